@@ -0,0 +1,46 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/spf13/cobra"
+)
+
+func runBackupRestoreCommandFunc(cmd *cobra.Command, args []string) {
+	initialGlobal(args[0], nil)
+
+	if err := client.RunBackupRestore(globalContext, globalProps, globalWorkload, globalDB); err != nil {
+		fmt.Println(err)
+		exitCode = 1
+		return
+	}
+	measurement.Output()
+}
+
+func newBackupRestoreCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "backup-restore db",
+		Short: "Measure backing up a loaded table with a full scan and restoring it with a full re-insert",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runBackupRestoreCommandFunc,
+	}
+	m.Flags().StringVar(&profileName, "profile", "", profileUsage())
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	return m
+}
@@ -21,18 +21,28 @@ import (
 	"github.com/pingcap/go-ycsb/pkg/client"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/workload"
 	"github.com/spf13/cobra"
 )
 
 func runClientCommandFunc(cmd *cobra.Command, args []string, doTransactions bool) {
-	dbName := args[0]
+	initClientGlobal(cmd, args[0], doTransactions)
+	if runPhase(true) && verifyArg {
+		runVerify()
+	}
+}
+
+// initClientGlobal initializes the globals with the properties and flags of a client
+// command, starting with the phase selected by doTransactions.
+func initClientGlobal(cmd *cobra.Command, dbName string, doTransactions bool) {
+	if dryRunArg {
+		// the operations are only generated, e.g. to record them with --record-trace
+		dbName = "basic"
+	}
 
 	initialGlobal(dbName, func() {
-		doTransFlag := "true"
-		if !doTransactions {
-			doTransFlag = "false"
-		}
-		globalProps.Set(prop.DoTransactions, doTransFlag)
+		globalProps.Set(prop.DoTransactions, strconv.FormatBool(doTransactions))
 
 		if cmd.Flags().Changed("threads") {
 			// We set the threadArg via command line.
@@ -46,20 +56,120 @@ func runClientCommandFunc(cmd *cobra.Command, args []string, doTransactions bool
 		if cmd.Flags().Changed("interval") {
 			globalProps.Set(prop.LogInterval, strconv.Itoa(reportInterval))
 		}
+
+		if recordTraceArg != "" {
+			globalProps.Set(prop.TraceRecord, recordTraceArg)
+		}
+
+		if (quietArg && progressArg) || (quietArg && dashboardArg) || (progressArg && dashboardArg) {
+			util.Fatalf("only one of --quiet, --progress and --dashboard can be used")
+		}
+		if quietArg {
+			globalProps.Set(prop.OutputMode, client.OutputQuiet)
+		} else if progressArg {
+			globalProps.Set(prop.OutputMode, client.OutputProgress)
+		} else if dashboardArg {
+			globalProps.Set(prop.OutputMode, client.OutputDashboard)
+		}
+		// -v prints the operation errors, -vv also the executed queries
+		if verboseArg >= 1 {
+			globalProps.Set(prop.Silence, "false")
+		}
+		if verboseArg >= 2 {
+			globalProps.Set(prop.Verbose, "true")
+		}
 	})
 
-	fmt.Println("***************** properties *****************")
-	for key, value := range globalProps.Map() {
-		fmt.Printf("\"%s\"=\"%s\"\n", key, value)
+	if globalProps.GetString(prop.OutputMode, prop.OutputModeDefault) == client.OutputNormal {
+		fmt.Println("***************** properties *****************")
+		for key, value := range util.RedactedProperties(globalProps) {
+			fmt.Printf("\"%s\"=\"%s\"\n", key, value)
+		}
+		fmt.Println("**********************************************")
+	}
+}
+
+// runPhase runs the phase selected by the dotransactions property, and outputs its
+// summary. Unless check is false, the workload hash is checked against
+// --expect-workload-hash and the results against the SLA thresholds. The history, if
+// recorded with history.check set, is checked for linearizability. It returns false
+// if the phase was interrupted or aborted by the error budget.
+func runPhase(check bool) bool {
+	workloadHash, err := workload.Hash(globalProps)
+	if err != nil {
+		util.Fatalf("hash workload failed %v", err)
+	}
+	if check && expectWorkloadHash != "" {
+		if globalProps.GetInt64(prop.RandomSeed, prop.RandomSeedDefault) == 0 {
+			util.Fatalf("the workload is only reproducible with %s set", prop.RandomSeed)
+		}
+		if workloadHash != expectWorkloadHash {
+			util.Fatalf("the workload hash is %s, expecting %s", workloadHash, expectWorkloadHash)
+		}
 	}
-	fmt.Println("**********************************************")
 
 	c := client.NewClient(globalProps, globalWorkload, globalDB)
 	start := time.Now()
 	c.Run(globalContext)
 
 	fmt.Printf("Run finished, takes %s\n", time.Now().Sub(start))
+	completed := true
+	if globalContext.Err() != nil {
+		fmt.Println("Run interrupted, the results only cover the operations done before the interruption")
+		exitCode, completed = 1, false
+	} else if c.Aborted() {
+		fmt.Printf("Run aborted, the error rate exceeded %s\n", prop.ErrorBudget)
+		exitCode, completed = 1, false
+	} else {
+		fmt.Printf("Run ended by %s after %d operations in %s\n", c.Limit(), c.Operations(), c.Elapsed())
+	}
+	fmt.Printf("Workload hash: %s\n", workloadHash)
 	measurement.Output()
+	measurement.OutputStability()
+	if err := measurement.WriteHdrHistograms(); err != nil {
+		fmt.Printf("Write HdrHistogram files failed: %v\n", err)
+	}
+	if err := measurement.WriteHistograms(); err != nil {
+		fmt.Printf("Write histograms failed: %v\n", err)
+	}
+	if err := measurement.Export(); err != nil {
+		fmt.Printf("Export results failed: %v\n", err)
+	}
+	client.OutputCost(globalProps, globalDB)
+	client.OutputExtendedStats(globalDB)
+	if check && !measurement.CheckSLA() {
+		exitCode = 1
+	}
+	if !client.CheckHistory() {
+		exitCode = 1
+	}
+	return completed
+}
+
+func runBenchCommandFunc(cmd *cobra.Command, args []string) {
+	initClientGlobal(cmd, args[0], false)
+	if globalProps.GetBool(prop.LoadResume, false) {
+		// the run phase must request all the records, not the resumed ones
+		util.Fatalf("%s is only supported by the load command", prop.LoadResume)
+	}
+	fmt.Println("***************** load phase *****************")
+	if !runPhase(false) {
+		return
+	}
+
+	// the run phase starts from fresh measurements, in the same process as the load,
+	// and with a workload created for the run, which is set up from dotransactions
+	globalProps.Set(prop.DoTransactions, "true")
+	measurement.InitMeasure(globalProps)
+	globalWorkload.Close()
+	var err error
+	if globalWorkload, err = workload.Create(globalProps); err != nil {
+		util.Fatalf("create workload %s failed %v", globalProps.GetString(prop.Workload, "core"), err)
+	}
+	fmt.Println("***************** run phase ******************")
+	if runPhase(true) && verifyArg {
+		runVerify()
+	}
 }
 
 func runLoadCommandFunc(cmd *cobra.Command, args []string) {
@@ -74,15 +184,33 @@ var (
 	threadsArg     int
 	targetArg      int
 	reportInterval int
+	quietArg       bool
+	progressArg    bool
+	dashboardArg   bool
+	verboseArg     int
+	// expectWorkloadHash is the workload hash the run refuses to start without
+	expectWorkloadHash string
+	recordTraceArg     string
+	dryRunArg          bool
+	verifyArg          bool
 )
 
 func initClientCommand(m *cobra.Command) {
+	m.Flags().StringVar(&profileName, "profile", "", profileUsage())
 	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
 	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
 	m.Flags().StringVar(&tableName, "table", "", "Use the table name instead of the default \""+prop.TableNameDefault+"\"")
 	m.Flags().IntVar(&threadsArg, "threads", 1, "Execute using n threads - can also be specified as the \"threadcount\" property")
 	m.Flags().IntVar(&targetArg, "target", 0, "Attempt to do n operations per second (default: unlimited) - can also be specified as the \"target\" property")
 	m.Flags().IntVar(&reportInterval, "interval", 10, "Interval of outputting measurements in seconds")
+	m.Flags().BoolVarP(&quietArg, "quiet", "q", false, "Only output the summary at the end of the run")
+	m.Flags().BoolVar(&progressArg, "progress", false, "Output the progress of the run as a single self-updating line")
+	m.Flags().BoolVar(&dashboardArg, "dashboard", false, "Output a live dashboard of the throughput, latencies and errors of every operation")
+	m.Flags().CountVarP(&verboseArg, "verbose", "v", "Output the operation errors, and with -vv the executed queries")
+	m.Flags().StringVar(&expectWorkloadHash, "expect-workload-hash", "", "Refuse to run unless the workload hash printed by a previous run matches, to compare runs of identical workloads")
+	m.Flags().StringVar(&recordTraceArg, "record-trace", "", "Record every operation to the trace file, which the trace workload can replay - can also be specified as the \"trace.record\" property")
+	m.Flags().BoolVar(&dryRunArg, "dry-run", false, "Generate the operations without executing them, on the basic DB instead of the given one")
+	m.Flags().BoolVar(&verifyArg, "verify", false, "Read back every record acknowledged by the load and the transactions at the end, and report the missing or corrupt ones")
 }
 
 func newLoadCommand() *cobra.Command {
@@ -97,6 +225,18 @@ func newLoadCommand() *cobra.Command {
 	return m
 }
 
+func newBenchCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "bench db",
+		Short: "YCSB load then run benchmark, in a single process",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runBenchCommandFunc,
+	}
+
+	initClientCommand(m)
+	return m
+}
+
 func newRunCommand() *cobra.Command {
 	m := &cobra.Command{
 		Use:   "run db",
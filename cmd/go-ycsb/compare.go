@@ -0,0 +1,190 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+type exportedOp struct {
+	Count       int64            `json:"count"`
+	OPS         float64          `json:"ops"`
+	Avg         int64            `json:"avg_us"`
+	Percentiles map[string]int64 `json:"percentiles_us"`
+}
+
+// exportedRun is the part of the summary written by the JSON exporter which is compared.
+type exportedRun struct {
+	Operations map[string]exportedOp `json:"operations"`
+	Errors     map[string]exportedOp `json:"errors"`
+}
+
+// ops returns the operations of the run which succeeded or failed, by name, leaving
+// out those derived from others, which older summaries listed among the operations.
+func (r *exportedRun) ops() []string {
+	seen := make(map[string]bool)
+	var ops []string
+	for _, m := range []map[string]exportedOp{r.Operations, r.Errors} {
+		for op := range m {
+			if !seen[op] && !measurement.IsDerived(op) {
+				seen[op] = true
+				ops = append(ops, op)
+			}
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// errorRate returns the percentage of the operations which failed.
+func (r *exportedRun) errorRate(op string) float64 {
+	failed := r.Errors[op].Count
+	total := r.Operations[op].Count + failed
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total) * 100
+}
+
+func loadExportedRun(path string) (*exportedRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	run := new(exportedRun)
+	if err = json.NewDecoder(f).Decode(run); err != nil {
+		return nil, fmt.Errorf("decode %s failed %v", path, err)
+	}
+	return run, nil
+}
+
+// change returns the relative change from base to candidate in percent.
+func change(base float64, candidate float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (candidate - base) / base * 100
+}
+
+// sortedPercentiles returns the percentile names, e.g. "p99.9", by increasing percentile.
+func sortedPercentiles(percentiles map[string]int64) []string {
+	names := make([]string, 0, len(percentiles))
+	for name := range percentiles {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, _ := strconv.ParseFloat(strings.TrimPrefix(names[i], "p"), 64)
+		pj, _ := strconv.ParseFloat(strings.TrimPrefix(names[j], "p"), 64)
+		return pi < pj
+	})
+	return names
+}
+
+var (
+	compareThroughputThreshold float64
+	compareLatencyThreshold    float64
+	compareErrorRateThreshold  float64
+)
+
+func runCompareCommandFunc(cmd *cobra.Command, args []string) {
+	base, err := loadExportedRun(args[0])
+	if err != nil {
+		util.Fatalf("load baseline failed %v", err)
+	}
+	candidate, err := loadExportedRun(args[1])
+	if err != nil {
+		util.Fatalf("load candidate failed %v", err)
+	}
+
+	regressions := 0
+	line := func(op string, metric string, b float64, c float64, regressed bool) {
+		mark := ""
+		if regressed {
+			mark = "  REGRESSION"
+			regressions++
+		}
+		fmt.Printf("%-16s %-8s %12.1f %12.1f %+8.1f%%%s\n", op, metric, b, c, change(b, c), mark)
+	}
+
+	fmt.Printf("%-16s %-8s %12s %12s %9s\n", "Operation", "Metric", "Baseline", "Candidate", "Change")
+	for _, op := range base.ops() {
+		_, succeeded := candidate.Operations[op]
+		_, failed := candidate.Errors[op]
+		if !succeeded && !failed {
+			// the candidate didn't run an operation of the baseline at all
+			fmt.Printf("%-16s missing from the candidate  REGRESSION\n", op)
+			regressions++
+			continue
+		}
+
+		be, ce := base.errorRate(op), candidate.errorRate(op)
+		line(op, "Err(%)", be, ce, ce-be > compareErrorRateThreshold)
+
+		b, ok := base.Operations[op]
+		c, cok := candidate.Operations[op]
+		if !ok || !cok {
+			// the latencies of the operations which all failed aren't compared
+			continue
+		}
+		line(op, "OPS", b.OPS, c.OPS, change(b.OPS, c.OPS) < -compareThroughputThreshold)
+		line(op, "Avg(us)", float64(b.Avg), float64(c.Avg), change(float64(b.Avg), float64(c.Avg)) > compareLatencyThreshold)
+		for _, p := range sortedPercentiles(b.Percentiles) {
+			cp, ok := c.Percentiles[p]
+			if !ok {
+				continue
+			}
+			bp := float64(b.Percentiles[p])
+			line(op, p+"(us)", bp, float64(cp), change(bp, float64(cp)) > compareLatencyThreshold)
+		}
+	}
+	for _, op := range candidate.ops() {
+		_, succeeded := base.Operations[op]
+		_, failed := base.Errors[op]
+		if !succeeded && !failed {
+			fmt.Printf("%-16s missing from the baseline\n", op)
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d regressions beyond a %.1f%% throughput drop, a %.1f%% latency increase or a %.1f point error rate increase\n",
+			regressions, compareThroughputThreshold, compareLatencyThreshold, compareErrorRateThreshold)
+		exitCode = 1
+		return
+	}
+	fmt.Println("No regressions")
+}
+
+func newCompareCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "compare baseline.json candidate.json",
+		Short: "Compare the results exported by two runs with exporter=json, and exit with status 1 on regressions",
+		Args:  cobra.ExactArgs(2),
+		Run:   runCompareCommandFunc,
+	}
+	m.Flags().Float64Var(&compareThroughputThreshold, "throughput-threshold", 5, "Throughput drop in percent beyond which an operation regressed")
+	m.Flags().Float64Var(&compareLatencyThreshold, "latency-threshold", 10, "Latency increase in percent beyond which an operation regressed")
+	m.Flags().Float64Var(&compareErrorRateThreshold, "error-rate-threshold", 0.1, "Error rate increase in percentage points beyond which an operation regressed")
+	return m
+}
@@ -0,0 +1,326 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// sharedProperties have the same name and meaning in Java YCSB and go-ycsb.
+var sharedProperties = map[string]bool{
+	prop.RecordCount:                   true,
+	prop.OperationCount:                true,
+	prop.InsertStart:                   true,
+	prop.InsertCount:                   true,
+	prop.ThreadCount:                   true,
+	prop.Target:                        true,
+	prop.MaxExecutiontime:              true,
+	prop.DoTransactions:                true,
+	prop.ExportFile:                    true,
+	prop.TableName:                     true,
+	prop.FieldCount:                    true,
+	prop.FieldLength:                   true,
+	prop.FieldLengthDistribution:       true,
+	prop.FieldLengthHistogramFile:      true,
+	prop.ReadAllFields:                 true,
+	prop.WriteAllFields:                true,
+	prop.DataIntegrity:                 true,
+	prop.ReadProportion:                true,
+	prop.UpdateProportion:              true,
+	prop.InsertProportion:              true,
+	prop.ScanProportion:                true,
+	prop.ReadModifyWriteProportion:     true,
+	prop.RequestDistribution:           true,
+	prop.ZeroPadding:                   true,
+	prop.MaxScanLength:                 true,
+	prop.ScanLengthDistribution:        true,
+	prop.InsertOrder:                   true,
+	prop.HotspotDataFraction:           true,
+	prop.HotspotOpnFraction:            true,
+	prop.InsertionRetryLimit:           true,
+	prop.InsertionRetryInterval:        true,
+	prop.ExponentialPercentile:         true,
+	prop.ExponentialFrac:               true,
+	prop.KeyPrefix:                     true,
+	measurement.HdrHistogramFileOutput: true,
+	measurement.HdrHistogramOutputPath: true,
+}
+
+// Java YCSB classes with a go-ycsb equivalent.
+const (
+	javaCoreWorkload      = "site.ycsb.workloads.CoreWorkload"
+	javaLegacyPackage     = "com.yahoo.ycsb."
+	javaTextExporter      = "site.ycsb.measurements.exporter.TextMeasurementsExporter"
+	javaJSONExporter      = "site.ycsb.measurements.exporter.JSONMeasurementsExporter"
+	javaJSONArrayExporter = "site.ycsb.measurements.exporter.JSONArrayMeasurementsExporter"
+)
+
+// Java YCSB properties without a shared name.
+const (
+	javaStatusInterval      = "status.interval"
+	javaPercentiles         = "hdrhistogram.percentiles"
+	javaMeasurementType     = "measurementtype"
+	javaRawOutputFile       = "measurement.raw.output_file"
+	javaFieldNamePrefix     = "fieldnameprefix"
+	javaMinScanLength       = "minscanlength"
+	javaMinFieldLength      = "minfieldlength"
+	javaMeasurementInterval = "measurement.interval"
+)
+
+// javaClass returns the name of a Java YCSB class, moving it out of the package of
+// the releases before 0.14.
+func javaClass(name string) string {
+	if strings.HasPrefix(name, javaLegacyPackage) {
+		return "site.ycsb." + strings.TrimPrefix(name, javaLegacyPackage)
+	}
+	return name
+}
+
+// conversion is the result of converting a workload parameter file.
+type conversion struct {
+	out *properties.Properties
+	// unsupported holds the options which couldn't be converted, with the reason
+	unsupported []string
+	// notes holds the options which were converted with a difference to be aware of
+	notes []string
+}
+
+func (c *conversion) set(key string, value string) {
+	c.out.Set(key, value)
+}
+
+func (c *conversion) unsupport(key string, value string, reason string) {
+	c.unsupported = append(c.unsupported, fmt.Sprintf("%s=%s: %s", key, value, reason))
+}
+
+func (c *conversion) note(format string, args ...interface{}) {
+	c.notes = append(c.notes, fmt.Sprintf(format, args...))
+}
+
+// importJava converts the properties of a Java YCSB workload to go-ycsb ones.
+func importJava(in *properties.Properties, compatible bool) *conversion {
+	c := &conversion{out: properties.NewProperties()}
+	for _, key := range in.Keys() {
+		value := strings.TrimSpace(in.GetString(key, ""))
+		if sharedProperties[key] {
+			c.set(key, value)
+			continue
+		}
+
+		switch key {
+		case prop.Workload:
+			if javaClass(value) != javaCoreWorkload {
+				c.unsupport(key, value, "only the core workload is supported")
+				continue
+			}
+			c.set(prop.Workload, "core")
+		case prop.Exporter:
+			switch javaClass(value) {
+			case javaTextExporter:
+				c.set(prop.Exporter, "text")
+			case javaJSONExporter, javaJSONArrayExporter:
+				c.set(prop.Exporter, "json")
+				c.note("%s=%s writes go-ycsb's JSON summary, whose layout differs from Java YCSB's", key, value)
+			default:
+				c.unsupport(key, value, "only the text and JSON exporters are supported")
+			}
+		case javaStatusInterval:
+			c.set(prop.LogInterval, value)
+		case javaPercentiles:
+			c.set(prop.Percentiles, value)
+		case javaRawOutputFile:
+			c.set(prop.SamplesFile, value)
+		case javaMeasurementType:
+			switch value {
+			case "histogram", "hdrhistogram":
+				// go-ycsb always measures latencies with a histogram
+			case "raw":
+				if in.GetString(javaRawOutputFile, "") == "" {
+					c.unsupport(key, value, "raw samples are only written to a file, set "+javaRawOutputFile)
+				}
+			case "timeseries":
+				c.unsupport(key, value, "use "+prop.TimeSeriesFile+" for the measurements of every "+prop.LogInterval)
+			default:
+				c.unsupport(key, value, "unknown measurement type")
+			}
+		case javaMeasurementInterval:
+			// go-ycsb uses this name for the status interval, and always measures
+			// the intended latencies once a target is set
+			if value != "op" {
+				c.note("%s=%s: the intended latencies are measured as INTENDED_<op> when %s is set", key, value, prop.Target)
+			}
+		case javaFieldNamePrefix:
+			if value != "field" {
+				c.unsupport(key, value, "field names always start with \"field\"")
+			}
+		case javaMinScanLength, javaMinFieldLength:
+			if value != "1" {
+				c.unsupport(key, value, "the minimum length is always 1")
+			}
+		case prop.DB:
+			c.unsupport(key, value, "the database is given on the command line")
+		default:
+			// likely a binding option, many of which have the same name in go-ycsb
+			c.set(key, value)
+			c.note("%s is not a core workload option, kept as is", key)
+		}
+	}
+
+	if compatible {
+		c.set(prop.Compatibility, "java")
+	} else if in.GetString(prop.InsertOrder, prop.InsertOrderDefault) == "hashed" {
+		c.note("hashed keys differ from Java YCSB's, set %s=java to generate the same keys", prop.Compatibility)
+	}
+	return c
+}
+
+// exportJava converts the properties of a go-ycsb workload to Java YCSB ones.
+func exportJava(in *properties.Properties) *conversion {
+	c := &conversion{out: properties.NewProperties()}
+	for _, key := range in.Keys() {
+		value := strings.TrimSpace(in.GetString(key, ""))
+		if sharedProperties[key] {
+			c.set(key, value)
+			continue
+		}
+
+		switch key {
+		case prop.Workload:
+			if value != "core" {
+				c.unsupport(key, value, "only the core workload is supported")
+				continue
+			}
+			c.set(prop.Workload, javaCoreWorkload)
+		case prop.Exporter:
+			switch value {
+			case "text":
+				c.set(prop.Exporter, javaTextExporter)
+			case "json":
+				c.set(prop.Exporter, javaJSONExporter)
+			default:
+				c.unsupport(key, value, "unknown exporter")
+			}
+		case prop.LogInterval:
+			c.set(javaStatusInterval, value)
+		case prop.Percentiles:
+			c.set(javaPercentiles, value)
+		case prop.SamplesFile:
+			c.set(javaMeasurementType, "raw")
+			c.set(javaRawOutputFile, value)
+		case prop.Compatibility:
+			// Java YCSB is what it's compatible with
+		case prop.KeyHash:
+			if value != "fnv64-java" {
+				c.unsupport(key, value, "Java YCSB always hashes keys like fnv64-java")
+			}
+		case prop.ThreadPools, prop.OpenLoop, prop.OpenLoopClasses, prop.OpenLoopBacklog,
+			prop.OpenLoopShedPolicy, prop.OpenLoopMemoryLimit, prop.WarmUpTime, prop.WarmUpReport,
+			prop.RandomSeed, prop.Breakdown, prop.TimeSeriesFile, prop.OutputMode:
+			c.unsupport(key, value, "not available in Java YCSB")
+		default:
+			c.set(key, value)
+			c.note("%s is not a core workload option, kept as is", key)
+		}
+	}
+
+	if in.GetString(prop.Compatibility, "") != "java" && in.GetString(prop.InsertOrder, prop.InsertOrderDefault) == "hashed" {
+		c.note("hashed keys differ from go-ycsb's unless it runs with %s=java", prop.Compatibility)
+	}
+	return c
+}
+
+// write writes the converted properties, then the unsupported options as comments.
+func (c *conversion) write(w io.Writer, source string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Converted from %s by go-ycsb convert\n\n", source)
+	keys := c.out.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, c.out.GetString(key, ""))
+	}
+	if len(c.unsupported) > 0 {
+		b.WriteString("\n# Unsupported options:\n")
+		for _, u := range c.unsupported {
+			fmt.Fprintf(&b, "# %s\n", u)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var (
+	convertTo         string
+	convertOutput     string
+	convertCompatible bool
+)
+
+func runConvertCommandFunc(cmd *cobra.Command, args []string) {
+	in, err := properties.LoadFile(args[0], properties.UTF8)
+	if err != nil {
+		util.Fatalf("load workload file failed %v", err)
+	}
+
+	var c *conversion
+	switch convertTo {
+	case "go":
+		c = importJava(in, convertCompatible)
+	case "java":
+		c = exportJava(in)
+	default:
+		util.Fatalf("unknown dialect %s; expecting go or java", convertTo)
+	}
+
+	var w io.Writer = os.Stdout
+	if convertOutput != "" {
+		f, err := os.Create(convertOutput)
+		if err != nil {
+			util.Fatalf("create %s failed %v", convertOutput, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err = c.write(w, args[0]); err != nil {
+		util.Fatalf("write converted workload failed %v", err)
+	}
+
+	for _, u := range c.unsupported {
+		fmt.Fprintf(os.Stderr, "Unsupported: %s\n", u)
+	}
+	for _, n := range c.notes {
+		fmt.Fprintf(os.Stderr, "Note: %s\n", n)
+	}
+}
+
+func newConvertCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "convert workload-file",
+		Short: "Convert a workload parameter file between the Java YCSB and go-ycsb properties",
+		Args:  cobra.ExactArgs(1),
+		Run:   runConvertCommandFunc,
+	}
+	m.Flags().StringVar(&convertTo, "to", "go", "Convert a Java YCSB workload to go-ycsb (\"go\"), or a go-ycsb one to Java YCSB (\"java\")")
+	m.Flags().StringVarP(&convertOutput, "output", "o", "", "File to write the converted workload to, stdout if not set")
+	m.Flags().BoolVar(&convertCompatible, "compatible", false, "Generate the same keys and values as Java YCSB, with "+prop.Compatibility+"=java")
+	return m
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/workload"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+	"github.com/spf13/cobra"
+)
+
+// recordDB is a simulated DB which records the operations issued by the workload
+// instead of executing them.
+type recordDB struct {
+	ops []string
+}
+
+func (db *recordDB) record(op string, table string, key string, extra string) {
+	db.ops = append(db.ops, fmt.Sprintf("%s %s %s %s", op, table, key, extra))
+}
+
+func describeValues(values map[string][]byte) string {
+	pairs := util.NewFieldPairs(values)
+	fields := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		fields = append(fields, fmt.Sprintf("%s:%x", pair.Field, util.BytesHash64(pair.Value)))
+	}
+	return strings.Join(fields, ",")
+}
+
+func (db *recordDB) ToSqlDB() *sql.DB {
+	return nil
+}
+
+func (db *recordDB) Close() error {
+	return nil
+}
+
+func (db *recordDB) InitThread(ctx context.Context, _ int, _ int) context.Context {
+	return ctx
+}
+
+func (db *recordDB) CleanupThread(_ context.Context) {
+}
+
+func (db *recordDB) Read(_ context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	db.record("READ", table, key, strings.Join(fields, ","))
+	return nil, nil
+}
+
+func (db *recordDB) Scan(_ context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	db.record("SCAN", table, startKey, fmt.Sprintf("%d %s", count, strings.Join(fields, ",")))
+	return nil, nil
+}
+
+func (db *recordDB) Update(_ context.Context, table string, key string, values map[string][]byte) error {
+	db.record("UPDATE", table, key, describeValues(values))
+	return nil
+}
+
+func (db *recordDB) Insert(_ context.Context, table string, key string, values map[string][]byte) error {
+	db.record("INSERT", table, key, describeValues(values))
+	return nil
+}
+
+func (db *recordDB) Delete(_ context.Context, table string, key string) error {
+	db.record("DELETE", table, key, "")
+	return nil
+}
+
+func (db *recordDB) RunTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	db.record("BEGIN", "", "", "")
+	err := fn(ctx)
+	db.record("COMMIT", "", "", "")
+	return err
+}
+
+// simulate runs the workload against a recordDB on a single thread and returns
+// the issued operations.
+func simulate(p *properties.Properties, count int64, doTransactions bool) ([]string, error) {
+	w, err := workload.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	db := new(recordDB)
+	if err = w.Init(db); err != nil {
+		return nil, err
+	}
+	ctx := w.InitThread(context.Background(), 0, 1)
+	defer w.CleanupThread(ctx)
+
+	for i := int64(0); i < count; i++ {
+		if doTransactions {
+			err = w.DoTransaction(ctx, db)
+		} else {
+			err = w.DoInsert(ctx, db)
+		}
+		if errors.Is(err, ycsb.ErrWorkloadDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db.ops, nil
+}
+
+var (
+	determinismCount int64
+	determinismLoad  bool
+)
+
+func runVerifyDeterminismCommandFunc(cmd *cobra.Command, args []string) {
+	p := properties.NewProperties()
+	if len(propertyFiles) > 0 {
+		p = properties.MustLoadFiles(propertyFiles, properties.UTF8, false)
+	}
+	for _, prop := range propertyValues {
+		seps := strings.SplitN(prop, "=", 2)
+		p.Set(seps[0], seps[1])
+	}
+	if p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault) == 0 {
+		p.Set(prop.RandomSeed, "1")
+	}
+	p.Set(prop.DoTransactions, fmt.Sprintf("%t", !determinismLoad))
+	measurement.InitMeasure(p)
+
+	var streams [2][]string
+	for i := range streams {
+		ops, err := simulate(p, determinismCount, !determinismLoad)
+		if err != nil {
+			util.Fatalf("simulate workload failed %v", err)
+		}
+		streams[i] = ops
+	}
+
+	if len(streams[0]) != len(streams[1]) {
+		fmt.Printf("Operation streams differ in length: %d vs %d\n", len(streams[0]), len(streams[1]))
+		os.Exit(1)
+	}
+	for i := range streams[0] {
+		if streams[0][i] != streams[1][i] {
+			fmt.Printf("Operation streams diverge at operation %d:\n- %s\n+ %s\n", i, streams[0][i], streams[1][i])
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Operation streams are identical (%d operations, %s=%s)\n",
+		len(streams[0]), prop.RandomSeed, p.GetString(prop.RandomSeed, ""))
+}
+
+func newVerifyDeterminismCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "verify-determinism",
+		Short: "Check that the workload generates the same operations for the same seed",
+		Args:  cobra.NoArgs,
+		Run:   runVerifyDeterminismCommandFunc,
+	}
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	m.Flags().Int64Var(&determinismCount, "count", 100000, "Number of operations to generate in each pass")
+	m.Flags().BoolVar(&determinismLoad, "load", false, "Verify the load phase instead of the transaction phase")
+	return m
+}
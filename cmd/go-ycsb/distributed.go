@@ -0,0 +1,406 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// agentJob is a load or run the coordinator starts on an agent.
+type agentJob struct {
+	Command    string            `json:"command"`
+	DB         string            `json:"db"`
+	Properties map[string]string `json:"properties"`
+	// Start is when all the agents start, so their clocks must be synchronized
+	Start time.Time `json:"start"`
+}
+
+// agentResult is the outcome of an agentJob, with the raw histograms to merge.
+type agentResult struct {
+	Output     string                  `json:"output"`
+	Error      string                  `json:"error,omitempty"`
+	Histograms *measurement.Histograms `json:"histograms,omitempty"`
+}
+
+// agentTokenEnv is the environment variable the token of the agents is read from
+// unless --token is set, so that it doesn't show in the process list.
+const agentTokenEnv = "GO_YCSB_AGENT_TOKEN"
+
+// agent executes the jobs of a coordinator, one at a time, as go-ycsb processes.
+type agent struct {
+	// token is the shared token the coordinator must send, since a job runs any
+	// DB with any properties, such as output files, as the agent's user
+	token string
+
+	sync.Mutex
+	busy bool
+	// running is the process of the current job, nil until it starts
+	running *os.Process
+	// stopped is set if the current job is stopped before it starts
+	stopped bool
+}
+
+// authorized returns whether the request carries the token of the agent, and
+// otherwise replies that it doesn't.
+func (a *agent) authorized(w http.ResponseWriter, r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		http.Error(w, "the token is missing or wrong", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (a *agent) serveRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "the job must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorized(w, r) {
+		return
+	}
+	var job agentJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if job.Command != "load" && job.Command != "run" {
+		http.Error(w, fmt.Sprintf("unknown command %q; expecting load or run", job.Command), http.StatusBadRequest)
+		return
+	}
+
+	res := a.run(&job)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (a *agent) run(job *agentJob) *agentResult {
+	res := new(agentResult)
+	dir, err := ioutil.TempDir("", "go-ycsb-agent")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer os.RemoveAll(dir)
+
+	histogramsFile := filepath.Join(dir, "histograms.json")
+	job.Properties[prop.HistogramsFile] = histogramsFile
+	propertyFile := filepath.Join(dir, "job.properties")
+	if err = writePropertyFile(propertyFile, properties.LoadMap(job.Properties)); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(exe, job.Command, job.DB, "-P", propertyFile)
+	cmd.Stdout, cmd.Stderr = &output, &output
+
+	a.Lock()
+	if a.busy {
+		a.Unlock()
+		res.Error = "the agent is already running a job"
+		return res
+	}
+	a.busy, a.stopped = true, false
+	a.Unlock()
+	defer func() {
+		a.Lock()
+		a.busy, a.running = false, nil
+		a.Unlock()
+	}()
+
+	time.Sleep(time.Until(job.Start))
+	a.Lock()
+	if !a.stopped {
+		if err = cmd.Start(); err == nil {
+			a.running = cmd.Process
+		}
+	}
+	stopped := a.stopped
+	a.Unlock()
+	if stopped {
+		res.Error = "the job was stopped before it started"
+		return res
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	fmt.Printf("Agent - Started %s of %s\n", job.Command, job.DB)
+
+	err = cmd.Wait()
+	fmt.Printf("Agent - Finished %s of %s\n", job.Command, job.DB)
+
+	res.Output = output.String()
+	if err != nil {
+		// the results of a failed run, e.g. one violating its SLA, are merged all the same
+		res.Error = err.Error()
+	}
+	if data, err := ioutil.ReadFile(histogramsFile); err == nil {
+		res.Histograms = new(measurement.Histograms)
+		if err = json.Unmarshal(data, res.Histograms); err != nil {
+			res.Histograms = nil
+			res.Error = fmt.Sprintf("decode histograms failed %v", err)
+		}
+	}
+	return res
+}
+
+// serveStop interrupts the current job, which then reports the results so far.
+func (a *agent) serveStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "the stop must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorized(w, r) {
+		return
+	}
+	a.stop()
+}
+
+func (a *agent) stop() {
+	a.Lock()
+	defer a.Unlock()
+	a.stopped = true
+	if a.running != nil {
+		_ = a.running.Signal(os.Interrupt)
+	}
+}
+
+func writePropertyFile(path string, p *properties.Properties) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = p.Write(f, properties.UTF8)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+var (
+	agentListenAddr string
+	agentToken      string
+)
+
+func runAgentCommandFunc(cmd *cobra.Command, args []string) {
+	if agentToken == "" {
+		util.Fatalf("the agent needs a shared token, set with --token or %s", agentTokenEnv)
+	}
+	a := &agent{token: agentToken}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/run", a.serveRun)
+	mux.HandleFunc("/stop", a.serveStop)
+	server := &http.Server{Addr: agentListenAddr, Handler: mux}
+	go func() {
+		<-globalContext.Done()
+		a.stop()
+		server.Close()
+	}()
+
+	fmt.Printf("Agent - Listening on %s\n", agentListenAddr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		util.Fatalf("serve agent failed %v", err)
+	}
+}
+
+func newAgentCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "agent",
+		Short: "Execute the loads and runs a coordinator starts",
+		Args:  cobra.NoArgs,
+		Run:   runAgentCommandFunc,
+	}
+	m.Flags().StringVar(&agentListenAddr, "listen", "127.0.0.1:7700", "Address to listen for the coordinator on, e.g. :7700 for every interface")
+	m.Flags().StringVar(&agentToken, "token", os.Getenv(agentTokenEnv), "Shared token the coordinator must send, "+agentTokenEnv+" by default")
+	return m
+}
+
+var (
+	coordinateAgents        []string
+	coordinateStartDelay    time.Duration
+	coordinateSplitKeyspace bool
+)
+
+func runCoordinateCommandFunc(cmd *cobra.Command, args []string) {
+	command, db := args[0], args[1]
+	if command != "load" && command != "run" {
+		util.Fatalf("unknown command %q; expecting load or run", command)
+	}
+	if len(coordinateAgents) == 0 {
+		util.Fatalf("no --agents to coordinate")
+	}
+	if agentToken == "" {
+		util.Fatalf("the agents need a shared token, set with --token or %s", agentTokenEnv)
+	}
+
+	p := properties.NewProperties()
+	if len(profileName) > 0 {
+		var err error
+		if p, err = loadProfile(profileName); err != nil {
+			util.Fatalf("load profile failed %v", err)
+		}
+	}
+	if len(propertyFiles) > 0 {
+		p.Merge(properties.MustLoadFiles(propertyFiles, properties.UTF8, false))
+	}
+	for _, prop := range propertyValues {
+		seps := strings.SplitN(prop, "=", 2)
+		p.Set(seps[0], seps[1])
+	}
+
+	start := time.Now().Add(coordinateStartDelay)
+	results := make([]*agentResult, len(coordinateAgents))
+	var wg sync.WaitGroup
+	for i, addr := range coordinateAgents {
+		job := &agentJob{Command: command, DB: db, Properties: p.Map(), Start: start}
+		if coordinateSplitKeyspace {
+			job.Properties[prop.LoaderIndex] = strconv.Itoa(i)
+			job.Properties[prop.LoaderTotal] = strconv.Itoa(len(coordinateAgents))
+		}
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			results[i] = postAgentJob(addr, job)
+		}(i, addr)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-globalContext.Done():
+			// the agents report the results so far once stopped
+			for _, addr := range coordinateAgents {
+				if resp, err := postAgent(addr, "/stop", "", nil); err == nil {
+					resp.Body.Close()
+				}
+			}
+		case <-done:
+		}
+	}()
+	fmt.Printf("Coordinate - Starting %s of %s on %d agents at %s\n", command, db, len(coordinateAgents), start.Format(time.RFC3339))
+	wg.Wait()
+	close(done)
+
+	var runs []*measurement.Histograms
+	for i, res := range results {
+		if res.Error != "" {
+			fmt.Printf("Agent %s failed: %s\n%s", coordinateAgents[i], res.Error, res.Output)
+			exitCode = 1
+		} else {
+			fmt.Printf("Agent %s finished\n", coordinateAgents[i])
+		}
+		if res.Histograms != nil {
+			runs = append(runs, res.Histograms)
+		}
+	}
+	if len(runs) == 0 {
+		util.Fatalf("no agent reported results")
+	}
+
+	if err := measurement.InitMerged(p, runs); err != nil {
+		util.Fatalf("merge results failed %v", err)
+	}
+	fmt.Printf("Merged results of %d agents\n", len(runs))
+	measurement.Output()
+	if err := measurement.Export(); err != nil {
+		fmt.Printf("Export results failed: %v\n", err)
+	}
+	if !measurement.CheckSLA() {
+		exitCode = 1
+	}
+}
+
+func agentURL(addr string, path string) string {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return strings.TrimSuffix(addr, "/") + path
+}
+
+// postAgent posts the body to the path of the agent with the shared token.
+func postAgent(addr string, path string, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, agentURL(addr, path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+agentToken)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func postAgentJob(addr string, job *agentJob) *agentResult {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return &agentResult{Error: err.Error()}
+	}
+	resp, err := postAgent(addr, "/run", "application/json", data)
+	if err != nil {
+		return &agentResult{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &agentResult{Error: fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(body)))}
+	}
+
+	res := new(agentResult)
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return &agentResult{Error: fmt.Sprintf("decode result failed %v", err)}
+	}
+	return res
+}
+
+func newCoordinateCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "coordinate load|run db",
+		Short: "Start a load or run on remote agents at the same time, and merge their results",
+		Args:  cobra.ExactArgs(2),
+		Run:   runCoordinateCommandFunc,
+	}
+	m.Flags().StringVar(&profileName, "profile", "", profileUsage())
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	m.Flags().StringSliceVar(&coordinateAgents, "agents", nil, "Addresses of the agents, e.g. host1:7700,host2:7700")
+	m.Flags().DurationVar(&coordinateStartDelay, "start-delay", 5*time.Second, "Delay before the agents all start, which must cover sending them the job")
+	m.Flags().BoolVar(&coordinateSplitKeyspace, "split-keyspace", true, "Give every agent its own range of the records with loader.index and loader.total")
+	m.Flags().StringVar(&agentToken, "token", os.Getenv(agentTokenEnv), "Shared token of the agents, "+agentTokenEnv+" by default")
+	return m
+}
@@ -19,7 +19,9 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -33,7 +35,7 @@ import (
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
-	_ "github.com/pingcap/go-ycsb/pkg/workload"
+	"github.com/pingcap/go-ycsb/pkg/workload"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	"github.com/spf13/cobra"
 
@@ -80,6 +82,7 @@ import (
 var (
 	propertyFiles  []string
 	propertyValues []string
+	profileName    string
 	dbName         string
 	tableName      string
 
@@ -89,12 +92,24 @@ var (
 	globalDB       ycsb.DB
 	globalWorkload ycsb.Workload
 	globalProps    *properties.Properties
+
+	// exitCode is the status to exit with once everything is closed
+	exitCode int
+	// shutdownGrace is the time.Duration of prop.ShutdownGrace, read atomically by the
+	// signal handler
+	shutdownGrace int64
 )
 
 func initialGlobal(dbName string, onProperties func()) {
 	globalProps = properties.NewProperties()
+	if len(profileName) > 0 {
+		var err error
+		if globalProps, err = loadProfile(profileName); err != nil {
+			util.Fatalf("load profile failed %v", err)
+		}
+	}
 	if len(propertyFiles) > 0 {
-		globalProps = properties.MustLoadFiles(propertyFiles, properties.UTF8, false)
+		globalProps.Merge(properties.MustLoadFiles(propertyFiles, properties.UTF8, false))
 	}
 
 	for _, prop := range propertyValues {
@@ -105,23 +120,57 @@ func initialGlobal(dbName string, onProperties func()) {
 	if onProperties != nil {
 		onProperties()
 	}
+	if _, ok := globalProps.Get(prop.DB); !ok {
+		globalProps.Set(prop.DB, dbName)
+	}
+	if s := globalProps.GetString(prop.ThreadPools, ""); s != "" {
+		pools, err := util.ParseThreadPools(s)
+		if err != nil {
+			util.Fatalf("parse thread pools failed %v", err)
+		}
+		globalProps.Set(prop.ThreadCount, strconv.Itoa(util.ThreadPoolsSize(pools)))
+	}
+	if total := globalProps.GetInt64(prop.LoaderTotal, 0); total > 0 {
+		partitionKeyspace(total)
+	}
+	if !globalProps.GetBool(prop.DoTransactions, true) {
+		if err := workload.ResumeLoad(globalProps); err != nil {
+			util.Fatalf("resume load failed %v", err)
+		}
+	}
 
-	addr := globalProps.GetString(prop.DebugPprof, prop.DebugPprofDefault)
-	go func() {
-		http.ListenAndServe(addr, nil)
-	}()
+	if grace, err := time.ParseDuration(globalProps.GetString(prop.ShutdownGrace, prop.ShutdownGraceDefault)); err != nil || grace < 0 {
+		util.Fatalf("invalid %s", prop.ShutdownGrace)
+	} else {
+		atomic.StoreInt64(&shutdownGrace, int64(grace))
+	}
+
+	if addr := globalProps.GetString(prop.DebugPprof, prop.DebugPprofDefault); addr != "" {
+		go func() {
+			http.ListenAndServe(addr, nil)
+		}()
+	}
 
 	measurement.InitMeasure(globalProps)
+	if port := globalProps.GetInt(prop.PrometheusPort, prop.PrometheusPortDefault); port > 0 {
+		if err := measurement.ServePrometheus(fmt.Sprintf(":%d", port)); err != nil {
+			util.Fatalf("serve prometheus metrics failed %v", err)
+		}
+	}
+	if port := globalProps.GetInt(prop.StatusPort, prop.StatusPortDefault); port > 0 {
+		if err := client.ServeStatus(fmt.Sprintf(":%d", port)); err != nil {
+			util.Fatalf("serve status failed %v", err)
+		}
+	}
 
 	if len(tableName) == 0 {
 		tableName = globalProps.GetString(prop.TableName, prop.TableNameDefault)
 	}
 
 	workloadName := globalProps.GetString(prop.Workload, "core")
-	workloadCreator := ycsb.GetWorkloadCreator(workloadName)
 
 	var err error
-	if globalWorkload, err = workloadCreator.Create(globalProps); err != nil {
+	if globalWorkload, err = workload.Create(globalProps); err != nil {
 		util.Fatalf("create workload %s failed %v", workloadName, err)
 	}
 
@@ -132,11 +181,61 @@ func initialGlobal(dbName string, onProperties func()) {
 	if globalDB, err = dbCreator.Create(globalProps); err != nil {
 		util.Fatalf("create db %s failed %v", dbName, err)
 	}
+	if shadowName := globalProps.GetString(prop.ShadowDB, ""); shadowName != "" {
+		shadowCreator := ycsb.GetDBCreator(shadowName)
+		if shadowCreator == nil {
+			util.Fatalf("%s is not registered", shadowName)
+		}
+		shadowDB, err := shadowCreator.Create(globalProps)
+		if err != nil {
+			util.Fatalf("create shadow db %s failed %v", shadowName, err)
+		}
+		if globalDB, err = client.NewShadowDB(globalProps, globalDB, shadowDB); err != nil {
+			util.Fatalf("create shadow db %s failed %v", shadowName, err)
+		}
+	}
 	globalDB = client.DbWrapper{globalDB}
 }
 
+// partitionKeyspace sets the insertstart and insertcount of the loader.index instance
+// out of total, giving the instances contiguous ranges of the keyspace which differ
+// by at most one record.
+func partitionKeyspace(total int64) {
+	index := globalProps.GetInt64(prop.LoaderIndex, -1)
+	if index < 0 || index >= total {
+		util.Fatalf("%s must be between 0 and %s-1", prop.LoaderIndex, prop.LoaderTotal)
+	}
+	recordCount := globalProps.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	if recordCount <= 0 {
+		util.Fatalf("%s needs a %s", prop.LoaderTotal, prop.RecordCount)
+	}
+
+	start := globalProps.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	count := globalProps.GetInt64(prop.InsertCount, recordCount-start)
+	share, extra := count/total, count%total
+	if share == 0 {
+		util.Fatalf("%s %d is more than the %d records", prop.LoaderTotal, total, count)
+	}
+	start += index*share + min64(index, extra)
+	if index < extra {
+		share++
+	}
+	globalProps.Set(prop.InsertStart, strconv.FormatInt(start, 10))
+	globalProps.Set(prop.InsertCount, strconv.FormatInt(share, 10))
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func main() {
 	globalContext, globalCancel = context.WithCancel(context.Background())
+	// until the properties are read
+	grace, _ := time.ParseDuration(prop.ShutdownGraceDefault)
+	shutdownGrace = int64(grace)
 
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
@@ -148,16 +247,25 @@ func main() {
 	closeDone := make(chan struct{}, 1)
 	go func() {
 		sig := <-sc
-		fmt.Printf("\nGot signal [%v] to exit.\n", sig)
+		fmt.Printf("\nGot signal [%v] to exit, stopping the workers and outputting the results so far.\n", sig)
 		globalCancel()
 
+		// the drivers which ignore the cancellation would otherwise hold up the exit forever
+		grace := time.Duration(atomic.LoadInt64(&shutdownGrace))
+		var forceExit <-chan time.Time
+		if grace > 0 {
+			fmt.Printf("Send it again to exit immediately, or wait %v for the exit to be forced.\n", grace)
+			forceExit = time.After(grace)
+		} else {
+			fmt.Print("Send it again to exit immediately.\n")
+		}
 		select {
 		case <-sc:
 			// send signal again, return directly
 			fmt.Printf("\nGot signal [%v] again to exit.\n", sig)
 			os.Exit(1)
-		case <-time.After(10 * time.Second):
-			fmt.Print("\nWait 10s for closed, force exit\n")
+		case <-forceExit:
+			fmt.Printf("\nWait %v for closed, force exit\n", grace)
 			os.Exit(1)
 		case <-closeDone:
 			return
@@ -173,6 +281,14 @@ func main() {
 		newShellCommand(),
 		newLoadCommand(),
 		newRunCommand(),
+		newBenchCommand(),
+		newVerifyDeterminismCommand(),
+		newConvertCommand(),
+		newBackupRestoreCommand(),
+		newVerifyCommand(),
+		newCompareCommand(),
+		newAgentCommand(),
+		newCoordinateCommand(),
 	)
 
 	cobra.EnablePrefixMatching = true
@@ -194,4 +310,7 @@ func main() {
 	}
 
 	closeDone <- struct{}{}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
@@ -0,0 +1,109 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+// profile is a named set of properties for a common kind of experiment.
+type profile struct {
+	description string
+	properties  string
+}
+
+var profiles = make(map[string]profile)
+
+func registerProfile(name string, description string, props string) {
+	if _, ok := profiles[name]; ok {
+		panic(fmt.Sprintf("duplicate profile %s", name))
+	}
+	profiles[name] = profile{description: description, properties: props}
+}
+
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileUsage describes the profiles for the --profile flag.
+func profileUsage() string {
+	lines := []string{"Start from the properties of a bundled profile, which property files and -p values override:"}
+	for _, name := range profileNames() {
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, profiles[name].description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func loadProfile(name string) (*properties.Properties, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %s; expecting one of %s", name, strings.Join(profileNames(), ", "))
+	}
+	return properties.LoadString(p.properties)
+}
+
+func init() {
+	registerProfile("smoke", "a quick check that the DB works and returns the data written to it", `
+recordcount=1000
+operationcount=1000
+threadcount=4
+workload=core
+readallfields=true
+readproportion=0.5
+updateproportion=0.5
+requestdistribution=zipfian
+dataintegrity=true
+measurement.interval=1
+silence=false
+`)
+
+	registerProfile("soak-24h", "1000 OPS for 24 hours, with per-minute CSV time series in soak-24h.csv", `
+operationcount=86400000
+threadcount=32
+target=1000
+workload=core
+readproportion=0.5
+updateproportion=0.5
+requestdistribution=zipfian
+warmuptime=300
+measurement.interval=60
+measurement.percentiles=50,99,99.9,99.99
+measurement.timeseries.file=soak-24h.csv
+`)
+
+	registerProfile("failover-drill", "10 minutes of open-loop load with client deadlines, to observe a failover in the per-second CSV time series in failover-drill.csv", `
+operationcount=1200000
+threadcount=64
+target=2000
+openloop=true
+operation.timeout=1s
+workload=core
+readproportion=0.5
+updateproportion=0.5
+requestdistribution=uniform
+warmuptime=30
+measurement.interval=1
+measurement.percentiles=50,99,99.9
+measurement.timeseries.file=failover-drill.csv
+`)
+}
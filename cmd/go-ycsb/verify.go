@@ -0,0 +1,48 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// runVerify reads back the records the workload acknowledged, and sets the exit code
+// if any is missing or corrupt.
+func runVerify() {
+	if err := client.RunVerify(globalContext, globalProps, globalWorkload, globalDB); err != nil {
+		fmt.Printf("Verify failed: %v\n", err)
+		exitCode = 1
+	}
+}
+
+func runVerifyCommandFunc(cmd *cobra.Command, args []string) {
+	initialGlobal(args[0], nil)
+	runVerify()
+}
+
+func newVerifyCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "verify db",
+		Short: "Read back every loaded record, and report the missing or corrupt ones",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runVerifyCommandFunc,
+	}
+	m.Flags().StringVar(&profileName, "profile", "", profileUsage())
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	return m
+}
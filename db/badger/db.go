@@ -131,6 +131,13 @@ func getOptions(p *properties.Properties) badger.Options {
 	return opts
 }
 
+// StorageSize returns the size of the LSM tree and the value log. Badger refreshes
+// the sizes periodically, so the result may lag behind recent writes.
+func (db *badgerDB) StorageSize(_ context.Context) (int64, error) {
+	lsm, vlog := db.db.Size()
+	return lsm + vlog, nil
+}
+
 func (db *badgerDB) ToSqlDB() *sql.DB {
 	return nil
 }
@@ -146,6 +153,9 @@ func (db *badgerDB) InitThread(ctx context.Context, _ int, _ int) context.Contex
 func (db *badgerDB) CleanupThread(_ context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (db *badgerDB) ConcurrentThreads() {}
+
 func (db *badgerDB) getRowKey(table string, key string) []byte {
 	return util.Slice(fmt.Sprintf("%s:%s", table, key))
 }
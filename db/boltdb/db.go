@@ -29,10 +29,13 @@
 package boltdb
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/binary"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/boltdb/bolt"
 	"github.com/magiconair/properties"
@@ -110,6 +113,16 @@ func (db *boltDB) Close() error {
 	return db.db.Close()
 }
 
+// StorageSize returns the size of the database file.
+func (db *boltDB) StorageSize(_ context.Context) (int64, error) {
+	var size int64
+	err := db.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return size, err
+}
+
 func (db *boltDB) ToSqlDB() *sql.DB {
 	return nil
 }
@@ -121,9 +134,49 @@ func (db *boltDB) InitThread(ctx context.Context, _ int, _ int) context.Context
 func (db *boltDB) CleanupThread(_ context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (db *boltDB) ConcurrentThreads() {}
+
+// txKey is the context key of the transaction the operations of RunTx run in.
+type txKey struct{}
+
+// view runs fn in the transaction of the context, or else in a read-only transaction.
+func (db *boltDB) view(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*bolt.Tx); ok {
+		return fn(tx)
+	}
+	return db.db.View(fn)
+}
+
+// update runs fn in the transaction of the context, or else in a read-write transaction.
+func (db *boltDB) update(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*bolt.Tx); ok {
+		return fn(tx)
+	}
+	return db.db.Update(fn)
+}
+
+// RunTx implements the TransactionDB RunTx interface. BoltDB runs a single read-write
+// transaction at a time, so its transactions never abort.
+func (db *boltDB) RunTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// putRow puts the row encoded in a pooled buffer. Bolt keeps the row until the
+// transaction commits, so it's copied in the transactions of RunTx, where the buffer
+// is reused by the next operations before the commit.
+func (db *boltDB) putRow(ctx context.Context, bucket *bolt.Bucket, key string, rowData []byte) error {
+	if ctx.Value(txKey{}) != nil {
+		rowData = append([]byte(nil), rowData...)
+	}
+	return bucket.Put([]byte(key), rowData)
+}
+
 func (db *boltDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	var m map[string][]byte
-	err := db.db.View(func(tx *bolt.Tx) error {
+	err := db.view(ctx, func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(table))
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
@@ -131,11 +184,12 @@ func (db *boltDB) Read(ctx context.Context, table string, key string, fields []s
 
 		row := bucket.Get([]byte(key))
 		if row == nil {
-			return fmt.Errorf("key not found: %s.%s", table, key)
+			return fmt.Errorf("key %w: %s.%s", ycsb.ErrNotFound, table, key)
 		}
 
+		// the row is only valid during the transaction
 		var err error
-		m, err = db.r.Decode(row, fields)
+		m, err = db.r.Decode(append([]byte(nil), row...), fields)
 		return err
 	})
 	return m, err
@@ -143,7 +197,7 @@ func (db *boltDB) Read(ctx context.Context, table string, key string, fields []s
 
 func (db *boltDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
 	res := make([]map[string][]byte, count)
-	err := db.db.View(func(tx *bolt.Tx) error {
+	err := db.view(ctx, func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(table))
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
@@ -152,7 +206,7 @@ func (db *boltDB) Scan(ctx context.Context, table string, startKey string, count
 		cursor := bucket.Cursor()
 		key, value := cursor.Seek([]byte(startKey))
 		for i := 0; key != nil && i < count; i++ {
-			m, err := db.r.Decode(value, fields)
+			m, err := db.r.Decode(append([]byte(nil), value...), fields)
 			if err != nil {
 				return err
 			}
@@ -166,8 +220,25 @@ func (db *boltDB) Scan(ctx context.Context, table string, startKey string, count
 	return res, err
 }
 
+func (db *boltDB) StreamScan(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	return db.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(table))
+		if bucket == nil {
+			return fmt.Errorf("table not found: %s", table)
+		}
+
+		return bucket.ForEach(func(key []byte, value []byte) error {
+			m, err := db.r.Decode(value, nil)
+			if err != nil {
+				return err
+			}
+			return fn(string(key), m)
+		})
+	})
+}
+
 func (db *boltDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(tx *bolt.Tx) error {
+	err := db.update(ctx, func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(table))
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
@@ -175,7 +246,7 @@ func (db *boltDB) Update(ctx context.Context, table string, key string, values m
 
 		value := bucket.Get([]byte(key))
 		if value == nil {
-			return fmt.Errorf("key not found: %s.%s", table, key)
+			return fmt.Errorf("key %w: %s.%s", ycsb.ErrNotFound, table, key)
 		}
 
 		data, err := db.r.Decode(value, nil)
@@ -195,13 +266,128 @@ func (db *boltDB) Update(ctx context.Context, table string, key string, values m
 			return err
 		}
 
-		return bucket.Put([]byte(key), rowData)
+		return db.putRow(ctx, bucket, key, rowData)
 	})
 	return err
 }
 
+func (db *boltDB) CAS(ctx context.Context, table string, key string, expected map[string][]byte, values map[string][]byte) error {
+	return db.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(table))
+		if bucket == nil {
+			return fmt.Errorf("table not found: %s", table)
+		}
+
+		value := bucket.Get([]byte(key))
+		if value == nil {
+			return fmt.Errorf("key %w: %s.%s", ycsb.ErrNotFound, table, key)
+		}
+
+		data, err := db.r.Decode(value, nil)
+		if err != nil {
+			return err
+		}
+
+		for field, value := range expected {
+			if !bytes.Equal(data[field], value) {
+				return fmt.Errorf("%w: field %s of %s.%s changed", ycsb.ErrConflict, field, table, key)
+			}
+		}
+
+		for field, value := range values {
+			data[field] = value
+		}
+
+		buf := db.bufPool.Get()
+		defer db.bufPool.Put(buf)
+
+		rowData, err := db.r.Encode(buf.Bytes(), data)
+		if err != nil {
+			return err
+		}
+
+		return db.putRow(ctx, bucket, key, rowData)
+	})
+}
+
+func (db *boltDB) Increment(ctx context.Context, table string, key string, field string, delta int64) (int64, error) {
+	var n int64
+	err := db.update(ctx, func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
+		if err != nil {
+			return err
+		}
+
+		data := make(map[string][]byte)
+		if value := bucket.Get([]byte(key)); value != nil {
+			if data, err = db.r.Decode(value, nil); err != nil {
+				return err
+			}
+		}
+
+		if value, ok := data[field]; ok {
+			if n, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return fmt.Errorf("field %s of %s.%s isn't a counter: %v", field, table, key, err)
+			}
+		}
+		n += delta
+		data[field] = []byte(strconv.FormatInt(n, 10))
+
+		buf := db.bufPool.Get()
+		defer db.bufPool.Put(buf)
+
+		rowData, err := db.r.Encode(buf.Bytes(), data)
+		if err != nil {
+			return err
+		}
+
+		return db.putRow(ctx, bucket, key, rowData)
+	})
+	return n, err
+}
+
+// Push implements the QueueDB Push interface. A queue is a bucket whose keys are
+// the sequence numbers of the values.
+func (db *boltDB) Push(ctx context.Context, table string, queue string, value []byte) error {
+	return db.update(ctx, func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(table + "/" + queue))
+		if err != nil {
+			return err
+		}
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bucket.Put(key, value)
+	})
+}
+
+// Pop implements the QueueDB Pop interface.
+func (db *boltDB) Pop(ctx context.Context, table string, queue string) ([]byte, error) {
+	var value []byte
+	err := db.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(table + "/" + queue))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		key, v := c.First()
+		if key == nil {
+			return nil
+		}
+		// the value is only valid until the end of the transaction
+		value = append([]byte(nil), v...)
+		return c.Delete()
+	})
+	return value, err
+}
+
 func (db *boltDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(tx *bolt.Tx) error {
+	err := db.update(ctx, func(tx *bolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
 		if err != nil {
 			return err
@@ -215,13 +401,13 @@ func (db *boltDB) Insert(ctx context.Context, table string, key string, values m
 			return err
 		}
 
-		return bucket.Put([]byte(key), rowData)
+		return db.putRow(ctx, bucket, key, rowData)
 	})
 	return err
 }
 
 func (db *boltDB) Delete(ctx context.Context, table string, key string) error {
-	err := db.db.Update(func(tx *bolt.Tx) error {
+	err := db.update(ctx, func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(table))
 		if bucket == nil {
 			return nil
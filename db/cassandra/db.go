@@ -93,16 +93,16 @@ func (c cassandraCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 
 	d.bufPool = util.NewBufPool()
 
-	if err := d.createTable(); err != nil {
-		return nil, err
+	for _, tableName := range util.TableNames(p) {
+		if err := d.createTable(tableName); err != nil {
+			return nil, err
+		}
 	}
 
 	return d, nil
 }
 
-func (db *cassandraDB) createTable() error {
-	tableName := db.p.GetString(prop.TableName, prop.TableNameDefault)
-
+func (db *cassandraDB) createTable(tableName string) error {
 	if db.p.GetBool(prop.DropData, prop.DropDataDefault) {
 		if err := db.session.Query(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", db.keySpace, tableName)).Exec(); err != nil {
 			return err
@@ -155,6 +155,9 @@ func (db *cassandraDB) CleanupThread(_ctx context.Context) {
 
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (db *cassandraDB) ConcurrentThreads() {}
+
 func (db *cassandraDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	var query string
 	if len(fields) == 0 {
@@ -201,16 +204,32 @@ func (db *cassandraDB) execQuery(ctx context.Context, query string, args ...inte
 	return err
 }
 
+// ttlSeconds returns the ttl in whole seconds, as CQL expects, rounding up so that
+// a record never expires sooner than asked.
+func ttlSeconds(ttl time.Duration) int {
+	return int((ttl + time.Second - 1) / time.Second)
+}
+
 func (db *cassandraDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return db.UpdateWithTTL(ctx, table, key, values, 0)
+}
+
+// UpdateWithTTL implements the TTLDB UpdateWithTTL interface. A zero ttl means the
+// record doesn't expire.
+func (db *cassandraDB) UpdateWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
 	buf := db.bufPool.Get()
 	defer db.bufPool.Put(buf)
 
 	buf.WriteString("UPDATE ")
 	buf.WriteString(fmt.Sprintf("%s.%s", db.keySpace, table))
+	args := make([]interface{}, 0, len(values)+2)
+	if ttl > 0 {
+		buf.WriteString(" USING TTL ?")
+		args = append(args, ttlSeconds(ttl))
+	}
 	buf.WriteString(" SET ")
 	firstField := true
 	pairs := util.NewFieldPairs(values)
-	args := make([]interface{}, 0, len(values)+1)
 	for _, p := range pairs {
 		if firstField {
 			firstField = false
@@ -230,6 +249,12 @@ func (db *cassandraDB) Update(ctx context.Context, table string, key string, val
 }
 
 func (db *cassandraDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return db.InsertWithTTL(ctx, table, key, values, 0)
+}
+
+// InsertWithTTL implements the TTLDB InsertWithTTL interface. A zero ttl means the
+// record doesn't expire.
+func (db *cassandraDB) InsertWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
 	args := make([]interface{}, 0, 1+len(values))
 	args = append(args, key)
 
@@ -253,6 +278,10 @@ func (db *cassandraDB) Insert(ctx context.Context, table string, key string, val
 	}
 
 	buf.WriteByte(')')
+	if ttl > 0 {
+		buf.WriteString(" USING TTL ?")
+		args = append(args, ttlSeconds(ttl))
+	}
 
 	return db.execQuery(ctx, buf.String(), args...)
 }
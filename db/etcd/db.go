@@ -1,27 +1,33 @@
 package etcd
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
-func assert(cond bool) {
-	if !cond {
-		panic("sanity assertion failed!")
-	}
-}
-
 type etcdClient struct {
 	client  *clientv3.Client
 	useInts bool
+	// readOpts are the options of every read, serializable ones if etcd.serializable is set
+	readOpts []clientv3.OpOption
 }
 
 func (etcd *etcdClient) ToSqlDB() *sql.DB {
@@ -39,6 +45,9 @@ func (etcd *etcdClient) InitThread(ctx context.Context, _ int, _ int) context.Co
 func (etcd *etcdClient) CleanupThread(_ context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (etcd *etcdClient) ConcurrentThreads() {}
+
 func (etcd *etcdClient) readCount(ctx context.Context, table string, key string, count int64, fields []string) ([]map[string][]byte, error) {
 	var results []map[string][]byte
 	var shouldHave map[string]bool
@@ -46,7 +55,8 @@ func (etcd *etcdClient) readCount(ctx context.Context, table string, key string,
 	currentKeyStr := table + "/" + key
 
 	for count > 0 {
-		resp, err := etcd.client.Get(ctx, currentKeyStr, clientv3.WithFromKey(), clientv3.WithLimit(count))
+		opts := append([]clientv3.OpOption{clientv3.WithFromKey(), clientv3.WithLimit(count)}, etcd.readOpts...)
+		resp, err := etcd.client.Get(ctx, currentKeyStr, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -93,12 +103,40 @@ func (etcd *etcdClient) readCount(ctx context.Context, table string, key string,
 }
 
 func (etcd *etcdClient) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
-	results, err := etcd.readCount(ctx, table, key, 1, fields)
+	keyStr := table + "/" + key
+	resp, err := etcd.client.Get(ctx, keyStr, etcd.readOpts...)
 	if err != nil {
 		return nil, err
 	}
-	assert(len(results) == 1)
-	return results[0], nil
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("key %w: %s", ycsb.ErrNotFound, keyStr)
+	}
+	if etcd.useInts {
+		// don't bother parsing, it's just ints
+		return make(map[string][]byte), nil
+	}
+	var result map[string][]byte
+	if err := json.Unmarshal(resp.Kvs[0].Value, &result); err != nil {
+		return nil, err
+	}
+	if len(fields) != 0 {
+		shouldHave := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			shouldHave[field] = true
+		}
+		for field := range result {
+			if !shouldHave[field] {
+				delete(result, field)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Valueless implements the ValuelessDB interface: with ycsb.useints, the values aren't
+// parsed back.
+func (etcd *etcdClient) Valueless() bool {
+	return etcd.useInts
 }
 
 func (etcd *etcdClient) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
@@ -106,45 +144,238 @@ func (etcd *etcdClient) Scan(ctx context.Context, table string, startKey string,
 }
 
 func (etcd *etcdClient) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	result, err := etcd.Read(ctx, table, key, nil)
+	return etcd.UpdateWithTTL(ctx, table, key, values, 0)
+}
+
+// UpdateWithTTL implements the TTLDB UpdateWithTTL interface. A zero ttl means the
+// record doesn't expire. The record is read linearizably and written back in a
+// transaction, again until no other write came in between, so that the fields
+// written meanwhile aren't dropped.
+func (etcd *etcdClient) UpdateWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
+	keyStr := table + "/" + key
+	opts, err := etcd.leaseOpts(ctx, ttl)
 	if err != nil {
 		return err
 	}
-	for k := range values {
-		result[k] = values[k]
+	for {
+		result, revision, err := etcd.getRevision(ctx, keyStr)
+		if err != nil {
+			return err
+		}
+		for k := range values {
+			result[k] = values[k]
+		}
+		err = etcd.putIfUnmodified(ctx, keyStr, result, revision, opts...)
+		if !errors.Is(err, ycsb.ErrConflict) {
+			return err
+		}
 	}
-	return etcd.Insert(ctx, table, key, result)
 }
 
 func (etcd *etcdClient) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
-	valuesBytes, err := json.Marshal(values)
+	return etcd.InsertWithTTL(ctx, table, key, values, 0)
+}
+
+// InsertWithTTL implements the TTLDB InsertWithTTL interface. The record is attached
+// to a lease of its own, granted with an extra request, which etcd revokes after the
+// ttl rounded up to seconds. A zero ttl means the record doesn't expire.
+func (etcd *etcdClient) InsertWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
+	content, err := etcd.encode(values)
 	if err != nil {
 		return err
 	}
-	var content string
-	if etcd.useInts {
-		content = fmt.Sprintf("%d", len(valuesBytes))
-	} else {
-		content = string(valuesBytes)
+	opts, err := etcd.leaseOpts(ctx, ttl)
+	if err != nil {
+		return err
 	}
 	// don't think there's anything useful we can do with response in this case
-	_, err = etcd.client.Put(ctx, table+"/"+key, content)
+	_, err = etcd.client.Put(ctx, table+"/"+key, content, opts...)
 	return err
 }
 
+// encode returns the content stored for the values of a record, only their length
+// with ycsb.useints.
+func (etcd *etcdClient) encode(values map[string][]byte) (string, error) {
+	valuesBytes, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	if etcd.useInts {
+		return fmt.Sprintf("%d", len(valuesBytes)), nil
+	}
+	return string(valuesBytes), nil
+}
+
+// leaseOpts returns the options attaching a record to a lease of its own expiring
+// after the ttl, none for a zero ttl.
+func (etcd *etcdClient) leaseOpts(ctx context.Context, ttl time.Duration) ([]clientv3.OpOption, error) {
+	if ttl <= 0 {
+		return nil, nil
+	}
+	lease, err := etcd.client.Grant(ctx, int64((ttl+time.Second-1)/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return []clientv3.OpOption{clientv3.WithLease(lease.ID)}, nil
+}
+
+// getRevision reads all the fields of a record and the revision it was last modified
+// at, linearizably even if etcd.serializable is set, since it is compared on write.
+func (etcd *etcdClient) getRevision(ctx context.Context, keyStr string) (map[string][]byte, int64, error) {
+	resp, err := etcd.client.Get(ctx, keyStr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, 0, fmt.Errorf("key %w: %s", ycsb.ErrNotFound, keyStr)
+	}
+	if etcd.useInts {
+		return make(map[string][]byte), resp.Kvs[0].ModRevision, nil
+	}
+	var result map[string][]byte
+	if err := json.Unmarshal(resp.Kvs[0].Value, &result); err != nil {
+		return nil, 0, err
+	}
+	return result, resp.Kvs[0].ModRevision, nil
+}
+
+// putIfUnmodified writes a record if it wasn't modified since the revision, and
+// returns an error wrapping ErrConflict otherwise.
+func (etcd *etcdClient) putIfUnmodified(ctx context.Context, keyStr string, values map[string][]byte, revision int64, opts ...clientv3.OpOption) error {
+	content, err := etcd.encode(values)
+	if err != nil {
+		return err
+	}
+	resp, err := etcd.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(keyStr), "=", revision)).
+		Then(clientv3.OpPut(keyStr, content, opts...)).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("%w: %s modified since revision %d", ycsb.ErrConflict, keyStr, revision)
+	}
+	return nil
+}
+
+// CAS implements the CASDB CAS interface, with a transaction writing the record only
+// if it wasn't modified since the values were compared.
+func (etcd *etcdClient) CAS(ctx context.Context, table string, key string, expected map[string][]byte, values map[string][]byte) error {
+	if etcd.useInts {
+		return fmt.Errorf("CAS isn't supported with %s, which doesn't store the values", etcdUseInts)
+	}
+	keyStr := table + "/" + key
+	result, revision, err := etcd.getRevision(ctx, keyStr)
+	if err != nil {
+		return err
+	}
+	for field, value := range expected {
+		if !bytes.Equal(result[field], value) {
+			return fmt.Errorf("%w: field %s of %s changed", ycsb.ErrConflict, field, keyStr)
+		}
+	}
+	for field, value := range values {
+		result[field] = value
+	}
+	return etcd.putIfUnmodified(ctx, keyStr, result, revision)
+}
+
+// Increment implements the IncrementDB Increment interface, reading the counter and
+// writing it back in a transaction, again until no other write came in between.
+func (etcd *etcdClient) Increment(ctx context.Context, table string, key string, field string, delta int64) (int64, error) {
+	if etcd.useInts {
+		return 0, fmt.Errorf("Increment isn't supported with %s, which doesn't store the values", etcdUseInts)
+	}
+	keyStr := table + "/" + key
+	for {
+		result, revision, err := etcd.getRevision(ctx, keyStr)
+		if errors.Is(err, ycsb.ErrNotFound) {
+			// a key which doesn't exist has the revision 0
+			result, err = make(map[string][]byte), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var n int64
+		if value, ok := result[field]; ok {
+			if n, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return 0, fmt.Errorf("field %s of %s isn't a counter: %v", field, keyStr, err)
+			}
+		}
+		n += delta
+		result[field] = []byte(strconv.FormatInt(n, 10))
+
+		err = etcd.putIfUnmodified(ctx, keyStr, result, revision)
+		if !errors.Is(err, ycsb.ErrConflict) {
+			return n, err
+		}
+	}
+}
+
 func (etcd *etcdClient) Delete(ctx context.Context, table string, key string) error {
 	_, err := etcd.client.Delete(ctx, table+"/"+key)
 	return err
 }
 
+// forwardTraceParent adds the traceparent of a traced operation to the metadata of its
+// requests, from which a member with distributed tracing enabled joins its spans to
+// the trace of the operation.
+func forwardTraceParent(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if traceParent := util.TraceParent(ctx); traceParent != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", traceParent)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
 type etcdCreator struct{}
 
 const (
-	etcdEndpoints   = "etcd.endpoints"
-	etcdDialTimeout = "etcd.dialtimeout"
-	etcdUseInts     = "ycsb.useints"
+	etcdEndpoints    = "etcd.endpoints"
+	etcdDialTimeout  = "etcd.dialtimeout"
+	etcdSerializable = "etcd.serializable"
+	etcdUsername     = "etcd.username"
+	etcdPassword     = "etcd.password"
+	etcdTLSCA        = "etcd.tls.ca"
+	etcdTLSCert      = "etcd.tls.cert"
+	etcdTLSKey       = "etcd.tls.key"
+	etcdTLSInsecure  = "etcd.tls.insecureskipverify"
+	etcdUseInts      = "ycsb.useints"
 )
 
+// tlsConfig returns the TLS config of the connections, nil if none of the TLS
+// properties are set.
+func tlsConfig(prop *properties.Properties) (*tls.Config, error) {
+	caFile := prop.GetString(etcdTLSCA, "")
+	certFile := prop.GetString(etcdTLSCert, "")
+	keyFile := prop.GetString(etcdTLSKey, "")
+	insecure := prop.GetBool(etcdTLSInsecure, false)
+	if caFile == "" && certFile == "" && keyFile == "" && !insecure {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: insecure}
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s %s", etcdTLSCA, caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
 func (crt etcdCreator) Create(prop *properties.Properties) (ycsb.DB, error) {
 	endpointsStr, ok := prop.Get(etcdEndpoints)
 	if !ok {
@@ -152,17 +383,41 @@ func (crt etcdCreator) Create(prop *properties.Properties) (ycsb.DB, error) {
 	}
 	endpoints := strings.Split(endpointsStr, ",")
 
+	tlsCfg, err := tlsConfig(prop)
+	if err != nil {
+		return nil, err
+	}
+	// the connections gRPC establishes to the members are counted
+	dial := util.CountingDial(new(net.Dialer).DialContext)
 	client, err := clientv3.New(clientv3.Config{
 		Endpoints:   endpoints,
 		DialTimeout: prop.GetParsedDuration(etcdDialTimeout, time.Second*5),
+		TLS:         tlsCfg,
+		Username:    prop.GetString(etcdUsername, ""),
+		Password:    prop.GetString(etcdPassword, ""),
+		DialOptions: []grpc.DialOption{
+			grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				if path := strings.TrimPrefix(addr, "unix://"); path != addr {
+					return dial(ctx, "unix", path)
+				}
+				return dial(ctx, "tcp", addr)
+			}),
+			// chained, since the client sets its own interceptor
+			grpc.WithChainUnaryInterceptor(forwardTraceParent),
+		},
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &etcdClient{
+	etcd := &etcdClient{
 		client:  client,
 		useInts: prop.GetBool(etcdUseInts, false),
-	}, nil
+	}
+	if prop.GetBool(etcdSerializable, false) {
+		// served by any member from its local state, which may be stale
+		etcd.readOpts = []clientv3.OpOption{clientv3.WithSerializable()}
+	}
+	return etcd, nil
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"io/ioutil"
+	"strings"
 
 	"github.com/magiconair/properties"
 	"github.com/minio/minio-go"
@@ -16,6 +17,12 @@ const (
 	minioSecretKey = "minio.secret-key"
 	minioEndpoint  = "minio.endpoint"
 	minioSecure    = "minio.secure"
+
+	// request prices in USD per million requests, like cost.<op>, defaults to the S3
+	// standard tier.
+	minioCostPut  = "minio.cost.put"
+	minioCostGet  = "minio.cost.get"
+	minioCostList = "minio.cost.list"
 )
 
 type minioCreator struct{}
@@ -30,12 +37,19 @@ func (c minioCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 		return nil, err
 	}
 	return &minioDB{
-		db: client,
+		db:       client,
+		costPut:  p.GetFloat64(minioCostPut, 5),
+		costGet:  p.GetFloat64(minioCostGet, 0.4),
+		costList: p.GetFloat64(minioCostList, 5),
 	}, nil
 }
 
 type minioDB struct {
 	db *minio.Client
+
+	costPut  float64
+	costGet  float64
+	costList float64
 }
 
 func (db *minioDB) ToSqlDB() *sql.DB {
@@ -128,6 +142,23 @@ func (db *minioDB) Delete(ctx context.Context, table string, key string) error {
 	return db.db.RemoveObject(table, key)
 }
 
+// EstimateCost estimates the request cost of the operations with S3 style pricing.
+// Deletes are free.
+func (db *minioDB) EstimateCost(ops map[string]int64) (float64, error) {
+	cost := float64(0)
+	for op, count := range ops {
+		switch strings.TrimSuffix(op, "_ERROR") {
+		case "READ":
+			cost += db.costGet * float64(count) / 1e6
+		case "INSERT", "UPDATE":
+			cost += db.costPut * float64(count) / 1e6
+		case "SCAN":
+			cost += db.costList * float64(count) / 1e6
+		}
+	}
+	return cost, nil
+}
+
 func init() {
 	ycsb.RegisterDBCreator("minio", minioCreator{})
 }
@@ -46,6 +46,9 @@ func (m *mongoDB) InitThread(ctx context.Context, threadID int, threadCount int)
 func (m *mongoDB) CleanupThread(ctx context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (m *mongoDB) ConcurrentThreads() {}
+
 // Read a document.
 func (m *mongoDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	projection := map[string]bool{"_id": false}
@@ -84,6 +87,38 @@ func (m *mongoDB) Scan(ctx context.Context, table string, startKey string, count
 	return docs, nil
 }
 
+// CreateIndex implements the QueryDB CreateIndex interface.
+func (m *mongoDB) CreateIndex(ctx context.Context, table string, field string) error {
+	if _, err := m.db.Collection(table).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{field: 1}}); err != nil {
+		return fmt.Errorf("CreateIndex error: %s", err.Error())
+	}
+	return nil
+}
+
+// Query documents by an indexed field.
+func (m *mongoDB) Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) ([]map[string][]byte, error) {
+	projection := map[string]bool{"_id": false}
+	for _, field := range fields {
+		projection[field] = true
+	}
+	limit := int64(count)
+	opt := &options.FindOptions{Projection: projection, Limit: &limit}
+	cursor, err := m.db.Collection(table).Find(ctx, bson.M{field: value}, opt)
+	if err != nil {
+		return nil, fmt.Errorf("Query error: %s", err.Error())
+	}
+	defer cursor.Close(ctx)
+	var docs []map[string][]byte
+	for cursor.Next(ctx) {
+		var doc map[string][]byte
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, fmt.Errorf("Query error: %s", err.Error())
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
 // Insert a document.
 func (m *mongoDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
 	doc := bson.M{"_id": key}
@@ -16,14 +16,14 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
 
-	// mysql package
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
@@ -60,6 +60,8 @@ type mysqlState struct {
 	stmtCache map[string]*sql.Stmt
 
 	conn *sql.Conn
+	// tx is the transaction the operations of RunTx run in, nil outside of it
+	tx *sql.Tx
 }
 
 func (c mysqlCreator) Create(p *properties.Properties) (ycsb.DB, error) {
@@ -79,7 +81,8 @@ func (c mysqlCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 		return nil, err
 	}
 
-	threadCount := int(p.GetInt64(prop.ThreadCount, prop.ThreadCountDefault))
+	// every operation a thread keeps in flight has its own connection
+	threadCount := int(p.GetInt64(prop.ThreadCount, prop.ThreadCountDefault)) * p.GetInt(prop.PipelineInflight, prop.PipelineInflightDefault)
 	db.SetMaxIdleConns(threadCount + 1)
 	db.SetMaxOpenConns(threadCount * 2)
 
@@ -152,6 +155,15 @@ func (db *mysqlDB) getAndCacheStmt(ctx context.Context, query string) (*sql.Stmt
 	return stmt, nil
 }
 
+// txStmt returns the statement in the transaction of the thread, if it's in one.
+func (db *mysqlDB) txStmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	state := ctx.Value(stateKey).(*mysqlState)
+	if state.tx == nil {
+		return stmt
+	}
+	return state.tx.StmtContext(ctx, stmt)
+}
+
 func (db *mysqlDB) clearCacheIfFailed(ctx context.Context, query string, err error) {
 	if err == nil {
 		return
@@ -173,7 +185,7 @@ func (db *mysqlDB) queryRows(ctx context.Context, query string, count int, args
 	if err != nil {
 		return nil, err
 	}
-	rows, err := stmt.QueryContext(ctx, args...)
+	rows, err := db.txStmt(ctx, stmt).QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -240,6 +252,39 @@ func (db *mysqlDB) Scan(ctx context.Context, table string, startKey string, coun
 	return rows, err
 }
 
+// CreateIndex implements the QueryDB CreateIndex interface. MySQL can't create an
+// index only if it doesn't exist, so it looks for it first.
+func (db *mysqlDB) CreateIndex(ctx context.Context, table string, field string) error {
+	name := fmt.Sprintf("%s_%s_idx", table, field)
+	var n int
+	err := db.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?`, table, name).Scan(&n)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	query := fmt.Sprintf("CREATE INDEX %s ON %s (%s)", name, table, field)
+	if db.verbose {
+		fmt.Println(query)
+	}
+	_, err = db.db.ExecContext(ctx, query)
+	return err
+}
+
+// Query implements the QueryDB Query interface.
+func (db *mysqlDB) Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) ([]map[string][]byte, error) {
+	var query string
+	if len(fields) == 0 {
+		query = fmt.Sprintf(`SELECT * FROM %s WHERE %s = ? LIMIT ?`, table, field)
+	} else {
+		query = fmt.Sprintf(`SELECT %s FROM %s WHERE %s = ? LIMIT ?`, strings.Join(fields, ","), table, field)
+	}
+
+	rows, err := db.queryRows(ctx, query, count, value, count)
+	db.clearCacheIfFailed(ctx, query, err)
+
+	return rows, err
+}
+
 func (db *mysqlDB) execQuery(ctx context.Context, query string, args ...interface{}) error {
 	if db.verbose {
 		fmt.Printf("%s %v\n", query, args)
@@ -250,7 +295,7 @@ func (db *mysqlDB) execQuery(ctx context.Context, query string, args ...interfac
 		return err
 	}
 
-	_, err = stmt.ExecContext(ctx, args...)
+	_, err = db.txStmt(ctx, stmt).ExecContext(ctx, args...)
 	db.clearCacheIfFailed(ctx, query, err)
 	return err
 }
@@ -317,6 +362,39 @@ func (db *mysqlDB) Delete(ctx context.Context, table string, key string) error {
 	return db.execQuery(ctx, query, key)
 }
 
+// RunTx implements the TransactionDB RunTx interface. The transaction runs on the
+// connection of the thread.
+func (db *mysqlDB) RunTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	state := ctx.Value(stateKey).(*mysqlState)
+	tx, err := state.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	state.tx = tx
+	defer func() {
+		state.tx = nil
+	}()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClassifyError implements the ErrorClassifierDB ClassifyError interface. The
+// deadlocks, and the write conflicts of TiDB, are conflicts.
+func (db *mysqlDB) ClassifyError(err error) string {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1213, 9007:
+			return ycsb.ErrorClassConflict
+		}
+	}
+	return ""
+}
+
 func (db *mysqlDB) Analyze(ctx context.Context, table string) error {
 	_, err := db.db.Exec(fmt.Sprintf(`ANALYZE TABLE %s`, table))
 	return err
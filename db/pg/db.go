@@ -17,14 +17,14 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
 
-	// pg package
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
@@ -62,6 +62,8 @@ type pgState struct {
 	stmtCache map[string]*sql.Stmt
 
 	conn *sql.Conn
+	// tx is the transaction the operations of RunTx run in, nil outside of it
+	tx *sql.Tx
 }
 
 func (c pgCreator) Create(p *properties.Properties) (ycsb.DB, error) {
@@ -83,7 +85,8 @@ func (c pgCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 		return nil, err
 	}
 
-	threadCount := int(p.GetInt64(prop.ThreadCount, prop.ThreadCountDefault))
+	// every operation a thread keeps in flight has its own connection
+	threadCount := int(p.GetInt64(prop.ThreadCount, prop.ThreadCountDefault)) * p.GetInt(prop.PipelineInflight, prop.PipelineInflightDefault)
 	db.SetMaxIdleConns(threadCount + 1)
 	db.SetMaxOpenConns(threadCount * 2)
 
@@ -93,16 +96,16 @@ func (c pgCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 
 	d.bufPool = util.NewBufPool()
 
-	if err := d.createTable(); err != nil {
-		return nil, err
+	for _, tableName := range util.TableNames(p) {
+		if err := d.createTable(tableName); err != nil {
+			return nil, err
+		}
 	}
 
 	return d, nil
 }
 
-func (db *pgDB) createTable() error {
-	tableName := db.p.GetString(prop.TableName, prop.TableNameDefault)
-
+func (db *pgDB) createTable(tableName string) error {
 	if db.p.GetBool(prop.DropData, prop.DropDataDefault) {
 		if _, err := db.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)); err != nil {
 			return err
@@ -188,6 +191,15 @@ func (db *pgDB) getAndCacheStmt(ctx context.Context, query string) (*sql.Stmt, e
 	return stmt, nil
 }
 
+// txStmt returns the statement in the transaction of the thread, if it's in one.
+func (db *pgDB) txStmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	state := ctx.Value(stateKey).(*pgState)
+	if state.tx == nil {
+		return stmt
+	}
+	return state.tx.StmtContext(ctx, stmt)
+}
+
 func (db *pgDB) clearCacheIfFailed(ctx context.Context, query string, err error) {
 	if err == nil {
 		return
@@ -209,7 +221,7 @@ func (db *pgDB) queryRows(ctx context.Context, query string, count int, args ...
 	if err != nil {
 		return nil, err
 	}
-	rows, err := stmt.QueryContext(ctx, args...)
+	rows, err := db.txStmt(ctx, stmt).QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -276,6 +288,31 @@ func (db *pgDB) Scan(ctx context.Context, table string, startKey string, count i
 	return rows, err
 }
 
+// CreateIndex implements the QueryDB CreateIndex interface.
+func (db *pgDB) CreateIndex(ctx context.Context, table string, field string) error {
+	query := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s (%s)", table, field, table, field)
+	if db.verbose {
+		fmt.Println(query)
+	}
+	_, err := db.db.ExecContext(ctx, query)
+	return err
+}
+
+// Query implements the QueryDB Query interface.
+func (db *pgDB) Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) ([]map[string][]byte, error) {
+	var query string
+	if len(fields) == 0 {
+		query = fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1 LIMIT $2`, table, field)
+	} else {
+		query = fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 LIMIT $2`, strings.Join(fields, ","), table, field)
+	}
+
+	rows, err := db.queryRows(ctx, query, count, value, count)
+	db.clearCacheIfFailed(ctx, query, err)
+
+	return rows, err
+}
+
 func (db *pgDB) execQuery(ctx context.Context, query string, args ...interface{}) error {
 	if db.verbose {
 		fmt.Printf("%s %v\n", query, args)
@@ -286,7 +323,7 @@ func (db *pgDB) execQuery(ctx context.Context, query string, args ...interface{}
 		return err
 	}
 
-	_, err = stmt.ExecContext(ctx, args...)
+	_, err = db.txStmt(ctx, stmt).ExecContext(ctx, args...)
 	db.clearCacheIfFailed(ctx, query, err)
 	return err
 }
@@ -353,6 +390,39 @@ func (db *pgDB) Delete(ctx context.Context, table string, key string) error {
 	return db.execQuery(ctx, query, key)
 }
 
+// RunTx implements the TransactionDB RunTx interface. The transaction runs on the
+// connection of the thread.
+func (db *pgDB) RunTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	state := ctx.Value(stateKey).(*pgState)
+	tx, err := state.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	state.tx = tx
+	defer func() {
+		state.tx = nil
+	}()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClassifyError implements the ErrorClassifierDB ClassifyError interface. The
+// serialization failures and deadlocks are conflicts.
+func (db *pgDB) ClassifyError(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", "40P01":
+			return ycsb.ErrorClassConflict
+		}
+	}
+	return ""
+}
+
 func init() {
 	ycsb.RegisterDBCreator("pg", pgCreator{})
 	ycsb.RegisterDBCreator("postgresql", pgCreator{})
@@ -149,7 +149,7 @@ func (cfg *pbkvsConfig) Read(ctx context.Context, table string, key string, fiel
 	resp := <-client.outChan
 	log.Printf("[get] %s received %v", client.clientCtx.IFace().Self().AsString(), resp)
 	if !resp.IsFunction() {
-		return nil, fmt.Errorf("key %s not found", keyStr)
+		return nil, fmt.Errorf("key %s %w", keyStr, ycsb.ErrNotFound)
 	}
 
 	result := make(map[string][]byte)
@@ -0,0 +1,359 @@
+package pgo_raftkv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"example.org/raftkvs"
+	"github.com/UBC-NSS/pgo/distsys"
+	"github.com/UBC-NSS/pgo/distsys/resources"
+	"github.com/UBC-NSS/pgo/distsys/tla"
+	"github.com/dgraph-io/badger/v3"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// clusterTest enables the end-to-end tests, which elect a leader over real sockets and
+// so take seconds, and may take much longer if the elections keep splitting the votes.
+var clusterTest = flag.Bool("cluster", false, "run the tests against an in-process raftkvs cluster")
+
+// freeAddrs returns n localhost addresses nothing listens on.
+func freeAddrs(t *testing.T, n int) []string {
+	var addrs []string
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, l.Addr().String())
+		defer l.Close()
+	}
+	return addrs
+}
+
+// testCluster is a raftkvs cluster running in the test process, whose servers and
+// server senders are monitored by a single monitor.
+type testCluster struct {
+	endpoints []string
+	monitor   string
+
+	mon  *resources.Monitor
+	db   *badger.DB
+	ctxs []*distsys.MPCalContext
+	errs chan error
+}
+
+// startCluster starts numServers servers, with the same constants the driver uses.
+func startCluster(t *testing.T, numServers int, numClients int) *testCluster {
+	// servers 1..numServers, then their senders, then the monitor
+	addrs := freeAddrs(t, 2*numServers+1)
+	c := &testCluster{
+		endpoints: addrs[:numServers],
+		monitor:   addrs[2*numServers],
+		errs:      make(chan error, 2*numServers),
+	}
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.db = db
+
+	c.mon = resources.NewMonitor(c.monitor)
+	go func() {
+		_ = c.mon.ListenAndServe()
+	}()
+
+	constants := []distsys.MPCalContextConfigFn{
+		distsys.DefineConstantValue("NumServers", tla.MakeTLANumber(int32(numServers))),
+		distsys.DefineConstantValue("NumClients", tla.MakeTLANumber(int32(numClients))),
+		distsys.DefineConstantValue("ExploreFail", tla.TLA_FALSE),
+		distsys.DefineConstantValue("KeySet", tla.MakeTLASet()),
+		distsys.DefineConstantValue("Debug", tla.TLA_FALSE),
+		raftkvs.PersistentLogConstantDefs,
+	}
+	network := func(self tla.TLAValue) distsys.ArchetypeResourceMaker {
+		return resources.RelaxedMailboxesMaker(func(idx tla.TLAValue) (resources.MailboxKind, string) {
+			kind := resources.MailboxesRemote
+			if idx.Equal(self) {
+				kind = resources.MailboxesLocal
+			}
+			if idx.IsString() {
+				// the reply points of the driver
+				return kind, idx.AsString()
+			}
+			return kind, addrs[idx.AsNumber()-1]
+		})
+	}
+	fd := func() distsys.ArchetypeResourceMaker {
+		return resources.FailureDetectorMaker(
+			func(tla.TLAValue) string { return c.monitor },
+			resources.WithFailureDetectorPullInterval(100*time.Millisecond),
+			resources.WithFailureDetectorTimeout(200*time.Millisecond),
+		)
+	}
+
+	for i := 1; i <= numServers; i++ {
+		self := tla.MakeTLANumber(int32(i))
+		iface := distsys.NewMPCalContextWithoutArchetype(constants...).IFace()
+		stateMaker := resources.LocalSharedMaker(raftkvs.Follower(iface))
+		nextIndexMaker := resources.LocalSharedMaker(
+			tla.MakeTLAFunction([]tla.TLAValue{raftkvs.ServerSet(iface)}, func([]tla.TLAValue) tla.TLAValue {
+				return tla.MakeTLANumber(1)
+			}),
+		)
+		logMaker := resources.LocalSharedMaker(tla.MakeTLATuple())
+		currentTermMaker := resources.LocalSharedMaker(tla.MakeTLANumber(1))
+		commitIndexMaker := resources.LocalSharedMaker(tla.MakeTLANumber(0))
+		votedForMaker := distsys.LocalArchetypeResourceMaker(raftkvs.Nil(iface))
+
+		mapMaker := func(maker distsys.ArchetypeResourceMaker) distsys.ArchetypeResourceMaker {
+			return resources.IncrementalMapMaker(func(index tla.TLAValue) distsys.ArchetypeResourceMaker {
+				if index.Equal(self) {
+					return maker
+				}
+				panic("wrong index")
+			})
+		}
+
+		srvCh := make(chan tla.TLAValue, 100)
+		srvCtx := distsys.NewMPCalContext(self, raftkvs.AServer,
+			distsys.EnsureMPCalContextConfigs(constants...),
+			distsys.EnsureArchetypeRefParam("net", network(self)),
+			distsys.EnsureArchetypeRefParam("fd", fd()),
+			distsys.EnsureArchetypeDerivedRefParam("netLen", "net", resources.MailboxesLengthMaker),
+			distsys.EnsureArchetypeRefParam("netEnabled", resources.PlaceHolderResourceMaker()),
+			distsys.EnsureArchetypeRefParam("state", mapMaker(stateMaker)),
+			distsys.EnsureArchetypeRefParam("nextIndex", mapMaker(nextIndexMaker)),
+			distsys.EnsureArchetypeRefParam("log", mapMaker(logMaker)),
+			distsys.EnsureArchetypeRefParam("currentTerm", mapMaker(resources.PersistentResourceMaker(
+				fmt.Sprintf("Server%v.currentTerm", self), db, currentTermMaker))),
+			distsys.EnsureArchetypeRefParam("commitIndex", mapMaker(commitIndexMaker)),
+			distsys.EnsureArchetypeRefParam("timer", raftkvs.TimerResourceMaker()),
+			distsys.EnsureArchetypeRefParam("in", resources.OutputChannelMaker(srvCh)),
+			distsys.EnsureArchetypeRefParam("votedFor", resources.PersistentResourceMaker(
+				fmt.Sprintf("Server%v.votedFor", self), db, votedForMaker)),
+			distsys.EnsureArchetypeRefParam("plog", mapMaker(raftkvs.PersistentLogMaker(fmt.Sprintf("Server%v.plog", self), db))),
+		)
+
+		sndSelf := tla.MakeTLANumber(int32(i + numServers))
+		sndCtx := distsys.NewMPCalContext(sndSelf, raftkvs.AServerSender,
+			distsys.EnsureMPCalContextConfigs(constants...),
+			distsys.EnsureArchetypeRefParam("net", network(sndSelf)),
+			distsys.EnsureArchetypeRefParam("fd", fd()),
+			distsys.EnsureArchetypeRefParam("netEnabled",
+				resources.IncrementalMapMaker(func(tla.TLAValue) distsys.ArchetypeResourceMaker {
+					return distsys.LocalArchetypeResourceMaker(tla.TLA_TRUE)
+				})),
+			distsys.EnsureArchetypeValueParam("sid", self),
+			distsys.EnsureArchetypeRefParam("state", mapMaker(stateMaker)),
+			distsys.EnsureArchetypeRefParam("nextIndex", mapMaker(nextIndexMaker)),
+			distsys.EnsureArchetypeRefParam("log", mapMaker(logMaker)),
+			distsys.EnsureArchetypeRefParam("currentTerm", mapMaker(currentTermMaker)),
+			distsys.EnsureArchetypeRefParam("commitIndex", mapMaker(commitIndexMaker)),
+			distsys.EnsureArchetypeRefParam("in", raftkvs.CustomInChanMaker(srvCh)),
+		)
+
+		for _, ctx := range []*distsys.MPCalContext{srvCtx, sndCtx} {
+			ctx := ctx
+			c.ctxs = append(c.ctxs, ctx)
+			go func() {
+				c.errs <- c.mon.RunArchetype(ctx)
+			}()
+		}
+	}
+
+	// the mailboxes start listening in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for _, addr := range c.endpoints {
+		for {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				_ = conn.Close()
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("server %s didn't start: %v", addr, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return c
+}
+
+// props returns the driver properties to connect the reply points to the cluster.
+func (c *testCluster) props(replyPoints []string) *properties.Properties {
+	var monitors []string
+	for _, endpoint := range c.endpoints {
+		monitors = append(monitors, endpoint+"->"+c.monitor)
+	}
+	p := properties.NewProperties()
+	p.Set(pgoRaftKVEndpoints, strings.Join(c.endpoints, ","))
+	p.Set(pgoRaftKVEndpointMonitors, strings.Join(monitors, ","))
+	p.Set(pgoRaftKVClientReplyPoints, strings.Join(replyPoints, ","))
+	return p
+}
+
+// stopServer stops the server i, numbered from 1, and its sender.
+func (c *testCluster) stopServer(i int) {
+	c.ctxs[2*(i-1)].Stop()
+	c.ctxs[2*(i-1)+1].Stop()
+}
+
+func (c *testCluster) stop(t *testing.T) {
+	for _, ctx := range c.ctxs {
+		ctx.Stop()
+	}
+	for range c.ctxs {
+		if err := <-c.errs; err != nil {
+			t.Errorf("archetype error: %v", err)
+		}
+	}
+	_ = c.mon.Close()
+	_ = c.db.Close()
+}
+
+// TestCluster runs a miniature workload through the driver against a three server
+// cluster, and checks that every read sees the last write.
+func TestCluster(t *testing.T) {
+	if !*clusterTest {
+		t.Skip("run with -cluster to start a raftkvs cluster")
+	}
+	const (
+		numServers = 3
+		numThreads = 2
+		numKeys    = 10
+	)
+	cluster := startCluster(t, numServers, numThreads)
+	defer cluster.stop(t)
+
+	replyPoints := freeAddrs(t, numThreads)
+	p := cluster.props(replyPoints)
+	p.Set(pgoRaftKVRequestTimeout, "200ms")
+	db, err := raftCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	errs := make(chan error, numThreads)
+	for thread := 0; thread < numThreads; thread++ {
+		go func(thread int) {
+			ctx := db.InitThread(context.Background(), thread, numThreads)
+			defer db.CleanupThread(ctx)
+			errs <- func() error {
+				for round := 0; round < 3; round++ {
+					for i := 0; i < numKeys; i++ {
+						key := fmt.Sprintf("user%d-%d", thread, i)
+						values := map[string][]byte{
+							"field0": []byte(fmt.Sprintf("value%d", round)),
+							"field1": []byte(key),
+						}
+						if err := db.Insert(ctx, "usertable", key, values); err != nil {
+							return fmt.Errorf("insert %s: %v", key, err)
+						}
+						read, err := db.Read(ctx, "usertable", key, nil)
+						if err != nil {
+							return fmt.Errorf("read %s: %v", key, err)
+						}
+						for field, value := range values {
+							if !bytes.Equal(read[field], value) {
+								return fmt.Errorf("read %s of %s, expecting %s", read[field], key, value)
+							}
+						}
+					}
+				}
+				return nil
+			}()
+		}(thread)
+	}
+	deadline := time.After(time.Minute)
+	for thread := 0; thread < numThreads; thread++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-deadline:
+			// the driver retries until the cluster answers, which it may never do
+			t.Fatalf("the workload didn't finish; driver statistics: %v", db.(*raftClient).ExtendedStats())
+		}
+	}
+
+	stats := db.(*raftClient).ExtendedStats()
+	if want := int64(numThreads * numKeys * 3 * 2); stats["requests"] != want {
+		t.Errorf("%d requests counted, expecting %d", stats["requests"], want)
+	}
+}
+
+// TestClusterTimeout stops the leader, and checks that a request gives up once the
+// deadline of operation.timeout expires rather than retrying until another leader is
+// elected.
+func TestClusterTimeout(t *testing.T) {
+	if !*clusterTest {
+		t.Skip("run with -cluster to start a raftkvs cluster")
+	}
+	cluster := startCluster(t, 3, 1)
+	defer cluster.stop(t)
+
+	// the deadline expires before the request is sent again, so before the request can
+	// reach another leader
+	p := cluster.props(freeAddrs(t, 1))
+	p.Set(pgoRaftKVRequestTimeout, "1s")
+	p.Set(prop.OperationTimeout, "100ms")
+	requestTimeout := p.GetParsedDuration(pgoRaftKVRequestTimeout, 0)
+	timeout := p.GetParsedDuration(prop.OperationTimeout, 0)
+	db, err := raftCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := db.InitThread(context.Background(), 0, 1)
+	defer db.CleanupThread(ctx)
+
+	// the first requests wait for the election
+	electionCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := db.Insert(electionCtx, "usertable", "user0", map[string][]byte{"field0": []byte("before")}); err != nil {
+		t.Fatal(err)
+	}
+	// the leader is the source of the responses
+	worker := ctx.Value(threadIdxTag{}).(*raftWorker)
+	client := worker.acquire()
+	err = client.sendRequest(electionCtx, tla.MakeTLARecord([]tla.TLARecordField{
+		{Key: tla.MakeTLAString("type"), Value: raftkvs.Get(client.clientCtx.IFace())},
+		{Key: tla.MakeTLAString("key"), Value: tla.MakeTLAString("user0")},
+	}), nil, false)
+	var resp tla.TLAValue
+	if err == nil {
+		resp, err = client.awaitResponse(electionCtx, requestTimeout)
+	}
+	worker.release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster.stopServer(int(resp.ApplyFunction(tla.MakeTLAString("msource")).AsNumber()))
+
+	opCtx, cancelOp := context.WithTimeout(ctx, timeout)
+	defer cancelOp()
+	start := time.Now()
+	err = db.Insert(opCtx, "usertable", "user0", map[string][]byte{"field0": []byte("after")})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("insert without a leader returned %v, expecting the deadline to expire", err)
+	}
+	if elapsed := time.Since(start); elapsed >= requestTimeout {
+		t.Errorf("insert gave up after %v, expecting it to give up before the request timeout", elapsed)
+	}
+
+	stats := db.(*raftClient).ExtendedStats()
+	if stats["abandoned"] != 1 {
+		t.Errorf("%d requests abandoned, expecting 1", stats["abandoned"])
+	}
+}
@@ -0,0 +1,85 @@
+package pgo_raftkv
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strings"
+	"time"
+
+	"github.com/UBC-NSS/pgo/distsys/resources"
+	"github.com/UBC-NSS/pgo/distsys/tla"
+)
+
+// maxConfigCheckServers bounds the server counts tried to find the NumServers a
+// server was started with.
+const maxConfigCheckServers = 64
+
+// isAlive asks the monitor whether it runs the archetype, and returns false if the
+// monitor doesn't know it.
+func isAlive(client *rpc.Client, archetypeID int, timeout time.Duration) (bool, error) {
+	var state resources.ArchetypeState
+	id := tla.MakeTLANumber(int32(archetypeID))
+	call := client.Go("MonitorRPCReceiver.IsAlive", &id, &state, nil)
+	select {
+	case <-call.Done:
+	case <-time.After(timeout):
+		return false, errors.New("timed out")
+	}
+	var serverErr rpc.ServerError
+	if errors.As(call.Error, &serverErr) {
+		return false, nil
+	}
+	return call.Error == nil, call.Error
+}
+
+// configCheck asks the monitor of every endpoint which archetypes it runs, to verify
+// that the servers were started with the same constants as the client: server i runs
+// at the i-th endpoint, and its sender, if monitored, is archetype i + NumServers.
+func configCheck(endpoints []string, endpointMonitors map[string]string, timeout time.Duration) error {
+	numServers := len(endpoints)
+	var mismatches []string
+	for i, endpoint := range endpoints {
+		server := i + 1
+		conn, err := net.DialTimeout("tcp", endpointMonitors[endpoint], timeout)
+		if err != nil {
+			return err
+		}
+		client := rpc.NewClient(conn)
+		mismatch, err := func() (string, error) {
+			defer client.Close()
+
+			if ok, err := isAlive(client, server, timeout); err != nil {
+				return "", fmt.Errorf("monitor %s of %s: %v", endpointMonitors[endpoint], endpoint, err)
+			} else if !ok {
+				return fmt.Sprintf("%s doesn't run server %d; %s must list the servers in the order of their indices",
+					endpoint, server, pgoRaftKVEndpoints), nil
+			}
+			if ok, err := isAlive(client, server+numServers, timeout); err != nil || ok {
+				return "", err
+			}
+			// the sender of the server isn't where NumServers puts it, look for it elsewhere
+			for n := 1; n <= maxConfigCheckServers; n++ {
+				if ok, err := isAlive(client, server+n, timeout); err != nil {
+					return "", err
+				} else if ok {
+					return fmt.Sprintf("server %d at %s was started with NumServers=%d, but %s has %d endpoints",
+						server, endpoint, n, pgoRaftKVEndpoints, numServers), nil
+				}
+			}
+			// the senders aren't monitored, so NumServers can't be checked
+			return "", nil
+		}()
+		if err != nil {
+			return err
+		}
+		if mismatch != "" {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	if len(mismatches) != 0 {
+		return fmt.Errorf("pgo-raftkv servers don't match the client configuration:\n\t%s", strings.Join(mismatches, "\n\t"))
+	}
+	return nil
+}
@@ -4,15 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"example.org/raftkvs"
 	"fmt"
 	"github.com/UBC-NSS/pgo/distsys"
 	"github.com/UBC-NSS/pgo/distsys/resources"
 	"github.com/UBC-NSS/pgo/distsys/tla"
 	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	"go.uber.org/multierr"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,14 +32,99 @@ func assert(cond bool) {
 	}
 }
 
+// payloadMode selects how record values are encoded into TLA values.
+type payloadMode int
+
+const (
+	// payloadFull sends every field as a TLA record, and parses it back on read.
+	payloadFull payloadMode = iota
+	// payloadSize sends the stringified length of the JSON-encoded record.
+	payloadSize
+	// payloadOpaque sends a random string of the record size, so the message size
+	// matches payloadFull while skipping parsing on read.
+	payloadOpaque
+	// payloadFixed sends the same integer for every record, the smallest payload.
+	payloadFixed
+	// payloadHash sends the hex FNV-1a hash of the JSON-encoded record, a fixed size
+	// payload which still depends on the record.
+	payloadHash
+)
+
+func parsePayloadMode(mode string) (payloadMode, error) {
+	switch strings.ToLower(mode) {
+	case "full":
+		return payloadFull, nil
+	// int is the original name of the size mode
+	case "size", "int":
+		return payloadSize, nil
+	case "opaque":
+		return payloadOpaque, nil
+	case "fixed":
+		return payloadFixed, nil
+	case "hash":
+		return payloadHash, nil
+	default:
+		return payloadFull, fmt.Errorf("unknown %s %q; expecting full, size, fixed, hash or opaque", pgoRaftKVPayloadMode, mode)
+	}
+}
+
+// validatePayload checks that a value read back has the form the payload mode writes.
+// Deletes write an empty record, which has an empty payload in the modes other than
+// the full one.
+func (cfg *raftClient) validatePayload(value tla.TLAValue) error {
+	if cfg.payloadMode == payloadFull {
+		if !value.IsFunction() {
+			return fmt.Errorf("read %v, expecting a record", value)
+		}
+		return nil
+	}
+	if !value.IsString() {
+		return fmt.Errorf("read %v, expecting a string payload", value)
+	}
+	payload := value.AsString()
+	valid := true
+	switch cfg.payloadMode {
+	case payloadSize:
+		_, err := strconv.ParseUint(payload, 10, 64)
+		valid = payload == "" || err == nil
+	case payloadOpaque:
+		valid = payload == "" || len(payload) == cfg.payloadSize
+	case payloadFixed:
+		valid = payload == "" || payload == cfg.fixedPayload
+	case payloadHash:
+		_, err := strconv.ParseUint(payload, 16, 64)
+		valid = payload == "" || (len(payload) == 16 && err == nil)
+	}
+	if !valid {
+		return fmt.Errorf("read payload %q, which the %s payload mode doesn't write", payload, pgoRaftKVPayloadMode)
+	}
+	return nil
+}
+
 type raftClient struct {
 	endpoints         []string
 	endpointMonitors  map[string]string
 	clientReplyPoints []string
 	requestTimeout    time.Duration
-	useInts           bool
-
+	payloadMode       payloadMode
+	payloadSize       int
+	fixedPayload      string
+	// shareReplyPoints lets several threads share the client archetype of a reply point
+	shareReplyPoints bool
+	// the capacities of the channels to and from the client archetypes
+	inBuffer, outBuffer, timeoutBuffer int
+	// nonBlocking fails the requests the archetype isn't ready to take, instead of waiting
+	nonBlocking bool
+
+	stats    raftStats
+	fdEvents *fdEvents
+	// namespaces are the key namespaces of the tables mapped by pgo-raftkv.namespaces
+	namespaces map[string]*namespace
+
+	lock sync.Mutex
+	// clientThreads are the client archetypes, by reply point
 	clientThreads []*raftClientThread
+	workers       []*raftWorker
 }
 
 type threadIdxTag struct{}
@@ -38,6 +133,108 @@ type raftClientThread struct {
 	clientCtx              *distsys.MPCalContext
 	errCh                  chan error
 	inCh, outCh, timeoutCh chan tla.TLAValue
+	r                      *rand.Rand
+	// turn is held by the worker whose request the archetype is processing
+	turn chan struct{}
+
+	stats *raftStats
+	// nsStats holds the *raftStats of the namespace of the current request, nil if the
+	// table isn't mapped to one
+	nsStats atomic.Value
+	// reqIdx mirrors the archetype's request index, which is bumped for every request read from inCh
+	reqIdx   int64
+	attempts int64
+}
+
+// recordStats updates the statistics of the client, and of the namespace of the current
+// request if it has one.
+func (client *raftClientThread) recordStats(fn func(stats *raftStats)) {
+	fn(client.stats)
+	if nsStats := client.nsStats.Load().(*raftStats); nsStats != nil {
+		fn(nsStats)
+	}
+}
+
+// errQueueFull is returned by non-blocking requests the archetype isn't ready to take.
+var errQueueFull = errors.New("pgo-raftkv request queue full")
+
+// sendRequest submits the request to the archetype. If nonBlocking, it returns
+// errQueueFull rather than waiting for the archetype to take it, and it gives up
+// waiting if ctx is done.
+func (client *raftClientThread) sendRequest(ctx context.Context, req tla.TLAValue, nsStats *raftStats, nonBlocking bool) error {
+	client.nsStats.Store(nsStats)
+	atomic.AddInt64(&client.reqIdx, 1)
+	if nonBlocking {
+		select {
+		case client.inCh <- req:
+		default:
+			atomic.AddInt64(&client.reqIdx, -1)
+			client.recordStats(func(stats *raftStats) {
+				atomic.AddInt64(&stats.queueFull, 1)
+			})
+			return errQueueFull
+		}
+	} else {
+		select {
+		case client.inCh <- req:
+		case <-ctx.Done():
+			atomic.AddInt64(&client.reqIdx, -1)
+			return ctx.Err()
+		}
+	}
+	client.recordStats(func(stats *raftStats) {
+		atomic.AddInt64(&stats.requests, 1)
+	})
+	client.attempts = 1
+	return nil
+}
+
+// awaitResponse waits for the response to the current request, firing the timeout of
+// the archetype every requestTimeout so that it sends the request again. If ctx is done
+// first, the request is abandoned. The archetype still completes it before taking the
+// next request, so the responses are matched to the current request by their index,
+// and those of the abandoned requests are discarded.
+func (client *raftClientThread) awaitResponse(ctx context.Context, requestTimeout time.Duration) (tla.TLAValue, error) {
+	for {
+		select {
+		case resp := <-client.outCh:
+			reqIdx := tla.MakeTLANumber(int32(atomic.LoadInt64(&client.reqIdx)))
+			if !resp.ApplyFunction(tla.MakeTLAString("mresponse")).ApplyFunction(tla.MakeTLAString("idx")).Equal(reqIdx) {
+				client.recordStats(func(stats *raftStats) {
+					atomic.AddInt64(&stats.abandonedResponses, 1)
+				})
+				continue
+			}
+			client.receiveResponse()
+			return resp, nil
+		case <-time.After(requestTimeout):
+			client.fireTimeout()
+		case <-ctx.Done():
+			client.recordStats(func(stats *raftStats) {
+				atomic.AddInt64(&stats.abandoned, 1)
+			})
+			return tla.TLAValue{}, ctx.Err()
+		}
+	}
+}
+
+func (client *raftClientThread) fireTimeout() {
+	// clear timeout channel
+	select {
+	case <-client.timeoutCh:
+	default:
+	}
+	client.timeoutCh <- tla.TLA_TRUE
+	client.recordStats(func(stats *raftStats) {
+		atomic.AddInt64(&stats.timeoutFires, 1)
+	})
+	client.attempts++
+}
+
+func (client *raftClientThread) receiveResponse() {
+	client.recordStats(func(stats *raftStats) {
+		stats.recordAttempts(client.attempts)
+	})
 }
 
 func (cfg *raftClient) ToSqlDB() *sql.DB {
@@ -47,8 +244,24 @@ func (cfg *raftClient) ToSqlDB() *sql.DB {
 func (cfg *raftClient) Close() error {
 	var err error
 	for _, client := range cfg.clientThreads {
+		if client == nil {
+			continue
+		}
+		// the archetype may be blocked sending the response of an abandoned request,
+		// which no request is left to discard
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-client.outCh:
+				case <-done:
+					return
+				}
+			}
+		}()
 		client.clientCtx.Stop()
 		err = multierr.Append(err, <-client.errCh)
+		close(done)
 	}
 	if err != nil {
 		fmt.Printf("error closing RaftKV clients %v\n", err)
@@ -57,10 +270,24 @@ func (cfg *raftClient) Close() error {
 }
 
 func (cfg *raftClient) InitThread(ctx context.Context, threadIdx int, threadCount int) context.Context {
-	if threadCount != len(cfg.clientReplyPoints) {
-		panic(fmt.Errorf("%s must contain %d elements (equal to thread count); contains %v", pgoRaftKVClientReplyPoints, threadCount, cfg.clientReplyPoints))
+	if threadCount != len(cfg.clientReplyPoints) && !cfg.shareReplyPoints {
+		panic(fmt.Errorf("%s must contain %d elements (equal to thread count), or %s be set; contains %v",
+			pgoRaftKVClientReplyPoints, threadCount, pgoRaftKVShareReplyPoints, cfg.clientReplyPoints))
+	}
+
+	cfg.lock.Lock()
+	defer cfg.lock.Unlock()
+	replyPoint := threadIdx % len(cfg.clientReplyPoints)
+	if cfg.clientThreads[replyPoint] == nil {
+		cfg.clientThreads[replyPoint] = cfg.newClientThread(replyPoint)
 	}
+	worker := &raftWorker{client: cfg.clientThreads[replyPoint]}
+	cfg.workers = append(cfg.workers, worker)
+	return context.WithValue(ctx, threadIdxTag{}, worker)
+}
 
+// newClientThread starts the client archetype of the reply point.
+func (cfg *raftClient) newClientThread(replyPoint int) *raftClientThread {
 	errCh := make(chan error, 1)
 	numServers := len(cfg.endpoints)
 	constants := []distsys.MPCalContextConfigFn{
@@ -69,58 +296,64 @@ func (cfg *raftClient) InitThread(ctx context.Context, threadIdx int, threadCoun
 		distsys.DefineConstantValue("KeySet", tla.MakeTLASet()), // at runtime, we support growing the key set
 		distsys.DefineConstantValue("Debug", tla.TLA_FALSE),
 	}
-	self := tla.MakeTLAString(cfg.clientReplyPoints[threadIdx])
-	inChan := make(chan tla.TLAValue)
-	outChan := make(chan tla.TLAValue)
-	timeoutCh := make(chan tla.TLAValue, 1)
-	clientCtx := distsys.NewMPCalContext(self, raftkvs.AClient,
-		distsys.EnsureMPCalContextConfigs(constants...),
-		distsys.EnsureArchetypeRefParam("net", resources.RelaxedMailboxesMaker(func(idx tla.TLAValue) (resources.MailboxKind, string) {
-			if idx.Equal(self) {
-				return resources.MailboxesLocal, idx.AsString()
-			} else if idx.IsNumber() && int(idx.AsNumber()) <= len(cfg.endpoints) {
-				return resources.MailboxesRemote, cfg.endpoints[int(idx.AsNumber())-1]
-			} else if idx.IsString() {
-				return resources.MailboxesRemote, idx.AsString()
-			} else {
-				panic(fmt.Errorf("count not link index to hostname: %v", idx))
-			}
-		})),
-		distsys.EnsureArchetypeRefParam("fd", resources.FailureDetectorMaker(
-			func(index tla.TLAValue) string {
-				endpoint := cfg.endpoints[index.AsNumber()-1]
-				monAddr, ok := cfg.endpointMonitors[endpoint]
-				if !ok {
-					panic(fmt.Errorf("%v is not a server whose monitor we know! options: %v", index, cfg.endpointMonitors))
-				}
-				return monAddr
-			},
-			resources.WithFailureDetectorPullInterval(100*time.Millisecond),
-			resources.WithFailureDetectorTimeout(200*time.Millisecond),
-		)),
-		distsys.EnsureArchetypeRefParam("in", resources.InputChannelMaker(inChan)),
-		distsys.EnsureArchetypeRefParam("out", resources.OutputChannelMaker(outChan)),
-		distsys.EnsureArchetypeDerivedRefParam("netLen", "net", resources.MailboxesLengthMaker),
-		distsys.EnsureArchetypeRefParam("timeout", resources.InputChannelMaker(timeoutCh)))
-
+	self := tla.MakeTLAString(cfg.clientReplyPoints[replyPoint])
+	inChan := make(chan tla.TLAValue, cfg.inBuffer)
+	outChan := make(chan tla.TLAValue, cfg.outBuffer)
+	timeoutCh := make(chan tla.TLAValue, cfg.timeoutBuffer)
 	clientThread := &raftClientThread{
-		clientCtx: clientCtx,
 		errCh:     errCh,
 		inCh:      inChan,
 		outCh:     outChan,
 		timeoutCh: timeoutCh,
+		r:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		turn:      make(chan struct{}, 1),
+		stats:     &cfg.stats,
 	}
-
-	cfg.clientThreads = append(cfg.clientThreads, clientThread)
-	if len(cfg.clientThreads) > threadCount {
-		panic("too many client threads!")
-	}
+	clientThread.nsStats.Store((*raftStats)(nil))
+	mailboxesMaker := resources.RelaxedMailboxesMaker(func(idx tla.TLAValue) (resources.MailboxKind, string) {
+		if idx.Equal(self) {
+			return resources.MailboxesLocal, idx.AsString()
+		} else if idx.IsNumber() && int(idx.AsNumber()) <= len(cfg.endpoints) {
+			return resources.MailboxesRemote, cfg.endpoints[int(idx.AsNumber())-1]
+		} else if idx.IsString() {
+			return resources.MailboxesRemote, idx.AsString()
+		} else {
+			panic(fmt.Errorf("count not link index to hostname: %v", idx))
+		}
+	})
+	// the mailboxes are wrapped to count the responses the archetype discards, so netLen
+	// must be derived from the unwrapped mailboxes
+	mailboxes := mailboxesMaker.Make()
+	mailboxesMaker.Configure(mailboxes)
+	// likewise, the failure detectors are wrapped to report their state changes
+	fdsMaker := resources.FailureDetectorMaker(
+		func(index tla.TLAValue) string {
+			endpoint := cfg.endpoints[index.AsNumber()-1]
+			monAddr, ok := cfg.endpointMonitors[endpoint]
+			if !ok {
+				panic(fmt.Errorf("%v is not a server whose monitor we know! options: %v", index, cfg.endpointMonitors))
+			}
+			return monAddr
+		},
+		resources.WithFailureDetectorPullInterval(100*time.Millisecond),
+		resources.WithFailureDetectorTimeout(200*time.Millisecond),
+	)
+	fds := fdsMaker.Make()
+	fdsMaker.Configure(fds)
+	clientCtx := distsys.NewMPCalContext(self, raftkvs.AClient,
+		distsys.EnsureMPCalContextConfigs(constants...),
+		distsys.EnsureArchetypeRefParam("net", countingMailboxesMaker(mailboxes, self, clientThread)),
+		distsys.EnsureArchetypeRefParam("fd", watchedFailureDetectorMaker(fds, cfg.fdEvents)),
+		distsys.EnsureArchetypeRefParam("in", resources.InputChannelMaker(inChan)),
+		distsys.EnsureArchetypeRefParam("out", resources.OutputChannelMaker(outChan)),
+		distsys.EnsureArchetypeRefParam("netLen", resources.MailboxesLengthMaker(mailboxes)),
+		distsys.EnsureArchetypeRefParam("timeout", resources.InputChannelMaker(timeoutCh)))
+	clientThread.clientCtx = clientCtx
 
 	go func() {
 		errCh <- clientCtx.Run()
 	}()
-
-	return context.WithValue(ctx, threadIdxTag{}, clientThread)
+	return clientThread
 }
 
 func (cfg *raftClient) CleanupThread(_ context.Context) {
@@ -128,8 +361,10 @@ func (cfg *raftClient) CleanupThread(_ context.Context) {
 }
 
 func (cfg *raftClient) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
-	client := ctx.Value(threadIdxTag{}).(*raftClientThread)
-	keyStr := table + "/" + key
+	worker := ctx.Value(threadIdxTag{}).(*raftWorker)
+	client := worker.acquire()
+	defer worker.release()
+	keyStr, nsStats := cfg.namespacedKey(table, key)
 
 	var fieldFilter map[string]bool = nil
 	if len(fields) != 0 {
@@ -138,77 +373,127 @@ func (cfg *raftClient) Read(ctx context.Context, table string, key string, field
 			fieldFilter[field] = true
 		}
 	}
-	client.inCh <- tla.MakeTLARecord([]tla.TLARecordField{
+	err := client.sendRequest(ctx, tla.MakeTLARecord([]tla.TLARecordField{
 		{Key: tla.MakeTLAString("type"), Value: raftkvs.Get(client.clientCtx.IFace())},
 		{Key: tla.MakeTLAString("key"), Value: tla.MakeTLAString(keyStr)},
-	})
+	}), nsStats, cfg.nonBlocking)
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		select {
-		case resp := <-client.outCh:
-			//log.Printf("[get] %s received %v", client.clientCtx.IFace().Self().AsString(), resp)
-			assert(resp.ApplyFunction(tla.MakeTLAString("msuccess")).AsBool())
-			typ := resp.ApplyFunction(tla.MakeTLAString("mtype"))
-			mresp := resp.ApplyFunction(tla.MakeTLAString("mresponse"))
-			respKey := mresp.ApplyFunction(tla.MakeTLAString("key")).AsString()
-			assert(typ.Equal(raftkvs.ClientGetResponse(client.clientCtx.IFace())))
-			assert(respKey == keyStr)
-
-			if !mresp.ApplyFunction(tla.MakeTLAString("ok")).AsBool() {
-				return nil, fmt.Errorf("key not found: %s", keyStr)
-			}
+	resp, err := client.awaitResponse(ctx, cfg.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	//log.Printf("[get] %s received %v", client.clientCtx.IFace().Self().AsString(), resp)
+	assert(resp.ApplyFunction(tla.MakeTLAString("msuccess")).AsBool())
+	typ := resp.ApplyFunction(tla.MakeTLAString("mtype"))
+	mresp := resp.ApplyFunction(tla.MakeTLAString("mresponse"))
+	respKey := mresp.ApplyFunction(tla.MakeTLAString("key")).AsString()
+	assert(typ.Equal(raftkvs.ClientGetResponse(client.clientCtx.IFace())))
+	assert(respKey == keyStr)
+
+	if !mresp.ApplyFunction(tla.MakeTLAString("ok")).AsBool() {
+		return nil, fmt.Errorf("key %w: %s", ycsb.ErrNotFound, keyStr)
+	}
 
-			if cfg.useInts {
-				// short-circuit attempting to parse the result, it's a random int
-				return make(map[string][]byte), nil
-			}
-			result := make(map[string][]byte)
-			it := mresp.ApplyFunction(tla.MakeTLAString("value")).AsFunction().Iterator()
-			for !it.Done() {
-				k, v := it.Next()
-				kStr := k.(tla.TLAValue).AsString()
-				if fieldFilter == nil || fieldFilter[kStr] {
-					result[kStr] = []byte(v.(tla.TLAValue).AsString())
-				}
-			}
-			return result, nil
-		case <-time.After(cfg.requestTimeout):
-			// clear timeout channel
-			select {
-			case <-client.timeoutCh:
-			default:
-			}
-			client.timeoutCh <- tla.TLA_TRUE
+	value := mresp.ApplyFunction(tla.MakeTLAString("value"))
+	if err := cfg.validatePayload(value); err != nil {
+		return nil, fmt.Errorf("key %s: %v", keyStr, err)
+	}
+	if cfg.payloadMode != payloadFull {
+		// short-circuit attempting to parse the result, it's not a record, and only tells
+		// whether the record was deleted
+		if value.AsString() == "" {
+			return nil, fmt.Errorf("key %w: %s", ycsb.ErrNotFound, keyStr)
+		}
+		return make(map[string][]byte), nil
+	}
+	result := make(map[string][]byte)
+	it := value.AsFunction().Iterator()
+	for !it.Done() {
+		k, v := it.Next()
+		kStr := k.(tla.TLAValue).AsString()
+		if fieldFilter == nil || fieldFilter[kStr] {
+			result[kStr] = []byte(v.(tla.TLAValue).AsString())
 		}
 	}
+	return result, nil
+}
+
+// Valueless implements the ValuelessDB interface: the payload modes other than the
+// full one don't store the values.
+func (cfg *raftClient) Valueless() bool {
+	return cfg.payloadMode != payloadFull
 }
 
 func (cfg *raftClient) Scan(_ context.Context, _ string, _ string, _ int, _ []string) ([]map[string][]byte, error) {
 	return nil, fmt.Errorf("pgo-raftkv does not implement key scan")
 }
 
+// Update reads the record and writes it back with the values, as raftkvs has no
+// atomic read-modify-write. The two halves are measured as UPDATE[part=read] and
+// UPDATE[part=write], which, like the other breakdowns, aren't counted as operations.
 func (cfg *raftClient) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	start := measurement.Now()
 	result, err := cfg.Read(ctx, table, key, nil)
 	if err != nil {
 		return err
 	}
+	measurement.Measure("UPDATE[part=read]", measurement.Now().Sub(start))
 	for k := range values {
 		result[k] = values[k]
 	}
-	return cfg.Insert(ctx, table, key, result)
+	start = measurement.Now()
+	if err := cfg.Insert(ctx, table, key, result); err != nil {
+		return err
+	}
+	measurement.Measure("UPDATE[part=write]", measurement.Now().Sub(start))
+	return nil
 }
 
 func (cfg *raftClient) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
-	client := ctx.Value(threadIdxTag{}).(*raftClientThread)
-	keyStr := table + "/" + key
+	worker := ctx.Value(threadIdxTag{}).(*raftWorker)
+	client := worker.acquire()
+	defer worker.release()
+	keyStr, nsStats := cfg.namespacedKey(table, key)
 
 	kvFn := func() tla.TLAValue {
-		if cfg.useInts {
+		switch cfg.payloadMode {
+		case payloadSize:
+			if len(values) == 0 {
+				return tla.MakeTLAString("")
+			}
 			valuesBytes, err := json.Marshal(&values)
 			if err != nil {
 				panic(err)
 			}
 			return tla.MakeTLAString(fmt.Sprintf("%d", len(valuesBytes)))
+		case payloadFixed:
+			if len(values) == 0 {
+				return tla.MakeTLAString("")
+			}
+			return tla.MakeTLAString(cfg.fixedPayload)
+		case payloadHash:
+			if len(values) == 0 {
+				return tla.MakeTLAString("")
+			}
+			// json.Marshal sorts the fields, so equal records hash the same
+			valuesBytes, err := json.Marshal(&values)
+			if err != nil {
+				panic(err)
+			}
+			h := fnv.New64a()
+			_, _ = h.Write(valuesBytes)
+			return tla.MakeTLAString(fmt.Sprintf("%016x", h.Sum64()))
+		case payloadOpaque:
+			if len(values) == 0 {
+				// deletes carry no payload
+				return tla.MakeTLAString("")
+			}
+			buf := make([]byte, cfg.payloadSize)
+			util.RandBytes(client.r, buf)
+			return tla.MakeTLAString(string(buf))
 		}
 		var kvPairs []tla.TLARecordField
 		for k := range values {
@@ -219,33 +504,28 @@ func (cfg *raftClient) Insert(ctx context.Context, table string, key string, val
 		}
 		return tla.MakeTLARecord(kvPairs)
 	}()
-	client.inCh <- tla.MakeTLARecord([]tla.TLARecordField{
+	err := client.sendRequest(ctx, tla.MakeTLARecord([]tla.TLARecordField{
 		{Key: tla.MakeTLAString("type"), Value: raftkvs.Put(client.clientCtx.IFace())},
 		{Key: tla.MakeTLAString("key"), Value: tla.MakeTLAString(keyStr)},
 		{Key: tla.MakeTLAString("value"), Value: kvFn},
-	})
+	}), nsStats, cfg.nonBlocking)
+	if err != nil {
+		return err
+	}
 
-	for {
-		select {
-		case resp := <-client.outCh:
-			//log.Printf("[put] %s received %v", client.clientCtx.IFace().Self().AsString(), resp)
-			assert(resp.ApplyFunction(tla.MakeTLAString("msuccess")).AsBool())
-			typ := resp.ApplyFunction(tla.MakeTLAString("mtype"))
-			mresp := resp.ApplyFunction(tla.MakeTLAString("mresponse"))
-			respKey := mresp.ApplyFunction(tla.MakeTLAString("key")).AsString()
-			assert(typ.Equal(raftkvs.ClientPutResponse(client.clientCtx.IFace())))
-			assert(respKey == keyStr)
-			assert(mresp.ApplyFunction(tla.MakeTLAString("value")).Equal(kvFn))
-			return nil
-		case <-time.After(cfg.requestTimeout):
-			// clear timeout channel
-			select {
-			case <-client.timeoutCh:
-			default:
-			}
-			client.timeoutCh <- tla.TLA_TRUE
-		}
+	resp, err := client.awaitResponse(ctx, cfg.requestTimeout)
+	if err != nil {
+		return err
 	}
+	//log.Printf("[put] %s received %v", client.clientCtx.IFace().Self().AsString(), resp)
+	assert(resp.ApplyFunction(tla.MakeTLAString("msuccess")).AsBool())
+	typ := resp.ApplyFunction(tla.MakeTLAString("mtype"))
+	mresp := resp.ApplyFunction(tla.MakeTLAString("mresponse"))
+	respKey := mresp.ApplyFunction(tla.MakeTLAString("key")).AsString()
+	assert(typ.Equal(raftkvs.ClientPutResponse(client.clientCtx.IFace())))
+	assert(respKey == keyStr)
+	assert(mresp.ApplyFunction(tla.MakeTLAString("value")).Equal(kvFn))
+	return nil
 }
 
 func (cfg *raftClient) Delete(ctx context.Context, table string, key string) error {
@@ -256,10 +536,48 @@ const (
 	pgoRaftKVEndpoints         = "pgo-raftkv.endpoints"
 	pgoRaftKVEndpointMonitors  = "pgo-raftkv.endpointmonitors"
 	pgoRaftKVClientReplyPoints = "pgo-raftkv.clientreplypoints"
+	pgoRaftKVShareReplyPoints  = "pgo-raftkv.sharereplypoints"
 	pgoRaftKVRequestTimeout    = "pgo-raftkv.requesttimeout"
+	pgoRaftKVPreflight         = "pgo-raftkv.preflight"
+	pgoRaftKVPreflightTimeout  = "pgo-raftkv.preflighttimeout"
+	pgoRaftKVConfigCheck       = "pgo-raftkv.configcheck"
+	pgoRaftKVPayloadMode       = "pgo-raftkv.payloadmode"
+	pgoRaftKVFixedPayload      = "pgo-raftkv.fixedpayload"
+	pgoRaftKVNamespaces        = "pgo-raftkv.namespaces"
+	pgoRaftKVInBuffer          = "pgo-raftkv.inbuffer"
+	pgoRaftKVOutBuffer         = "pgo-raftkv.outbuffer"
+	pgoRaftKVTimeoutBuffer     = "pgo-raftkv.timeoutbuffer"
+	pgoRaftKVNonBlocking       = "pgo-raftkv.nonblocking"
 	pgoRaftKVUseInts           = "ycsb.useints"
 )
 
+// preflightCheck dials every endpoint and monitor address once, so that a misconfigured
+// address fails the benchmark up front instead of showing up as endless request timeouts.
+func preflightCheck(endpoints []string, endpointMonitors map[string]string, timeout time.Duration) error {
+	var addrs []string
+	for _, endpoint := range endpoints {
+		monAddr, ok := endpointMonitors[endpoint]
+		if !ok {
+			return fmt.Errorf("endpoint %s has no monitor in %s", endpoint, pgoRaftKVEndpointMonitors)
+		}
+		addrs = append(addrs, endpoint, monAddr)
+	}
+
+	var unreachable []string
+	for _, addr := range addrs {
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%v)", addr, err))
+			continue
+		}
+		_ = conn.Close()
+	}
+	if len(unreachable) != 0 {
+		return fmt.Errorf("unreachable pgo-raftkv nodes:\n\t%s", strings.Join(unreachable, "\n\t"))
+	}
+	return nil
+}
+
 type raftCreator struct{}
 
 func (_ raftCreator) Create(props *properties.Properties) (ycsb.DB, error) {
@@ -286,12 +604,62 @@ func (_ raftCreator) Create(props *properties.Properties) (ycsb.DB, error) {
 		return nil, fmt.Errorf("must specify %s", pgoRaftKVClientReplyPoints)
 	}
 
+	endpointList := strings.Split(endpoints, ",")
+	if props.GetBool(pgoRaftKVPreflight, true) {
+		timeout := props.GetParsedDuration(pgoRaftKVPreflightTimeout, time.Second*2)
+		err := preflightCheck(endpointList, endPointMonitorMap, timeout)
+		if err == nil && props.GetBool(pgoRaftKVConfigCheck, true) {
+			err = configCheck(endpointList, endPointMonitorMap, timeout)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ycsb.useints is kept as a shorthand for payloadmode=size
+	defaultPayloadMode := "full"
+	if props.GetBool(pgoRaftKVUseInts, false) {
+		defaultPayloadMode = "size"
+	}
+	mode, err := parsePayloadMode(props.GetString(pgoRaftKVPayloadMode, defaultPayloadMode))
+	if err != nil {
+		return nil, err
+	}
+	payloadSize := props.GetInt64(prop.FieldCount, prop.FieldCountDefault) * props.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
+
+	namespaces, err := parseNamespaces(props.GetString(pgoRaftKVNamespaces, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	inBuffer := props.GetInt(pgoRaftKVInBuffer, 0)
+	outBuffer := props.GetInt(pgoRaftKVOutBuffer, 0)
+	timeoutBuffer := props.GetInt(pgoRaftKVTimeoutBuffer, 1)
+	if inBuffer < 0 || outBuffer < 0 {
+		return nil, fmt.Errorf("%s and %s can't be negative", pgoRaftKVInBuffer, pgoRaftKVOutBuffer)
+	}
+	if timeoutBuffer < 1 {
+		// a timeout is fired without waiting for the archetype to take it
+		return nil, fmt.Errorf("%s must be at least 1", pgoRaftKVTimeoutBuffer)
+	}
+
+	replyPoints := strings.Split(clientReplyPoints, ",")
 	return &raftClient{
-		endpoints:         strings.Split(endpoints, ","),
+		endpoints:         endpointList,
 		endpointMonitors:  endPointMonitorMap,
-		clientReplyPoints: strings.Split(clientReplyPoints, ","),
+		clientReplyPoints: replyPoints,
 		requestTimeout:    props.GetParsedDuration(pgoRaftKVRequestTimeout, time.Second*1),
-		useInts:           props.GetBool(pgoRaftKVUseInts, false),
+		payloadMode:       mode,
+		payloadSize:       int(payloadSize),
+		fixedPayload:      strconv.FormatInt(props.GetInt64(pgoRaftKVFixedPayload, 1), 10),
+		shareReplyPoints:  props.GetBool(pgoRaftKVShareReplyPoints, false),
+		clientThreads:     make([]*raftClientThread, len(replyPoints)),
+		fdEvents:          newFDEvents(endpointList),
+		namespaces:        namespaces,
+		inBuffer:          inBuffer,
+		outBuffer:         outBuffer,
+		timeoutBuffer:     timeoutBuffer,
+		nonBlocking:       props.GetBool(pgoRaftKVNonBlocking, false),
 	}, nil
 }
 
@@ -0,0 +1,57 @@
+package pgo_raftkv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UBC-NSS/pgo/distsys/tla"
+)
+
+func testResponse(idx int32) tla.TLAValue {
+	return tla.MakeTLARecord([]tla.TLARecordField{
+		{Key: tla.MakeTLAString("mresponse"), Value: tla.MakeTLARecord([]tla.TLARecordField{
+			{Key: tla.MakeTLAString("idx"), Value: tla.MakeTLANumber(idx)},
+		})},
+	})
+}
+
+// TestAwaitResponse checks that the response to an abandoned request, which the
+// archetype sends before it takes the next request, isn't taken for the response to
+// the next request.
+func TestAwaitResponse(t *testing.T) {
+	client := &raftClientThread{
+		inCh:      make(chan tla.TLAValue, 2),
+		outCh:     make(chan tla.TLAValue, 2),
+		timeoutCh: make(chan tla.TLAValue, 1),
+		stats:     &raftStats{},
+	}
+	client.nsStats.Store((*raftStats)(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.sendRequest(context.Background(), tla.TLA_TRUE, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.awaitResponse(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("awaiting a cancelled request returned %v", err)
+	}
+
+	if err := client.sendRequest(context.Background(), tla.TLA_TRUE, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	client.outCh <- testResponse(1)
+	client.outCh <- testResponse(2)
+	resp, err := client.awaitResponse(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx := resp.ApplyFunction(tla.MakeTLAString("mresponse")).ApplyFunction(tla.MakeTLAString("idx")); !idx.Equal(tla.MakeTLANumber(2)) {
+		t.Errorf("received the response to request %v, expecting request 2", idx)
+	}
+	stats := client.stats.toMap()
+	if stats["abandoned"] != 1 || stats["abandoned_responses"] != 1 {
+		t.Errorf("%d requests abandoned and %d responses discarded, expecting 1", stats["abandoned"], stats["abandoned_responses"])
+	}
+}
@@ -0,0 +1,99 @@
+package pgo_raftkv
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/UBC-NSS/pgo/distsys"
+	"github.com/UBC-NSS/pgo/distsys/resources"
+	"github.com/UBC-NSS/pgo/distsys/tla"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// fdEvents records the changes of the suspicion state of every server, as the failure
+// detectors of all client threads report it.
+type fdEvents struct {
+	lock      sync.Mutex
+	endpoints []string
+	suspected map[int]bool
+	events    []ycsb.Event
+}
+
+func newFDEvents(endpoints []string) *fdEvents {
+	return &fdEvents{
+		endpoints: endpoints,
+		suspected: make(map[int]bool),
+	}
+}
+
+func suspicionState(suspected bool) string {
+	if suspected {
+		return "suspected"
+	}
+	return "alive"
+}
+
+// observe records an event if the server is suspected and wasn't, or the other way round.
+// Servers are assumed to be alive until suspected.
+func (e *fdEvents) observe(server int, suspected bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.suspected[server] == suspected {
+		return
+	}
+	e.suspected[server] = suspected
+	e.events = append(e.events, ycsb.Event{
+		Time: time.Now(),
+		Message: fmt.Sprintf("failure detector: server %d (%s) %s -> %s", server, e.endpoints[server-1],
+			suspicionState(!suspected), suspicionState(suspected)),
+	})
+}
+
+func (e *fdEvents) take() []ycsb.Event {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	events := e.events
+	e.events = nil
+	return events
+}
+
+// watchedFailureDetector wraps the failure detector of a server, and reports what the
+// archetype reads from it to fdEvents.
+type watchedFailureDetector struct {
+	distsys.ArchetypeResource
+	server int
+	events *fdEvents
+}
+
+var _ distsys.ArchetypeResource = &watchedFailureDetector{}
+
+func (res *watchedFailureDetector) ReadValue() (tla.TLAValue, error) {
+	value, err := res.ArchetypeResource.ReadValue()
+	if err == nil {
+		res.events.observe(res.server, value.AsBool())
+	}
+	return value, err
+}
+
+// watchedFailureDetectorMaker returns a failure detector resource which behaves like fds,
+// except that the failure detector of every server is wrapped in a watchedFailureDetector.
+func watchedFailureDetectorMaker(fds distsys.ArchetypeResource, events *fdEvents) distsys.ArchetypeResourceMaker {
+	return resources.IncrementalMapMaker(func(index tla.TLAValue) distsys.ArchetypeResourceMaker {
+		return distsys.ArchetypeResourceMakerFn(func() distsys.ArchetypeResource {
+			fd, err := fds.Index(index)
+			if err != nil {
+				panic(fmt.Errorf("wrong index for watched failure detectors: %w", err))
+			}
+			return &watchedFailureDetector{
+				ArchetypeResource: fd,
+				server:            int(index.AsNumber()),
+				events:            events,
+			}
+		})
+	})
+}
+
+func (cfg *raftClient) Events() []ycsb.Event {
+	return cfg.fdEvents.take()
+}
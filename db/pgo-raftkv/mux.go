@@ -0,0 +1,66 @@
+package pgo_raftkv
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// raftWorker is a worker thread of the benchmark. Several workers share a client
+// archetype when there are fewer reply points than threads, and take turns sending
+// their requests through it.
+type raftWorker struct {
+	client *raftClientThread
+	// requests and wait, in nanoseconds, account for the time the worker queued for
+	// the archetype, which is part of the measured latency of its operations
+	requests int64
+	wait     int64
+}
+
+// acquire waits for the turn of the worker on its archetype. Workers blocked on the
+// turn channel are woken in the order they arrived, so the archetype is shared fairly.
+func (w *raftWorker) acquire() *raftClientThread {
+	start := time.Now()
+	w.client.turn <- struct{}{}
+	atomic.AddInt64(&w.wait, int64(time.Since(start)))
+	atomic.AddInt64(&w.requests, 1)
+	return w.client
+}
+
+func (w *raftWorker) release() {
+	<-w.client.turn
+}
+
+// muxStats summarizes how evenly the workers sharing archetypes were served: the
+// fewest and the most requests a worker sent, and the lowest and highest average
+// time a worker waited for its turn.
+func (cfg *raftClient) muxStats() map[string]int64 {
+	cfg.lock.Lock()
+	defer cfg.lock.Unlock()
+
+	stats := map[string]int64{
+		"mux_workers":         int64(len(cfg.workers)),
+		"mux_requests_min":    math.MaxInt64,
+		"mux_wait_avg_us_min": math.MaxInt64,
+	}
+	for _, w := range cfg.workers {
+		requests := atomic.LoadInt64(&w.requests)
+		avgWait := int64(0)
+		if requests > 0 {
+			avgWait = atomic.LoadInt64(&w.wait) / requests / int64(time.Microsecond)
+		}
+		if requests < stats["mux_requests_min"] {
+			stats["mux_requests_min"] = requests
+		}
+		if requests > stats["mux_requests_max"] {
+			stats["mux_requests_max"] = requests
+		}
+		if avgWait < stats["mux_wait_avg_us_min"] {
+			stats["mux_wait_avg_us_min"] = avgWait
+		}
+		if avgWait > stats["mux_wait_avg_us_max"] {
+			stats["mux_wait_avg_us_max"] = avgWait
+		}
+	}
+	return stats
+}
@@ -0,0 +1,49 @@
+package pgo_raftkv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namespace is the key namespace a table is mapped to, with the statistics of the
+// requests on it.
+type namespace struct {
+	name  string
+	stats raftStats
+}
+
+// parseNamespaces parses the table:namespace pairs of pgo-raftkv.namespaces into the
+// namespaces of the tables. Tables mapped to the same namespace share it.
+func parseNamespaces(s string) (map[string]*namespace, error) {
+	namespaces := make(map[string]*namespace)
+	if s == "" {
+		return namespaces, nil
+	}
+	byName := make(map[string]*namespace)
+	for _, pairStr := range strings.Split(s, ",") {
+		pair := strings.Split(pairStr, ":")
+		if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return nil, fmt.Errorf("could not parse mapping %s in %s; expecting table:namespace", pairStr, pgoRaftKVNamespaces)
+		}
+		if _, ok := namespaces[pair[0]]; ok {
+			return nil, fmt.Errorf("table %s is mapped twice in %s", pair[0], pgoRaftKVNamespaces)
+		}
+		ns, ok := byName[pair[1]]
+		if !ok {
+			ns = &namespace{name: pair[1]}
+			byName[pair[1]] = ns
+		}
+		namespaces[pair[0]] = ns
+	}
+	return namespaces, nil
+}
+
+// namespacedKey returns the raftkvs key of the record, prefixed with the namespace of
+// its table, and the statistics of the namespace. Tables which aren't mapped to a
+// namespace prefix their keys with the table name, and have no statistics of their own.
+func (cfg *raftClient) namespacedKey(table string, key string) (string, *raftStats) {
+	if ns, ok := cfg.namespaces[table]; ok {
+		return ns.name + "/" + key, &ns.stats
+	}
+	return table + "/" + key, nil
+}
@@ -0,0 +1,144 @@
+package pgo_raftkv
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/UBC-NSS/pgo/distsys"
+	"github.com/UBC-NSS/pgo/distsys/resources"
+	"github.com/UBC-NSS/pgo/distsys/tla"
+)
+
+// maxAttemptsBucket is the last bucket of the attempts per request histogram, which
+// also counts all requests that took more attempts.
+const maxAttemptsBucket = 5
+
+// raftStats counts the retries the client archetype performs behind a single request.
+// All fields are updated atomically, as they are shared by every client thread.
+type raftStats struct {
+	requests           int64
+	timeoutFires       int64
+	duplicateResponses int64
+	notLeaderResponses int64
+	// queueFull counts the non-blocking requests the archetype wasn't ready to take
+	queueFull int64
+	// abandoned counts the requests given up on once their context was done, and
+	// abandonedResponses the responses to them, which are discarded
+	abandoned          int64
+	abandonedResponses int64
+	maxAttempts        int64
+	attempts           [maxAttemptsBucket]int64
+}
+
+func (s *raftStats) recordAttempts(attempts int64) {
+	bucket := attempts
+	if bucket > maxAttemptsBucket {
+		bucket = maxAttemptsBucket
+	}
+	atomic.AddInt64(&s.attempts[bucket-1], 1)
+	for {
+		max := atomic.LoadInt64(&s.maxAttempts)
+		if attempts <= max || atomic.CompareAndSwapInt64(&s.maxAttempts, max, attempts) {
+			return
+		}
+	}
+}
+
+func (s *raftStats) toMap() map[string]int64 {
+	stats := map[string]int64{
+		"requests":             atomic.LoadInt64(&s.requests),
+		"timeout_fires":        atomic.LoadInt64(&s.timeoutFires),
+		"duplicate_responses":  atomic.LoadInt64(&s.duplicateResponses),
+		"not_leader_responses": atomic.LoadInt64(&s.notLeaderResponses),
+		"queue_full":           atomic.LoadInt64(&s.queueFull),
+		"abandoned":            atomic.LoadInt64(&s.abandoned),
+		"abandoned_responses":  atomic.LoadInt64(&s.abandonedResponses),
+		"attempts_max":         atomic.LoadInt64(&s.maxAttempts),
+	}
+	for i := range s.attempts {
+		name := fmt.Sprintf("attempts_%d", i+1)
+		if i+1 == maxAttemptsBucket {
+			name += "+"
+		}
+		stats[name] = atomic.LoadInt64(&s.attempts[i])
+	}
+	return stats
+}
+
+// countingMailbox wraps the client's local mailbox, and classifies the responses the
+// archetype consumes from it. Only committed reads are counted, since aborted reads
+// are put back into the mailbox and will be read again.
+type countingMailbox struct {
+	distsys.ArchetypeResource
+	client  *raftClientThread
+	pending []tla.TLAValue
+}
+
+var _ distsys.ArchetypeResource = &countingMailbox{}
+
+func (res *countingMailbox) ReadValue() (tla.TLAValue, error) {
+	value, err := res.ArchetypeResource.ReadValue()
+	if err == nil {
+		res.pending = append(res.pending, value)
+	}
+	return value, err
+}
+
+func (res *countingMailbox) Abort() chan struct{} {
+	res.pending = nil
+	return res.ArchetypeResource.Abort()
+}
+
+func (res *countingMailbox) Commit() chan struct{} {
+	reqIdx := tla.MakeTLANumber(int32(atomic.LoadInt64(&res.client.reqIdx)))
+	for _, resp := range res.pending {
+		if !resp.ApplyFunction(tla.MakeTLAString("msuccess")).AsBool() {
+			res.client.recordStats(func(stats *raftStats) {
+				atomic.AddInt64(&stats.notLeaderResponses, 1)
+			})
+		} else if !resp.ApplyFunction(tla.MakeTLAString("mresponse")).ApplyFunction(tla.MakeTLAString("idx")).Equal(reqIdx) {
+			// the archetype drops responses to requests it already gave up on, which are
+			// counted in the namespace of the current request
+			res.client.recordStats(func(stats *raftStats) {
+				atomic.AddInt64(&stats.duplicateResponses, 1)
+			})
+		}
+	}
+	res.pending = nil
+	return res.ArchetypeResource.Commit()
+}
+
+// countingMailboxesMaker returns a mailboxes resource which behaves like mailboxes, except
+// that the mailbox at self is wrapped in a countingMailbox counting into the client's stats.
+func countingMailboxesMaker(mailboxes distsys.ArchetypeResource, self tla.TLAValue, client *raftClientThread) distsys.ArchetypeResourceMaker {
+	return resources.IncrementalMapMaker(func(index tla.TLAValue) distsys.ArchetypeResourceMaker {
+		return distsys.ArchetypeResourceMakerFn(func() distsys.ArchetypeResource {
+			mailbox, err := mailboxes.Index(index)
+			if err != nil {
+				panic(fmt.Errorf("wrong index for counting mailboxes: %w", err))
+			}
+			if !index.Equal(self) {
+				return mailbox
+			}
+			return &countingMailbox{
+				ArchetypeResource: mailbox,
+				client:            client,
+			}
+		})
+	})
+}
+
+func (cfg *raftClient) ExtendedStats() map[string]int64 {
+	stats := cfg.stats.toMap()
+	for _, ns := range cfg.namespaces {
+		for name, value := range ns.stats.toMap() {
+			stats[ns.name+"."+name] = value
+		}
+	}
+	if cfg.shareReplyPoints {
+		for name, value := range cfg.muxStats() {
+			stats[name] = value
+		}
+	}
+	return stats
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	goredis "github.com/go-redis/redis/v8"
 	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
+	"net"
+	"time"
 )
 
 type redis struct {
@@ -30,6 +33,9 @@ func (r *redis) InitThread(ctx context.Context, _ int, _ int) context.Context {
 func (r *redis) CleanupThread(_ context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (r *redis) ConcurrentThreads() {}
+
 func (r *redis) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	data := make(map[string][]byte, len(fields))
 
@@ -63,6 +69,12 @@ func (r *redis) Scan(_ context.Context, _ string, _ string, _ int, _ []string) (
 }
 
 func (r *redis) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return r.UpdateWithTTL(ctx, table, key, values, 0)
+}
+
+// UpdateWithTTL implements the TTLDB UpdateWithTTL interface. A zero ttl means the
+// record doesn't expire.
+func (r *redis) UpdateWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
 	d, err := r.Read(ctx, table, key, nil)
 	if err != nil {
 		return err
@@ -72,17 +84,23 @@ func (r *redis) Update(ctx context.Context, table string, key string, values map
 		d[k] = values[k]
 	}
 
-	return r.Insert(ctx, table, key, d)
+	return r.InsertWithTTL(ctx, table, key, d, ttl)
 }
 
 func (r *redis) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return r.InsertWithTTL(ctx, table, key, values, 0)
+}
+
+// InsertWithTTL implements the TTLDB InsertWithTTL interface. A zero ttl means the
+// record doesn't expire.
+func (r *redis) InsertWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error {
 	data, err := json.Marshal(values)
 	if err != nil {
 		return err
 	}
 
 	pp := r.client.Pipeline()
-	_ = pp.Set(ctx, table+"/"+key, string(data), 0)
+	_ = pp.Set(ctx, table+"/"+key, string(data), ttl)
 	_ = pp.Do(ctx, "WAIT", r.numReplicas, 0)
 	_, err = pp.Exec(ctx)
 	return err
@@ -96,6 +114,24 @@ func (r *redis) Delete(ctx context.Context, table string, key string) error {
 	return err
 }
 
+// Push implements the QueueDB Push interface with a list.
+func (r *redis) Push(ctx context.Context, table string, queue string, value []byte) error {
+	pp := r.client.Pipeline()
+	_ = pp.RPush(ctx, table+"/"+queue, value)
+	_ = pp.Do(ctx, "WAIT", r.numReplicas, 0)
+	_, err := pp.Exec(ctx)
+	return err
+}
+
+// Pop implements the QueueDB Pop interface.
+func (r *redis) Pop(ctx context.Context, table string, queue string) ([]byte, error) {
+	value, err := r.client.LPop(ctx, table+"/"+queue).Bytes()
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
 type redisCreator struct{}
 
 func (r redisCreator) Create(p *properties.Properties) (ycsb.DB, error) {
@@ -108,8 +144,11 @@ func (r redisCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 		return nil, fmt.Errorf("%s must be specified", redisNumReplicas)
 	}
 
+	// the dialer of go-redis, counting the connections of the pool
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 5 * time.Minute}
 	client := goredis.NewClient(&goredis.Options{
-		Addr: addr,
+		Addr:   addr,
+		Dialer: util.CountingDial(dialer.DialContext),
 	})
 	return &redis{
 		client:      client,
@@ -179,6 +179,9 @@ func (db *rocksDB) InitThread(ctx context.Context, _ int, _ int) context.Context
 func (db *rocksDB) CleanupThread(_ context.Context) {
 }
 
+// ConcurrentThreads implements the ConcurrentDB interface.
+func (db *rocksDB) ConcurrentThreads() {}
+
 func (db *rocksDB) getRowKey(table string, key string) []byte {
 	return util.Slice(fmt.Sprintf("%s:%s", table, key))
 }
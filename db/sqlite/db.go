@@ -82,16 +82,16 @@ func (c sqliteCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 
 	d.bufPool = util.NewBufPool()
 
-	if err := d.createTable(); err != nil {
-		return nil, err
+	for _, tableName := range util.TableNames(p) {
+		if err := d.createTable(tableName); err != nil {
+			return nil, err
+		}
 	}
 
 	return d, nil
 }
 
-func (db *sqliteDB) createTable() error {
-	tableName := db.p.GetString(prop.TableName, prop.TableNameDefault)
-
+func (db *sqliteDB) createTable(tableName string) error {
 	fieldCount := db.p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
 	fieldLength := db.p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
 
@@ -113,6 +113,18 @@ func (db *sqliteDB) createTable() error {
 	return err
 }
 
+// StorageSize returns the size of the database pages.
+func (db *sqliteDB) StorageSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+	if err := db.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, err
+	}
+	if err := db.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pageCount * pageSize, nil
+}
+
 func (db *sqliteDB) ToSqlDB() *sql.DB {
 	return nil
 }
@@ -203,6 +215,28 @@ func (db *sqliteDB) Scan(ctx context.Context, table string, startKey string, cou
 	return rows, err
 }
 
+// CreateIndex implements the QueryDB CreateIndex interface.
+func (db *sqliteDB) CreateIndex(ctx context.Context, table string, field string) error {
+	query := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s (%s)", table, field, table, field)
+	if db.verbose {
+		fmt.Println(query)
+	}
+	_, err := db.db.ExecContext(ctx, query)
+	return err
+}
+
+// Query implements the QueryDB Query interface.
+func (db *sqliteDB) Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) ([]map[string][]byte, error) {
+	var query string
+	if len(fields) == 0 {
+		query = fmt.Sprintf(`SELECT * FROM %s WHERE %s = ? LIMIT ?`, table, field)
+	} else {
+		query = fmt.Sprintf(`SELECT %s FROM %s WHERE %s = ? LIMIT ?`, strings.Join(fields, ","), table, field)
+	}
+
+	return db.queryRows(ctx, query, count, value, count)
+}
+
 func (db *sqliteDB) execQuery(ctx context.Context, query string, args ...interface{}) error {
 	if db.verbose {
 		fmt.Printf("%s %v\n", query, args)
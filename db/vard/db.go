@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	"io"
 	"log"
@@ -22,16 +24,18 @@ import (
 type vardClientTag struct{}
 
 type vardClient struct {
-	clientId    uuid.UUID
-	endpointIdx int
-	conn        net.Conn
-	requestId   int
-	buffer      bytes.Buffer
+	clientId  uuid.UUID
+	threadID  int
+	conns     []net.Conn
+	connected []bool
+	requestId int
+	buffer    bytes.Buffer
 }
 
 type vardConfig struct {
 	dialTimeout time.Duration
 	endpoints   []string
+	balancer    *util.Balancer
 }
 
 var responseRx = regexp.MustCompile("Response\\W+([0-9]+)\\W+([/A-Za-z0-9]+|-)\\W+([/A-Za-z0-9]+|-)\\W+([/A-Za-z0-9]+|-)")
@@ -46,95 +50,111 @@ func (conf *vardConfig) Close() error {
 	return nil
 }
 
-func (conf *vardConfig) InitThread(ctx context.Context, _ int, _ int) context.Context {
+func (conf *vardConfig) InitThread(ctx context.Context, threadID int, _ int) context.Context {
 	client := &vardClient{
-		clientId: uuid.New(),
+		clientId:  uuid.New(),
+		threadID:  threadID,
+		conns:     make([]net.Conn, len(conf.endpoints)),
+		connected: make([]bool, len(conf.endpoints)),
 	}
 	return context.WithValue(ctx, vardClientTag{}, client)
 }
 
-func (conf *vardConfig) setupConn(ctx context.Context) {
-	client := ctx.Value(vardClientTag{}).(*vardClient)
-	if client.conn == nil {
-		var err error
-		for {
-			if err != nil {
-				log.Printf("client %v error establishing connection: %v", client.clientId, err)
-				client.conn = nil
-				err = nil
-			}
-			endpoint := conf.endpoints[client.endpointIdx]
-			client.endpointIdx = (client.endpointIdx + 1) % len(conf.endpoints)
-
-			client.conn, err = net.DialTimeout("tcp", endpoint, conf.dialTimeout)
-			if err != nil {
-				continue
-			}
-			// send client ID (has to be 32 chars of hex)
-			clientIdStr := strings.Replace(client.clientId.String(), "-", "", 4)
-			err = binary.Write(client.conn, binary.LittleEndian, int32(len(clientIdStr)))
-			if err != nil {
-				continue
-			}
-			_, err = client.conn.Write([]byte(clientIdStr))
-			if err != nil {
-				continue
-			}
-			return
-		}
+// setupConn returns the client's connection to the endpoint, establishing it if needed.
+func (conf *vardConfig) setupConn(client *vardClient, endpointIdx int) (net.Conn, error) {
+	if client.conns[endpointIdx] != nil {
+		return client.conns[endpointIdx], nil
+	}
+	conn, err := net.DialTimeout("tcp", conf.endpoints[endpointIdx], conf.dialTimeout)
+	if err != nil {
+		util.RecordDialFailure()
+		return nil, err
+	}
+	// send client ID (has to be 32 chars of hex)
+	clientIdStr := strings.Replace(client.clientId.String(), "-", "", 4)
+	err = binary.Write(conn, binary.LittleEndian, int32(len(clientIdStr)))
+	if err == nil {
+		_, err = conn.Write([]byte(clientIdStr))
+	}
+	if err != nil {
+		util.RecordDialFailure()
+		_ = conn.Close()
+		return nil, err
 	}
+	util.RecordConnEstablished(client.connected[endpointIdx])
+	client.connected[endpointIdx] = true
+	client.conns[endpointIdx] = conn
+	return conn, nil
 }
 
 func (conf *vardConfig) procMsg(ctx context.Context, cmd string, arg1, arg2, arg3 string) ([]string, error) {
 	client := ctx.Value(vardClientTag{}).(*vardClient)
-	var err error
 	for {
-		conf.setupConn(ctx)
+		endpointIdx := conf.balancer.Pick(ctx, client.threadID)
+		_, pinned := util.PinnedEndpoint(ctx)
+		start := time.Now()
+		conn, err := conf.setupConn(client, endpointIdx)
+		if err != nil {
+			log.Printf("client %v error establishing connection: %v", client.clientId, err)
+			conf.balancer.Done(client.threadID, endpointIdx, time.Since(start), err)
+			if pinned {
+				// retrying on another endpoint isn't an option
+				return nil, err
+			}
+			continue
+		}
 		results := func() []string {
+			// broken is set when the connection itself failed, rather than the request
+			broken := false
 			defer func() {
 				if err != nil {
 					log.Printf("client %v error handling msg %s: %v", client.clientId, cmd, err)
-					err = client.conn.Close()
-					if err != nil {
-						log.Printf("client %v error closing connection: %v", client.clientId, err)
-						err = nil
+					if broken {
+						util.RecordKeepAliveFailure()
+					}
+					if closeErr := conn.Close(); closeErr != nil {
+						log.Printf("client %v error closing connection: %v", client.clientId, closeErr)
 					}
-					client.conn = nil
+					client.conns[endpointIdx] = nil
 				}
 			}()
 
 			msg := fmt.Sprintf("%d %s %s %s %s", client.requestId, cmd, arg1, arg2, arg3)
-			err = binary.Write(client.conn, binary.LittleEndian, int32(len(msg)))
+			err = binary.Write(conn, binary.LittleEndian, int32(len(msg)))
 			if err != nil {
+				broken = true
 				return nil
 			}
-			_, err = client.conn.Write([]byte(msg))
+			_, err = conn.Write([]byte(msg))
 			if err != nil {
+				broken = true
 				return nil
 			}
 			client.requestId += 1
 
 			// wait for response...
 			var responseLen int32
-			err = binary.Read(client.conn, binary.LittleEndian, &responseLen)
+			err = binary.Read(conn, binary.LittleEndian, &responseLen)
 			if err != nil {
+				broken = true
 				return nil
 			}
 			client.buffer.Reset()
 			client.buffer.Grow(int(responseLen))
-			_, err = io.CopyN(&client.buffer, client.conn, int64(responseLen))
+			_, err = io.CopyN(&client.buffer, conn, int64(responseLen))
 			if err != nil {
+				broken = true
 				return nil
 			}
 
 			responseStr := client.buffer.String()
 			if strings.HasPrefix(responseStr, "NotLeader") {
-				err = fmt.Errorf("%s was not leader", client.conn.RemoteAddr().String())
+				err = fmt.Errorf("%s was not leader", conn.RemoteAddr().String())
 				return nil
 			}
 			matches := responseRx.FindStringSubmatchIndex(responseStr)
 			if matches == nil {
-				err = fmt.Errorf("could not parse response `%s` from %s", responseStr, client.conn.RemoteAddr().String())
+				err = fmt.Errorf("could not parse response `%s` from %s", responseStr, conn.RemoteAddr().String())
 				return nil
 			}
 
@@ -144,23 +164,38 @@ func (conf *vardConfig) procMsg(ctx context.Context, cmd string, arg1, arg2, arg
 			}
 			return results[1:] // element 0 is the entire string!
 		}()
+		conf.balancer.Done(client.threadID, endpointIdx, time.Since(start), err)
 		if results != nil {
 			return results, nil
 		}
+		if pinned {
+			return nil, err
+		}
 	}
 
 }
 
 func (conf *vardConfig) CleanupThread(ctx context.Context) {
 	client := ctx.Value(vardClientTag{}).(*vardClient)
-	if client.conn != nil {
-		err := client.conn.Close()
+	for _, conn := range client.conns {
+		if conn == nil {
+			continue
+		}
+		err := conn.Close()
 		if err != nil {
-			log.Printf("error closing client %s connection to %s: %v", client.clientId, client.conn.RemoteAddr().String(), err)
+			log.Printf("error closing client %s connection to %s: %v", client.clientId, conn.RemoteAddr().String(), err)
 		}
 	}
 }
 
+func (conf *vardConfig) Endpoints() []string {
+	return conf.endpoints
+}
+
+func (conf *vardConfig) ExtendedStats() map[string]int64 {
+	return conf.balancer.Stats()
+}
+
 func (conf *vardConfig) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	_, err := conf.procMsg(ctx, "GET", table+"/"+key, "-", "-")
 	if err != nil {
@@ -238,9 +273,16 @@ func (_ vardCreator) Create(props *properties.Properties) (ycsb.DB, error) {
 	}
 	endpoints := strings.Split(endpointsStr, ",")
 
+	// vard only accepts requests at the leader, so stay on an endpoint until it fails by default
+	balancer, err := util.NewBalancer(props.GetString(prop.LBPolicy, util.LBSticky), endpoints)
+	if err != nil {
+		return nil, err
+	}
+
 	return &vardConfig{
 		endpoints:   endpoints,
 		dialTimeout: props.GetParsedDuration(vardDialTimeout, time.Second*5),
+		balancer:    balancer,
 	}, nil
 }
 
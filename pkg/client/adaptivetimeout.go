@@ -0,0 +1,141 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// latencyWindowSize is the number of recent latencies a latency percentile is
+// estimated from.
+const latencyWindowSize = 1000
+
+// latencyWindow estimates a percentile of the recent latencies, again every tenth
+// of the window.
+type latencyWindow struct {
+	percentile float64
+	latencies  []time.Duration
+	next       int
+	observed   int
+}
+
+func newLatencyWindow(percentile float64) *latencyWindow {
+	return &latencyWindow{percentile: percentile, latencies: make([]time.Duration, latencyWindowSize)}
+}
+
+// observe records a latency, and returns the new estimate of the percentile and true
+// when it is estimated again.
+func (w *latencyWindow) observe(latency time.Duration) (time.Duration, bool) {
+	w.latencies[w.next] = latency
+	w.next = (w.next + 1) % len(w.latencies)
+	w.observed++
+	if w.observed%(latencyWindowSize/10) != 0 {
+		return 0, false
+	}
+
+	n := w.observed
+	if n > len(w.latencies) {
+		n = len(w.latencies)
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.latencies[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(float64(n-1)*w.percentile/100)], true
+}
+
+// adaptive is the adaptive deadline of the DB calls, nil if disabled.
+var adaptive *adaptiveTimeout
+
+// adaptiveTimeout derives the deadline of the calls of every operation from a
+// percentile of the latencies of its recent successful calls, so that the calls
+// stuck far behind the others are given up on without tuning operation.timeout.
+type adaptiveTimeout struct {
+	percentile float64
+	multiplier float64
+	min        time.Duration
+
+	sync.Mutex
+	windows  map[string]*latencyWindow
+	timeouts map[string]time.Duration
+}
+
+// newAdaptiveTimeout returns the adaptive deadline of the DB calls, nil if disabled.
+func newAdaptiveTimeout(p *properties.Properties) (*adaptiveTimeout, error) {
+	percentile := p.GetFloat64(prop.AdaptiveTimeoutPercentile, 0)
+	if percentile <= 0 {
+		return nil, nil
+	}
+	if percentile > 100 {
+		return nil, fmt.Errorf("%s must be at most 100", prop.AdaptiveTimeoutPercentile)
+	}
+	a := &adaptiveTimeout{
+		percentile: percentile,
+		multiplier: p.GetFloat64(prop.AdaptiveTimeoutMultiplier, prop.AdaptiveTimeoutMultiplierDefault),
+		windows:    make(map[string]*latencyWindow),
+		timeouts:   make(map[string]time.Duration),
+	}
+	if a.multiplier < 1 {
+		return nil, fmt.Errorf("%s must be at least 1", prop.AdaptiveTimeoutMultiplier)
+	}
+	var err error
+	if a.min, err = time.ParseDuration(p.GetString(prop.AdaptiveTimeoutMin, prop.AdaptiveTimeoutMinDefault)); err != nil || a.min < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.AdaptiveTimeoutMin)
+	}
+	return a, nil
+}
+
+// timeout returns the deadline of the next call of the operation, 0 until enough of
+// its calls succeeded to estimate it.
+func (a *adaptiveTimeout) timeout(op string) time.Duration {
+	a.Lock()
+	defer a.Unlock()
+	return a.timeouts[op]
+}
+
+// observe records the latency of a successful call of the operation.
+func (a *adaptiveTimeout) observe(op string, latency time.Duration) {
+	a.Lock()
+	defer a.Unlock()
+	w, ok := a.windows[op]
+	if !ok {
+		w = newLatencyWindow(a.percentile)
+		a.windows[op] = w
+	}
+	if value, ok := w.observe(latency); ok {
+		timeout := time.Duration(float64(value) * a.multiplier)
+		if timeout < a.min {
+			timeout = a.min
+		}
+		a.timeouts[op] = timeout
+	}
+}
+
+func (a *adaptiveTimeout) output() {
+	a.Lock()
+	defer a.Unlock()
+	ops := make([]string, 0, len(a.timeouts))
+	for op := range a.timeouts {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		fmt.Printf("Adaptive timeout - Operation: %s, Timeout(us): %d\n", op, a.timeouts[op]/time.Microsecond)
+	}
+}
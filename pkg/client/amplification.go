@@ -0,0 +1,62 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// storageProbe samples the physical storage size of the DB around a run.
+type storageProbe struct {
+	db     ycsb.StorageSizeDB
+	before int64
+}
+
+// newStorageProbe records the storage size before the run, it returns nil
+// if the DB can't report its size.
+func newStorageProbe(ctx context.Context, db ycsb.DB) *storageProbe {
+	sizeDB, ok := db.(ycsb.StorageSizeDB)
+	if !ok {
+		return nil
+	}
+	before, err := sizeDB.StorageSize(ctx)
+	if err != nil {
+		if err != errNotSupported {
+			fmt.Printf("Get storage size failed: %v\n", err)
+		}
+		return nil
+	}
+	atomic.StoreInt64(&writtenBytes, 0)
+	return &storageProbe{db: sizeDB, before: before}
+}
+
+// output prints the storage size growth against the logical bytes written.
+func (s *storageProbe) output(ctx context.Context) {
+	after, err := s.db.StorageSize(ctx)
+	if err != nil {
+		fmt.Printf("Get storage size failed: %v\n", err)
+		return
+	}
+
+	written := atomic.LoadInt64(&writtenBytes)
+	fmt.Printf("Storage size(bytes) before: %d, after: %d, logical bytes written: %d", s.before, after, written)
+	if written > 0 {
+		fmt.Printf(", write amplification: %.2f", float64(after-s.before)/float64(written))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// The failed operations, by whether the client gave up on them or the DB failed them.
+// adaptiveDeadlineErrors counts the client deadline expirations of the adaptive deadline.
+var (
+	clientDeadlineErrors   int64
+	adaptiveDeadlineErrors int64
+	serverErrors           int64
+)
+
+// attributeError counts a failed operation as a client deadline expiration if the
+// deadline of its last attempt expired, and as a server failure otherwise.
+func attributeError(ctx context.Context, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || timedOut(ctx) {
+		atomic.AddInt64(&clientDeadlineErrors, 1)
+		if adaptiveTimedOut(ctx) {
+			atomic.AddInt64(&adaptiveDeadlineErrors, 1)
+		}
+	} else {
+		atomic.AddInt64(&serverErrors, 1)
+	}
+}
+
+// outputErrorAttribution prints how many errors were client-imposed deadline expirations,
+// so that timeout tuning isn't mistaken for server unreliability.
+func outputErrorAttribution(p *properties.Properties) {
+	client := atomic.LoadInt64(&clientDeadlineErrors)
+	server := atomic.LoadInt64(&serverErrors)
+	total := client + server
+	if total == 0 && p.GetParsedDuration(prop.OperationTimeout, 0) <= 0 && adaptive == nil {
+		return
+	}
+
+	clientPct, serverPct := float64(0), float64(0)
+	if total > 0 {
+		clientPct = float64(client) / float64(total) * 100
+		serverPct = float64(server) / float64(total) * 100
+	}
+	fmt.Printf("Errors - Total: %d, Client deadline: %d (%.2f%%), Adaptive deadline: %d, Server: %d (%.2f%%)\n",
+		total, client, clientPct, atomic.LoadInt64(&adaptiveDeadlineErrors), server, serverPct)
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// backupStats are the measurements of the backup or the restore phase.
+type backupStats struct {
+	method  string
+	records int64
+	missing int64
+	bytes   int64
+	elapsed time.Duration
+}
+
+func (s *backupStats) output(phase string) {
+	secs := s.elapsed.Seconds()
+	fmt.Printf("%-7s - Method: %s, Records: %d, Missing: %d, Size(MB): %.1f, Takes(s): %.1f, Records/s: %.1f, MB/s: %.1f\n",
+		phase, s.method, s.records, s.missing, float64(s.bytes)/(1<<20), secs,
+		float64(s.records)/secs, float64(s.bytes)/(1<<20)/secs)
+}
+
+// backupWriter writes records as the uvarint length prefixed key, the field count,
+// and the field names and values.
+type backupWriter struct {
+	w     *bufio.Writer
+	buf   [binary.MaxVarintLen64]byte
+	bytes int64
+}
+
+func (b *backupWriter) writeBytes(p []byte) error {
+	n := binary.PutUvarint(b.buf[:], uint64(len(p)))
+	if _, err := b.w.Write(b.buf[:n]); err != nil {
+		return err
+	}
+	_, err := b.w.Write(p)
+	b.bytes += int64(len(p))
+	return err
+}
+
+func (b *backupWriter) write(key string, values map[string][]byte) error {
+	if err := b.writeBytes(util.Slice(key)); err != nil {
+		return err
+	}
+	n := binary.PutUvarint(b.buf[:], uint64(len(values)))
+	if _, err := b.w.Write(b.buf[:n]); err != nil {
+		return err
+	}
+	for field, value := range values {
+		if err := b.writeBytes(util.Slice(field)); err != nil {
+			return err
+		}
+		if err := b.writeBytes(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readBackupBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	p := make([]byte, n)
+	_, err = io.ReadFull(r, p)
+	return p, err
+}
+
+// readBackupRecord reads a record written by backupWriter, and returns io.EOF after the last one.
+func readBackupRecord(r *bufio.Reader) (string, map[string][]byte, error) {
+	key, err := readBackupBytes(r)
+	if err != nil {
+		return "", nil, err
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", nil, err
+	}
+	values := make(map[string][]byte, count)
+	for i := uint64(0); i < count; i++ {
+		field, err := readBackupBytes(r)
+		if err != nil {
+			return "", nil, err
+		}
+		if values[string(field)], err = readBackupBytes(r); err != nil {
+			return "", nil, err
+		}
+	}
+	return string(key), values, nil
+}
+
+// backup reads every record of the table to w, with a streaming scan if the DB
+// supports it, otherwise by reading the records loaded by the workload one by one.
+func backup(ctx context.Context, p *properties.Properties, workload ycsb.Workload, db ycsb.DB, table string, w *backupWriter) (*backupStats, error) {
+	s := &backupStats{method: "stream scan"}
+	start := time.Now()
+	defer func() {
+		s.elapsed = time.Since(start)
+		s.bytes = w.bytes
+	}()
+
+	if _, ok := unwrap(db).(ycsb.StreamScanDB); ok {
+		err := db.(ycsb.StreamScanDB).StreamScan(ctx, table, func(key string, values map[string][]byte) error {
+			s.records++
+			return w.write(key, values)
+		})
+		return s, err
+	}
+
+	namer, ok := workload.(ycsb.KeyNameWorkload)
+	if !ok {
+		return nil, errors.New("the DB has no streaming scan, and the workload can't name the keys to read")
+	}
+	s.method = "point reads"
+	recordCount := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	insertStart := p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	insertCount := p.GetInt64(prop.InsertCount, recordCount-insertStart)
+	for keyNum := insertStart; keyNum < insertStart+insertCount; keyNum++ {
+		if ctx.Err() != nil {
+			return s, ctx.Err()
+		}
+		key := namer.KeyName(keyNum)
+		values, err := db.Read(ctx, table, key, nil)
+		if errors.Is(err, ycsb.ErrNotFound) || (err == nil && len(values) == 0) {
+			s.missing++
+			continue
+		} else if err != nil {
+			return s, err
+		}
+		s.records++
+		if err = w.write(key, values); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// restore inserts every record of r into the table, in batches if the DB supports them.
+func restore(ctx context.Context, db ycsb.DB, table string, batchSize int, r *bufio.Reader) (*backupStats, error) {
+	s := &backupStats{method: "insert"}
+	// DbWrapper falls back to single inserts for the DBs which can't batch
+	_, ok := unwrap(db).(ycsb.BatchDB)
+	if ok && batchSize > 1 {
+		s.method = fmt.Sprintf("batch insert(%d)", batchSize)
+	} else {
+		batchSize = 1
+	}
+
+	start := time.Now()
+	defer func() {
+		s.elapsed = time.Since(start)
+	}()
+
+	keys := make([]string, 0, batchSize)
+	rows := make([]map[string][]byte, 0, batchSize)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		var err error
+		if batchSize > 1 {
+			err = db.(ycsb.BatchDB).BatchInsert(ctx, table, keys, rows)
+		} else {
+			err = db.Insert(ctx, table, keys[0], rows[0])
+		}
+		keys, rows = keys[:0], rows[:0]
+		return err
+	}
+	for {
+		if ctx.Err() != nil {
+			return s, ctx.Err()
+		}
+		key, values, err := readBackupRecord(r)
+		if err == io.EOF {
+			return s, flush()
+		} else if err != nil {
+			return s, err
+		}
+		s.records++
+		s.bytes += int64(len(key))
+		for field, value := range values {
+			s.bytes += int64(len(field) + len(value))
+		}
+		keys = append(keys, key)
+		rows = append(rows, values)
+		if len(keys) == batchSize {
+			if err = flush(); err != nil {
+				return s, err
+			}
+		}
+	}
+}
+
+// RunBackupRestore measures backing up the whole table to a file, then restoring it by
+// re-inserting every record into another table, and prints the throughput of both
+// phases.
+func RunBackupRestore(ctx context.Context, p *properties.Properties, workload ycsb.Workload, db ycsb.DB) error {
+	table := p.GetString(prop.TableName, prop.TableNameDefault)
+	// restoring to the table backed up would insert the keys it holds again
+	restoreTable := p.GetString(prop.BackupRestoreTable, "")
+	if restoreTable == "" || restoreTable == table {
+		return fmt.Errorf("%s must name a table other than %s to restore to", prop.BackupRestoreTable, table)
+	}
+	path := p.GetString(prop.BackupFile, "")
+	var (
+		f   *os.File
+		err error
+	)
+	if path == "" {
+		f, err = ioutil.TempFile("", "go-ycsb-backup")
+		if err == nil {
+			defer os.Remove(f.Name())
+		}
+	} else {
+		f, err = os.Create(path)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx = workload.InitThread(ctx, 0, 1)
+	ctx = db.InitThread(ctx, 0, 1)
+	defer workload.CleanupThread(ctx)
+	defer db.CleanupThread(ctx)
+
+	w := &backupWriter{w: bufio.NewWriterSize(f, 1<<20)}
+	backupStats, err := backup(ctx, p, workload, db, table, w)
+	if err == nil {
+		err = w.w.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("backup failed: %v", err)
+	}
+	backupStats.output("BACKUP")
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	batchSize := p.GetInt(prop.BatchSize, prop.DefaultBatchSize)
+	restoreStats, err := restore(ctx, db, restoreTable, batchSize, bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return fmt.Errorf("restore failed: %v", err)
+	}
+	restoreStats.output("RESTORE")
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// cacheProbe estimates how effective the caches of the DB are, by reading random
+// keys several times in a row before the run warms them, and comparing the latency
+// of the first, cold, read of every key to the next, warm, ones.
+type cacheProbe struct {
+	namer  ycsb.KeyNameWorkload
+	db     ycsb.DB
+	tables []string
+	keys   int64
+	burst  int64
+	r      *rand.Rand
+
+	insertStart int64
+	insertCount int64
+}
+
+func newCacheProbe(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) (*cacheProbe, error) {
+	keys := p.GetInt64(prop.CacheProbeKeys, 0)
+	if keys <= 0 || !p.GetBool(prop.DoTransactions, true) {
+		// the records only exist once loaded
+		return nil, nil
+	}
+	namer, ok := workload.(ycsb.KeyNameWorkload)
+	if !ok {
+		return nil, errors.New("the workload can't name the keys to read")
+	}
+	burst := p.GetInt64(prop.CacheProbeBurst, prop.CacheProbeBurstDefault)
+	if burst < 2 {
+		return nil, fmt.Errorf("%s must be at least 2", prop.CacheProbeBurst)
+	}
+	// read the DB directly, so that the probe isn't part of the measured operations
+	db = unwrap(db)
+
+	seed := p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	recordCount := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	insertStart := p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	return &cacheProbe{
+		namer:       namer,
+		db:          db,
+		tables:      util.TableNames(p),
+		keys:        keys,
+		burst:       burst,
+		r:           rand.New(rand.NewSource(seed)),
+		insertStart: insertStart,
+		insertCount: p.GetInt64(prop.InsertCount, recordCount-insertStart),
+	}, nil
+}
+
+// cacheProbeStats are the latencies of the cold and warm reads on an endpoint.
+type cacheProbeStats struct {
+	endpoint string
+	cold     []time.Duration
+	warm     []time.Duration
+	failed   int64
+}
+
+// maxProbeKeyRetries is how many times a key already probed is chosen again, before
+// probing it anyway, so that a small keyspace doesn't spin.
+const maxProbeKeyRetries = 100
+
+// probe reads random keys burst times each. The keys aren't probed on another
+// endpoint before, unless the keyspace is too small, since the reads of a key on an
+// endpoint may warm the caches the other endpoints share.
+func (c *cacheProbe) probe(ctx context.Context, endpoint string, probed map[int64]bool) *cacheProbeStats {
+	s := &cacheProbeStats{endpoint: endpoint}
+	for i := int64(0); i < c.keys && ctx.Err() == nil; i++ {
+		keyNum := c.insertStart + c.r.Int63n(c.insertCount)
+		for j := 0; j < maxProbeKeyRetries && probed[keyNum]; j++ {
+			keyNum = c.insertStart + c.r.Int63n(c.insertCount)
+		}
+		probed[keyNum] = true
+		key := c.namer.KeyName(keyNum)
+		cold := true
+		for j := int64(0); j < c.burst; j++ {
+			start := time.Now()
+			_, err := c.db.Read(ctx, util.KeyTable(c.tables, key), key, nil)
+			latency := time.Since(start)
+			if err != nil {
+				// the first successful read is the cold one
+				s.failed++
+			} else if cold {
+				s.cold = append(s.cold, latency)
+				cold = false
+			} else {
+				s.warm = append(s.warm, latency)
+			}
+		}
+	}
+	return s
+}
+
+func latencyStats(latencies []time.Duration) (avg time.Duration, p99 time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+	return sum / time.Duration(len(latencies)), latencies[(len(latencies)-1)*99/100]
+}
+
+func (s *cacheProbeStats) output() {
+	coldAvg, coldP99 := latencyStats(s.cold)
+	warmAvg, warmP99 := latencyStats(s.warm)
+	fmt.Printf("%-6s - Endpoint: %s, Keys: %d, Failed reads: %d, Cold avg(us): %d, Cold 99th(us): %d, Warm avg(us): %d, Warm 99th(us): %d",
+		"CACHE", s.endpoint, len(s.cold), s.failed,
+		coldAvg.Microseconds(), coldP99.Microseconds(), warmAvg.Microseconds(), warmP99.Microseconds())
+	if coldAvg > 0 {
+		// the share of the cold read latency the caches save
+		fmt.Printf(", Effectiveness: %.1f%%", (1-float64(warmAvg)/float64(coldAvg))*100)
+	}
+	fmt.Println()
+}
+
+// run probes every endpoint of the DB in turn, or the DB as a whole if it doesn't
+// expose its endpoints, and returns the cold and warm read latencies of each, until
+// ctx is done. It runs before the workers, as the first of them, since the DBs may
+// not take more threads than threadcount.
+func (c *cacheProbe) run(ctx context.Context, threadCount int) []*cacheProbeStats {
+	ctx = c.db.InitThread(ctx, 0, threadCount)
+	defer c.db.CleanupThread(ctx)
+
+	probed := make(map[int64]bool)
+	endpointDB, ok := c.db.(ycsb.EndpointDB)
+	if !ok || len(endpointDB.Endpoints()) == 0 {
+		return []*cacheProbeStats{c.probe(ctx, "all", probed)}
+	}
+	var stats []*cacheProbeStats
+	for i, endpoint := range endpointDB.Endpoints() {
+		stats = append(stats, c.probe(util.WithEndpoint(ctx, i), endpoint, probed))
+	}
+	return stats
+}
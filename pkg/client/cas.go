@@ -0,0 +1,36 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// The conditional updates, and those of them which found other values than expected.
+var (
+	casAttempts  int64
+	casConflicts int64
+)
+
+// outputCASConflicts prints the share of the conditional updates which conflicted with
+// concurrent ones, if there were any.
+func outputCASConflicts() {
+	attempts := atomic.LoadInt64(&casAttempts)
+	if attempts == 0 {
+		return
+	}
+	conflicts := atomic.LoadInt64(&casConflicts)
+	fmt.Printf("CAS - Attempts: %d, Conflicts: %d (%.2f%%)\n", attempts, conflicts, percentOf(conflicts, attempts))
+}
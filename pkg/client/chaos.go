@@ -0,0 +1,120 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// corrupter is the response corruption used by DbWrapper, nil if disabled.
+var corrupter *responseCorrupter
+
+// responseCorrupter corrupts a fraction of the rows read from the DB before the
+// workload sees them, to check that data integrity checking catches bad data.
+type responseCorrupter struct {
+	rate     float64
+	bitFlip  bool
+	truncate bool
+
+	mu sync.Mutex
+	r  *rand.Rand
+
+	bitFlips  int64
+	truncated int64
+}
+
+func newResponseCorrupter(p *properties.Properties) (*responseCorrupter, error) {
+	rate := p.GetFloat64(prop.ChaosCorruptRate, 0)
+	if rate <= 0 {
+		return nil, nil
+	}
+
+	c := &responseCorrupter{
+		rate: rate,
+		r:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	switch mode := p.GetString(prop.ChaosCorruptMode, prop.ChaosCorruptModeDefault); mode {
+	case "bitflip":
+		c.bitFlip = true
+	case "truncate":
+		c.truncate = true
+	case "all":
+		c.bitFlip = true
+		c.truncate = true
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting bitflip, truncate or all", prop.ChaosCorruptMode, mode)
+	}
+	return c, nil
+}
+
+// corruptRow returns the row, or a corrupted copy of it with a probability of the
+// corruption rate. The row itself is never modified, as the DB may still own it.
+func (c *responseCorrupter) corruptRow(row map[string][]byte) map[string][]byte {
+	if len(row) == 0 {
+		return row
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.r.Float64() >= c.rate {
+		return row
+	}
+
+	corrupted := make(map[string][]byte, len(row))
+	fields := make([]string, 0, len(row))
+	for field, value := range row {
+		corrupted[field] = value
+		fields = append(fields, field)
+	}
+	field := fields[c.r.Intn(len(fields))]
+
+	if c.bitFlip && (!c.truncate || c.r.Intn(2) == 0) {
+		if len(row[field]) == 0 {
+			return row
+		}
+		value := append([]byte(nil), row[field]...)
+		i := c.r.Intn(len(value))
+		value[i] ^= 1 << uint(c.r.Intn(8))
+		corrupted[field] = value
+		atomic.AddInt64(&c.bitFlips, 1)
+	} else {
+		delete(corrupted, field)
+		atomic.AddInt64(&c.truncated, 1)
+	}
+	return corrupted
+}
+
+func (c *responseCorrupter) corruptRows(rows []map[string][]byte) []map[string][]byte {
+	if len(rows) == 0 {
+		return rows
+	}
+	corrupted := make([]map[string][]byte, len(rows))
+	for i, row := range rows {
+		corrupted[i] = c.corruptRow(row)
+	}
+	return corrupted
+}
+
+func (c *responseCorrupter) output() {
+	bitFlips := atomic.LoadInt64(&c.bitFlips)
+	truncated := atomic.LoadInt64(&c.truncated)
+	fmt.Printf("Chaos - Corrupted rows: %d, Bit flips: %d, Truncated: %d\n", bitFlips+truncated, bitFlips, truncated)
+}
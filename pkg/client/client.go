@@ -15,15 +15,18 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
@@ -39,9 +42,55 @@ type worker struct {
 	threadID        int
 	targetOpsTickNs int64
 	opsDone         int64
+	sched           *scheduler
+	// pipeline runs the operations of the thread concurrently, nil if it runs them one at a time
+	pipeline *pipeline
+	// think is the pause between the operations of the thread, nil if it doesn't pause
+	think     *thinkTime
+	thinkRand *rand.Rand
+	// schedule is the changing target throughput of the thread, shared by
+	// targetThreads threads, nil if it doesn't change
+	schedule      *targetSchedule
+	targetThreads int64
+	// poolTarget is set if the thread pool of the thread has its own target,
+	// which the target set through the control endpoint doesn't replace
+	poolTarget bool
+	// controlVersion is the version of the control last applied, and controlTarget
+	// the target it set
+	controlVersion int64
+	controlTarget  int64
+	// done is set once the workload has no more operations
+	done bool
 }
 
-func newWorker(p *properties.Properties, threadID int, threadCount int, workload ycsb.Workload, db ycsb.DB) *worker {
+// totalOpCount returns the number of operations to execute over all the workers.
+func totalOpCount(p *properties.Properties) int64 {
+	if p.GetBool(prop.DoTransactions, true) {
+		return p.GetInt64(prop.OperationCount, 0)
+	}
+	if _, ok := p.Get(prop.InsertCount); ok {
+		return p.GetInt64(prop.InsertCount, 0)
+	}
+	return p.GetInt64(prop.RecordCount, 0)
+}
+
+// opCountLimit returns the limit set by totalOpCount, as the property setting it.
+func opCountLimit(p *properties.Properties) string {
+	name := prop.RecordCount
+	if p.GetBool(prop.DoTransactions, true) {
+		name = prop.OperationCount
+	} else if _, ok := p.Get(prop.InsertCount); ok {
+		name = prop.InsertCount
+	}
+	return fmt.Sprintf("%s=%d", name, totalOpCount(p))
+}
+
+// maxExecutionTime returns the time the run is limited to, 0 for none.
+func maxExecutionTime(p *properties.Properties) time.Duration {
+	return time.Duration(p.GetInt64(prop.MaxExecutiontime, 0)) * time.Second
+}
+
+func newWorker(p *properties.Properties, threadID int, threadCount int, pools []util.ThreadPool, schedule *targetSchedule, workload ycsb.Workload, db ycsb.DB) *worker {
 	w := new(worker)
 	w.p = p
 	w.doTransactions = p.GetBool(prop.DoTransactions, true)
@@ -52,19 +101,13 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 	w.threadID = threadID
 	w.workload = workload
 	w.workDB = db
+	w.targetThreads = int64(threadCount)
+	w.controlVersion = -1
 
-	var totalOpCount int64
-	if w.doTransactions {
-		totalOpCount = p.GetInt64(prop.OperationCount, 0)
-	} else {
-		if _, ok := p.Get(prop.InsertCount); ok {
-			totalOpCount = p.GetInt64(prop.InsertCount, 0)
-		} else {
-			totalOpCount = p.GetInt64(prop.RecordCount, 0)
-		}
-	}
+	totalOpCount := totalOpCount(p)
 
-	if totalOpCount < int64(threadCount) {
+	// with a time limit and no operation count, the run only ends at the time limit
+	if totalOpCount < int64(threadCount) && !(totalOpCount == 0 && maxExecutionTime(p) > 0) {
 		fmt.Printf("totalOpCount(%s/%s/%s): %d should be bigger than threadCount: %d",
 			prop.OperationCount,
 			prop.InsertCount,
@@ -75,13 +118,28 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 		os.Exit(-1)
 	}
 
+	// the remainder is spread over the first threads, so that exactly totalOpCount
+	// operations are executed
 	w.opCount = totalOpCount / int64(threadCount)
+	if int64(threadID) < totalOpCount%int64(threadCount) {
+		w.opCount++
+	}
 
 	targetPerThreadPerms := float64(-1)
 	if v := p.GetInt64(prop.Target, 0); v > 0 {
 		targetPerThread := float64(v) / float64(threadCount)
 		targetPerThreadPerms = targetPerThread / 1000.0
 	}
+	if schedule != nil {
+		w.schedule = schedule
+		targetPerThreadPerms = schedule.rates[0] / float64(threadCount) / 1000.0
+	}
+	if pool := util.FindThreadPool(pools, threadID); pool != nil && pool.Target > 0 {
+		targetPerThread := float64(pool.Target) / float64(pool.Threads)
+		targetPerThreadPerms = targetPerThread / 1000.0
+		w.schedule = nil
+		w.poolTarget = true
+	}
 
 	if targetPerThreadPerms > 0 {
 		w.targetOpsPerMs = targetPerThreadPerms
@@ -91,13 +149,45 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 	return w
 }
 
-func (w *worker) throttle(ctx context.Context, startTime time.Time) {
+// finished returns whether the worker did all its operations, or the workload ran out of them.
+func (w *worker) finished() bool {
+	return w.done || (w.opCount > 0 && w.opsDone >= w.opCount)
+}
+
+// due returns the time the operation after opsDone ones is scheduled to start at.
+func (w *worker) due(startTime time.Time, opsDone int64) time.Time {
+	if w.schedule != nil {
+		return startTime.Add(w.schedule.offset(float64(opsDone * w.targetThreads)))
+	}
+	return startTime.Add(time.Duration(opsDone * w.targetOpsTickNs))
+}
+
+// applyControl waits while the run is paused, and applies the target set through
+// the control endpoint. It returns how long the worker was paused, whether the
+// target changed, and false if ctx is done in the meantime.
+func (w *worker) applyControl(ctx context.Context) (time.Duration, bool, bool) {
+	version, target, paused, ok := control.wait(ctx)
+	if !ok {
+		return 0, false, false
+	}
+	w.controlVersion = version
+	if target <= 0 || target == w.controlTarget || w.poolTarget {
+		return paused, false, true
+	}
+
+	w.controlTarget = target
+	w.schedule = nil
+	w.targetOpsPerMs = float64(target) / float64(w.targetThreads) / 1000.0
+	w.targetOpsTickNs = int64(1000000.0 / w.targetOpsPerMs)
+	return paused, true, true
+}
+
+func (w *worker) throttle(ctx context.Context, startTime time.Time, opsDone int64) {
 	if w.targetOpsPerMs <= 0 {
 		return
 	}
 
-	d := time.Duration(w.opsDone * w.targetOpsTickNs)
-	d = startTime.Add(d).Sub(time.Now())
+	d := time.Until(w.due(startTime, opsDone))
 	if d < 0 {
 		return
 	}
@@ -107,40 +197,115 @@ func (w *worker) throttle(ctx context.Context, startTime time.Time) {
 	}
 }
 
+// doOperation executes one transaction or insert, and returns the number of operations it covers.
+func (w *worker) doOperation(ctx context.Context) int {
+	opsCount, err := w.execute(ctx)
+	if errors.Is(err, ycsb.ErrWorkloadDone) {
+		w.done = true
+		return 0
+	}
+	return opsCount
+}
+
+// execute executes one transaction or insert, and returns the number of operations it
+// covers and its error.
+func (w *worker) execute(ctx context.Context) (int, error) {
+	var err error
+	opsCount := 1
+	if w.doTransactions {
+		if w.doBatch {
+			err = w.workload.DoBatchTransaction(ctx, w.batchSize, w.workDB)
+			opsCount = w.batchSize
+		} else {
+			err = w.workload.DoTransaction(ctx, w.workDB)
+		}
+	} else {
+		if w.doBatch {
+			err = w.workload.DoBatchInsert(ctx, w.batchSize, w.workDB)
+			opsCount = w.batchSize
+		} else {
+			err = w.workload.DoInsert(ctx, w.workDB)
+		}
+	}
+
+	if err != nil && !errors.Is(err, ycsb.ErrWorkloadDone) && !w.p.GetBool(prop.Silence, prop.SilenceDefault) {
+		fmt.Printf("operation err: %v\n", err)
+	}
+
+	return opsCount, err
+}
+
 func (w *worker) run(ctx context.Context) {
+	if w.sched != nil {
+		w.runOpenLoop(ctx)
+		return
+	}
+
 	// spread the thread operation out so they don't all hit the DB at the same time
 	if w.targetOpsPerMs > 0.0 && w.targetOpsPerMs <= 1.0 {
 		time.Sleep(time.Duration(rand.Int63n(w.targetOpsTickNs)))
 	}
 
-	startTime := time.Now()
+	startTime, startOps := time.Now(), int64(0)
+	warmUpFinished := measurement.IsWarmUpFinished()
 
-	for w.opCount == 0 || w.opsDone < w.opCount {
-		var err error
-		opsCount := 1
-		if w.doTransactions {
-			if w.doBatch {
-				err = w.workload.DoBatchTransaction(ctx, w.batchSize, w.workDB)
-				opsCount = w.batchSize
+	var intendedStart *time.Time
+	if w.targetOpsPerMs > 0 {
+		intendedStart = new(time.Time)
+		ctx = context.WithValue(ctx, intendedStartKey{}, intendedStart)
+	}
+
+	// operations during warm-up run on their own schedule, and don't count towards opCount
+	var warmUpOpsDone int64
+	for (w.opCount == 0 || w.opsDone < w.opCount) && !w.done {
+		opsDone := &w.opsDone
+		if !warmUpFinished {
+			if measurement.IsWarmUpFinished() {
+				// the schedule restarts once warm-up is done
+				warmUpFinished = true
+				startTime, startOps = time.Now(), w.opsDone
 			} else {
-				err = w.workload.DoTransaction(ctx, w.workDB)
+				opsDone = &warmUpOpsDone
 			}
-		} else {
-			if w.doBatch {
-				err = w.workload.DoBatchInsert(ctx, w.batchSize, w.workDB)
-				opsCount = w.batchSize
+		}
+		if control.changed(w.controlVersion) {
+			paused, retargeted, ok := w.applyControl(ctx)
+			if !ok {
+				return
+			}
+			if retargeted {
+				// the new target applies from now on
+				startTime, startOps = time.Now(), *opsDone
 			} else {
-				err = w.workload.DoInsert(ctx, w.workDB)
+				// the schedule resumes where it was paused
+				startTime = startTime.Add(paused)
+			}
+			if intendedStart == nil && w.targetOpsPerMs > 0 {
+				intendedStart = new(time.Time)
+				ctx = context.WithValue(ctx, intendedStartKey{}, intendedStart)
 			}
 		}
-
-		if err != nil && !w.p.GetBool(prop.Silence, prop.SilenceDefault) {
-			fmt.Printf("operation err: %v\n", err)
+		if intendedStart != nil {
+			*intendedStart = w.due(startTime, *opsDone-startOps)
 		}
 
-		if measurement.IsWarmUpFinished() {
-			w.opsDone += int64(opsCount)
-			w.throttle(ctx, startTime)
+		if w.pipeline != nil {
+			n, ok := w.submit(ctx, intendedStart)
+			if !ok {
+				return
+			}
+			*opsDone += int64(n)
+		} else {
+			*opsDone += int64(w.doOperation(ctx))
+		}
+		w.throttle(ctx, startTime, *opsDone-startOps)
+		if w.think != nil {
+			paused, ok := w.think.pause(ctx, w.thinkRand)
+			if !ok {
+				return
+			}
+			// the schedule leaves the pauses out, so that they don't show as intended latency
+			startTime = startTime.Add(paused)
 		}
 
 		select {
@@ -151,13 +316,30 @@ func (w *worker) run(ctx context.Context) {
 	}
 }
 
+func outputInterval() {
+	if err := measurement.OutputInterval(); err != nil {
+		fmt.Printf("Write time series failed: %v\n", err)
+	}
+}
+
 // Client is a struct which is used the run workload to a specific DB.
 type Client struct {
 	p        *properties.Properties
 	workload ycsb.Workload
 	db       ycsb.DB
+	// aborted is set if the run was stopped by the error budget
+	aborted bool
+	// limit is the limit which ended the run, "" if it was interrupted or aborted
+	limit string
+	// operations are the operations the threads executed, out of warm-up, in elapsed
+	operations int64
+	elapsed    time.Duration
 }
 
+// LimitWorkload is the limit of the runs which ended because the workload ran out of
+// operations, e.g. at the end of a replayed trace.
+const LimitWorkload = "the end of the workload"
+
 // NewClient returns a client with the given workload and DB.
 // The workload and db can't be nil.
 func NewClient(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *Client {
@@ -166,9 +348,146 @@ func NewClient(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *Cl
 
 // Run runs the workload to the target DB, and blocks until all workers end.
 func (c *Client) Run(ctx context.Context) {
+	// the error budget stops the run as an interruption would
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
+	// and so does maxexecutiontime, without the operations it cuts short being measured
+	// as failed
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
 	var wg sync.WaitGroup
 	threadCount := c.p.GetInt(prop.ThreadCount, 1)
 
+	var err error
+	if limiter, err = newConcurrencyLimiter(c.p); err != nil {
+		fmt.Printf("Initialize concurrency limiter fail: %v\n", err)
+		return
+	}
+	callTimeout = c.p.GetParsedDuration(prop.OperationTimeout, 0)
+	if adaptive, err = newAdaptiveTimeout(c.p); err != nil {
+		fmt.Printf("Initialize adaptive timeout fail: %v\n", err)
+		return
+	}
+	if c.p.GetBool(prop.DoTransactions, true) && c.p.GetFloat64(prop.CASProportion, prop.CASProportionDefault) > 0 {
+		if _, ok := unwrap(c.db).(ycsb.CASDB); !ok {
+			fmt.Printf("Initialize workload fail: %s is set, but the DB doesn't implement CAS\n", prop.CASProportion)
+			return
+		}
+	}
+	if c.p.GetBool(prop.DoTransactions, true) && c.p.GetInt64(prop.TransactionSize, prop.TransactionSizeDefault) > 1 {
+		// the wrapper implements RunTx whatever the DB, failing every transaction
+		if _, ok := unwrap(c.db).(ycsb.TransactionDB); !ok {
+			fmt.Printf("Initialize workload fail: %s is set, but the DB doesn't implement transactions\n", prop.TransactionSize)
+			return
+		}
+	}
+	if _, ok := c.p.Get(prop.LBPolicy); ok {
+		// only the DBs which spread their requests over endpoints balance them
+		if _, ok := unwrap(c.db).(ycsb.EndpointDB); !ok {
+			fmt.Printf("Initialize load balancing fail: %s is set, but the DB doesn't have several endpoints to balance\n", prop.LBPolicy)
+			return
+		}
+	}
+	if hedger, err = newReadHedger(c.p, c.db); err != nil {
+		fmt.Printf("Initialize read hedging fail: %v\n", err)
+		return
+	}
+	if retrier, err = newRetryPolicy(c.p); err != nil {
+		fmt.Printf("Initialize retry policy fail: %v\n", err)
+		return
+	}
+	if budget, err = newErrorBudget(c.p); err != nil {
+		fmt.Printf("Initialize error budget fail: %v\n", err)
+		return
+	}
+	if corrupter, err = newResponseCorrupter(c.p); err != nil {
+		fmt.Printf("Initialize response corrupter fail: %v\n", err)
+		return
+	}
+	if tracer, err = newOpTracer(c.p); err != nil {
+		fmt.Printf("Initialize tracing fail: %v\n", err)
+		return
+	}
+	if recorder, err = newTraceRecorder(c.p); err != nil {
+		fmt.Printf("Initialize trace recording fail: %v\n", err)
+		return
+	}
+	if historian, err = newHistoryRecorder(c.p, c.db); err != nil {
+		fmt.Printf("Initialize history recording fail: %v\n", err)
+		return
+	}
+	var pools []util.ThreadPool
+	if s := c.p.GetString(prop.ThreadPools, ""); s != "" {
+		if pools, err = util.ParseThreadPools(s); err != nil {
+			fmt.Printf("Parse thread pools fail: %v\n", err)
+			return
+		}
+	}
+	ramp, err := newThreadRamp(c.p, threadCount)
+	if err != nil {
+		fmt.Printf("Initialize thread ramp fail: %v\n", err)
+		return
+	}
+	schedule, err := newTargetSchedule(c.p)
+	if err != nil {
+		fmt.Printf("Initialize target schedule fail: %v\n", err)
+		return
+	}
+	think, err := newThinkTime(c.p)
+	if err != nil {
+		fmt.Printf("Initialize think time fail: %v\n", err)
+		return
+	}
+	inflight, err := pipelineInflight(c.p)
+	if err != nil {
+		fmt.Printf("Initialize pipeline fail: %v\n", err)
+		return
+	}
+	pipelineStart, pipelineSubmitted, pipelineBlocked, pipelineWaitTime = 0, 0, 0, 0
+	sched, err := newScheduler(c.p)
+	if err != nil {
+		fmt.Printf("Initialize open loop scheduler fail: %v\n", err)
+		return
+	}
+	grower, err := newKeyspaceGrower(c.p, c.workload, c.db)
+	if err != nil {
+		fmt.Printf("Initialize keyspace growth fail: %v\n", err)
+		return
+	}
+	self := newSelfProfiler(c.p)
+	cache, err := newCacheProbe(c.p, c.workload, c.db)
+	if err != nil {
+		fmt.Printf("Initialize cache probe fail: %v\n", err)
+		return
+	}
+
+	outputMode := c.p.GetString(prop.OutputMode, prop.OutputModeDefault)
+	var progress interface {
+		output()
+		end()
+	}
+	switch outputMode {
+	case OutputNormal, OutputQuiet:
+	case OutputProgress:
+		progress = newProgressLine(totalOpCount(c.p))
+	case OutputDashboard:
+		progress = newDashboard(totalOpCount(c.p))
+	default:
+		fmt.Printf("Unknown %s %q; expecting %s, %s, %s or %s\n", prop.OutputMode, outputMode,
+			OutputNormal, OutputQuiet, OutputProgress, OutputDashboard)
+		return
+	}
+
+	stage, phase := "load", PhaseRunning
+	if c.p.GetBool(prop.DoTransactions, true) {
+		stage = "run"
+		if c.p.GetInt64(prop.WarmUpTime, 0) > 0 {
+			phase = PhaseWarmUp
+		}
+	}
+	status.begin(stage, totalOpCount(c.p), phase)
+
+	conns := new(connReporter)
 	wg.Add(threadCount)
 	measureCtx, measureCancel := context.WithCancel(ctx)
 	measureCh := make(chan struct{}, 1)
@@ -186,33 +505,136 @@ func (c *Client) Run(ctx context.Context) {
 			}
 		}
 		// finish warming up
-		measurement.EnableWarmUp(false)
+		if !measurement.IsWarmUpFinished() {
+			measurement.EnableWarmUp(false)
+			measurement.OutputWarmUp()
+		}
+		status.setPhase(PhaseRunning)
 
 		dur := c.p.GetInt64(prop.LogInterval, 10)
 		t := time.NewTicker(time.Duration(dur) * time.Second)
 		defer t.Stop()
 
+		var progressC <-chan time.Time
+		if progress != nil {
+			pt := time.NewTicker(progressInterval)
+			defer pt.Stop()
+			progressC = pt.C
+		}
+
 		for {
 			select {
 			case <-t.C:
-				measurement.Output()
+				if outputMode == OutputNormal {
+					measurement.Output()
+					if limiter != nil {
+						limiter.output()
+					}
+					if grower != nil {
+						grower.output(ctx)
+					}
+					conns.output()
+					outputEvents(c.db)
+					if self != nil {
+						self.output()
+					}
+				} else if self != nil {
+					// keep track of the peaks
+					self.sample()
+				}
+				outputInterval()
+				if outputMode == OutputNormal {
+					measurement.OutputWriteStall()
+				}
+			case <-progressC:
+				progress.output()
 			case <-measureCtx.Done():
+				outputInterval()
 				return
 			}
 		}
 	}()
 
-	if err := c.workload.Init(c.db); err != nil {
+	// the workload reads what it needs before the run through the unmeasured DB
+	if err := c.workload.Init(unwrap(c.db)); err != nil {
 		fmt.Printf("Initialize workload fail: %v\n", err)
 		return
 	}
 
+	var cacheStats []*cacheProbeStats
+	if cache != nil {
+		// before the run, whose reads would warm the caches
+		cacheStats = cache.run(ctx, threadCount)
+	}
+
+	probe := newStorageProbe(ctx, c.db)
+	runStart := time.Now()
+	var timeLimit *time.Timer
+	if d := maxExecutionTime(c.p); d > 0 {
+		timeLimit = time.AfterFunc(d, stop)
+	}
+	if sched != nil {
+		if schedule == nil {
+			schedule = constantTarget(c.p.GetInt64(prop.Target, 0))
+		}
+		go sched.generate(runCtx, totalOpCount(c.p), schedule)
+	}
+	budgetCtx, budgetCancel := context.WithCancel(ctx)
+	if budget != nil {
+		go budget.watch(budgetCtx, abort)
+	}
+	growCtx, growCancel := context.WithCancel(ctx)
+	growCh := make(chan struct{})
+	if grower != nil {
+		go func() {
+			defer close(growCh)
+			grower.run(growCtx, threadCount, c.workload)
+		}()
+	} else {
+		close(growCh)
+	}
+
+	var operations int64
+	var finished, exhausted int32
 	for i := 0; i < threadCount; i++ {
 		go func(threadId int) {
 			defer wg.Done()
+			ctx := runCtx
+			if ramp != nil && !ramp.wait(ctx, threadId) {
+				return
+			}
 
-			w := newWorker(c.p, threadId, threadCount, c.workload, c.db)
-			ctx := c.workload.InitThread(ctx, threadId, threadCount)
+			w := newWorker(c.p, threadId, threadCount, pools, schedule, c.workload, c.db)
+			w.sched = sched
+			if think != nil {
+				w.think = think
+				w.thinkRand = rand.New(rand.NewSource(time.Now().UnixNano() + int64(threadId)))
+			}
+			defer func() {
+				atomic.AddInt64(&operations, w.opsDone)
+				if w.finished() {
+					atomic.AddInt32(&finished, 1)
+				}
+				if w.done {
+					atomic.AddInt32(&exhausted, 1)
+				}
+			}()
+			ctx = context.WithValue(ctx, threadIDKey{}, threadId)
+			if inflight > 1 {
+				// every slot has its own DB thread state, as a connection doesn't run
+				// several operations at once
+				w.pipeline = newPipeline(ctx, inflight, func(ctx context.Context) context.Context {
+					ctx = c.workload.InitThread(ctx, threadId, threadCount)
+					return c.db.InitThread(ctx, threadId, threadCount)
+				})
+				w.run(ctx)
+				w.pipeline.close(func(ctx context.Context) {
+					c.db.CleanupThread(ctx)
+					c.workload.CleanupThread(ctx)
+				})
+				return
+			}
+			ctx = c.workload.InitThread(ctx, threadId, threadCount)
 			ctx = c.db.InitThread(ctx, threadId, threadCount)
 			w.run(ctx)
 			c.db.CleanupThread(ctx)
@@ -221,12 +643,122 @@ func (c *Client) Run(ctx context.Context) {
 	}
 
 	wg.Wait()
+	c.elapsed, c.operations = time.Since(runStart), operations
+	timedOut := timeLimit != nil && !timeLimit.Stop()
+	budgetCancel()
+	c.aborted = budget != nil && budget.isExceeded()
+	switch {
+	case ctx.Err() != nil:
+		// interrupted or aborted
+	case int(finished) == threadCount && exhausted > 0:
+		c.limit = LimitWorkload
+	case int(finished) == threadCount:
+		c.limit = opCountLimit(c.p)
+	case timedOut:
+		c.limit = fmt.Sprintf("%s=%d", prop.MaxExecutiontime, c.p.GetInt64(prop.MaxExecutiontime, 0))
+	}
+	status.setPhase(PhaseFinished)
+	// the summary is complete even if the run was interrupted by cancelling ctx
+	outputCtx := context.Background()
+	growCancel()
+	<-growCh
+	if progress != nil {
+		progress.end()
+	}
+	if verifier, ok := c.workload.(ycsb.VerifyWorkload); ok && c.p.GetBool(prop.DoTransactions, true) {
+		// the run may have been stopped by cancelling ctx, and the reads aren't part of
+		// the measured operations
+		db := unwrap(c.db)
+		verifyCtx := db.InitThread(context.Background(), threadCount, threadCount+1)
+		if err := verifier.Verify(verifyCtx, db); err != nil {
+			fmt.Printf("Verify workload failed: %v\n", err)
+		}
+		db.CleanupThread(verifyCtx)
+	}
+	if err := measurement.CloseSamples(); err != nil {
+		fmt.Printf("Write raw samples failed: %v\n", err)
+	}
 	if !c.p.GetBool(prop.DoTransactions, true) {
 		// when loading is finished, try to analyze table if possible.
 		if analyzeDB, ok := c.db.(ycsb.AnalyzeDB); ok {
-			analyzeDB.Analyze(ctx, c.p.GetString(prop.TableName, prop.TableNameDefault))
+			for _, table := range util.TableNames(c.p) {
+				analyzeDB.Analyze(outputCtx, table)
+			}
 		}
 	}
+	if probe != nil {
+		probe.output(outputCtx)
+	}
+	for _, s := range cacheStats {
+		s.output()
+	}
+	if limiter != nil {
+		limiter.output()
+		limiter.summary()
+	}
+	if grower != nil {
+		grower.output(outputCtx)
+	}
+	if sched != nil {
+		// unblock the generation if it is held up by the memory limit
+		sched.close()
+		sched.output()
+	}
+	if hedger != nil {
+		hedger.output()
+	}
+	if retrier != nil {
+		retrier.output()
+	}
+	if corrupter != nil {
+		corrupter.output()
+	}
+	if tracer != nil {
+		tracer.close()
+		tracer.output()
+	}
+	if recorder != nil {
+		recorder.close()
+		recorder.output()
+	}
+	if historian != nil {
+		historian.close()
+		historian.output()
+	}
+	if adaptive != nil {
+		adaptive.output()
+	}
+	outputErrorAttribution(c.p)
+	outputCASConflicts()
+	outputTxAborts()
+	outputPipeline()
 	measureCancel()
 	<-measureCh
+	conns.output()
+	outputEvents(c.db)
+	if self != nil {
+		self.summary()
+	}
+}
+
+// Aborted returns whether the last run was stopped early because its error rate
+// exceeded errorbudget.
+func (c *Client) Aborted() bool {
+	return c.aborted
+}
+
+// Limit returns the limit which ended the last run, as the property setting it, e.g.
+// "maxexecutiontime=60", or LimitWorkload. It is "" if the run was interrupted or aborted.
+func (c *Client) Limit() string {
+	return c.limit
+}
+
+// Operations returns the operations the last run executed out of warm-up, and Elapsed
+// the time from the start of its threads to the end of the last one.
+func (c *Client) Operations() int64 {
+	return c.operations
+}
+
+func (c *Client) Elapsed() time.Duration {
+	return c.elapsed
 }
@@ -0,0 +1,135 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runControl holds the changes to the run requested through the control endpoint:
+// pausing the generation of operations, and replacing the target throughput.
+type runControl struct {
+	// version is bumped by every change, so the workers only lock mu when it changes
+	version int64
+
+	mu sync.Mutex
+	// resumed is closed on resume, nil unless paused
+	resumed chan struct{}
+	// target is the target throughput replacing target and targetschedule, 0 if unset
+	target int64
+}
+
+var control = new(runControl)
+
+func (c *runControl) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed == nil {
+		c.resumed = make(chan struct{})
+		atomic.AddInt64(&c.version, 1)
+		fmt.Println("Control - Paused")
+	}
+}
+
+func (c *runControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resumed != nil {
+		close(c.resumed)
+		c.resumed = nil
+		atomic.AddInt64(&c.version, 1)
+		fmt.Println("Control - Resumed")
+	}
+}
+
+func (c *runControl) setTarget(opsPerSec int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.target = opsPerSec
+	atomic.AddInt64(&c.version, 1)
+	fmt.Printf("Control - Target: %d\n", opsPerSec)
+}
+
+func (c *runControl) changed(version int64) bool {
+	return atomic.LoadInt64(&c.version) != version
+}
+
+// wait waits while the run is paused, and returns the current version and target,
+// and how long it waited. It returns false if ctx is done in the meantime.
+func (c *runControl) wait(ctx context.Context) (version int64, target int64, paused time.Duration, ok bool) {
+	start := time.Now()
+	for {
+		c.mu.Lock()
+		version, target, resumed := atomic.LoadInt64(&c.version), c.target, c.resumed
+		c.mu.Unlock()
+		if resumed == nil {
+			return version, target, time.Since(start), true
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, 0, 0, false
+		case <-resumed:
+		}
+	}
+}
+
+type controlState struct {
+	Paused bool `json:"paused"`
+	// Target is the target throughput set through the endpoint, 0 if unset
+	Target int64 `json:"target,omitempty"`
+}
+
+func (c *runControl) state() controlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return controlState{Paused: c.resumed != nil, Target: c.target}
+}
+
+// serveControl serves GET /control with the control state, and POST
+// /control/pause, /control/resume and /control/target?ops=N.
+func serveControl(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/control" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "the control changes must be POSTed", http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/control/pause":
+			control.pause()
+		case "/control/resume":
+			control.resume()
+		case "/control/target":
+			ops, err := strconv.ParseInt(r.URL.Query().Get("ops"), 10, 64)
+			if err != nil || ops <= 0 {
+				http.Error(w, "ops must be a positive number of operations per second", http.StatusBadRequest)
+				return
+			}
+			control.setTarget(ops)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(control.state())
+}
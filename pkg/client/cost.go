@@ -0,0 +1,76 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// propertyCost prices the operations with the unit prices given by the cost.<op>
+// properties. Failed operations are billed like successful ones.
+func propertyCost(p *properties.Properties, ops map[string]int64) (float64, bool) {
+	priced := false
+	cost := float64(0)
+	for op, count := range ops {
+		name := strings.ToLower(strings.TrimSuffix(op, "_ERROR"))
+		if _, ok := p.Get(prop.CostPrefix + name); !ok {
+			continue
+		}
+		priced = true
+		cost += p.GetFloat64(prop.CostPrefix+name, 0) * float64(count) / 1e6
+	}
+	return cost, priced
+}
+
+// OutputCost prints the estimated cost of the operations measured so far.
+// The DB's own cost model is used if it has one, otherwise the cost.<op> unit
+// prices are used. Nothing is printed if neither is available.
+func OutputCost(p *properties.Properties, db ycsb.DB) {
+	ops := make(map[string]int64)
+	for op, info := range measurement.Info() {
+		if measurement.IsDerived(op) {
+			continue
+		}
+		if count, ok := info.Get(measurement.COUNT).(int64); ok {
+			ops[op] = count
+		}
+	}
+
+	var (
+		cost float64
+		err  error
+	)
+	costDB, ok := db.(ycsb.CostDB)
+	if ok {
+		cost, err = costDB.EstimateCost(ops)
+	}
+	if !ok || err == errNotSupported {
+		if cost, ok = propertyCost(p, ops); !ok {
+			return
+		}
+		err = nil
+	}
+
+	if err != nil {
+		fmt.Printf("Estimate cost failed: %v\n", err)
+		return
+	}
+	fmt.Printf("Estimated cost(USD): %.6f\n", cost)
+}
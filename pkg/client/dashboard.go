@@ -0,0 +1,144 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+)
+
+// dashboardHistory is how many refreshes of throughput the sparklines show.
+const dashboardHistory = 60
+
+var (
+	dashboardPercentiles = []float64{50, 99, 99.9}
+	sparkRunes           = []rune("▁▂▃▄▅▆▇█")
+)
+
+// dashboard redraws a live view of the run in the alternate screen of the terminal,
+// with the recent throughput, the current percentiles and the error rate of every
+// operation, every progressInterval.
+type dashboard struct {
+	start     time.Time
+	total     int64
+	intervals func(ps []float64) []measurement.IntervalStats
+
+	count   int64
+	history map[string][]float64
+}
+
+func newDashboard(total int64) *dashboard {
+	// switch to the alternate screen and hide the cursor
+	fmt.Print("\033[?1049h\033[?25l")
+	return &dashboard{
+		start:     time.Now(),
+		total:     total,
+		intervals: measurement.NewIntervals(),
+		history:   make(map[string][]float64),
+	}
+}
+
+func sparkline(values []float64) string {
+	max := float64(0)
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	var b strings.Builder
+	for i := len(values); i < dashboardHistory; i++ {
+		b.WriteRune(' ')
+	}
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(v / max * float64(len(sparkRunes)-1))
+		}
+		b.WriteRune(sparkRunes[i])
+	}
+	return b.String()
+}
+
+func (d *dashboard) output() {
+	stats := d.intervals(dashboardPercentiles)
+	byOp := make(map[string]measurement.IntervalStats, len(stats))
+	var throughput float64
+	var count, errors int64
+	for _, s := range stats {
+		if measurement.IsDerived(s.Op) {
+			continue
+		}
+		byOp[s.Op] = s
+		throughput += s.Throughput
+		count += s.Count
+		if strings.HasSuffix(s.Op, "_ERROR") {
+			errors += s.Count
+		}
+	}
+	d.count += count
+
+	elapsed := time.Since(d.start)
+	var b strings.Builder
+	// move to the top left corner and clear the screen
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "go-ycsb - Elapsed: %v", elapsed.Round(time.Second))
+	if d.total > 0 && d.count > 0 {
+		done := float64(d.count) / float64(d.total)
+		remaining := time.Duration(float64(elapsed)/done) - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		fmt.Fprintf(&b, ", Remaining: %v (%.1f%% done)", remaining.Round(time.Second), done*100)
+	}
+	fmt.Fprintf(&b, ", Operations: %d, OPS: %.1f, Errors: %.2f%%\n\n", d.count, throughput, percentOf(errors, count))
+
+	fmt.Fprintf(&b, "%-16s %10s  %-*s %10s %10s %10s %8s\n", "Operation", "OPS",
+		dashboardHistory, fmt.Sprintf("Throughput (last %v)", dashboardHistory*progressInterval),
+		"p50", "p99", "p99.9", "Errors")
+	for _, s := range stats {
+		if _, ok := byOp[s.Op]; !ok || strings.HasSuffix(s.Op, "_ERROR") {
+			continue
+		}
+		errorCount := byOp[s.Op+"_ERROR"].Count
+		history := append(d.history[s.Op], s.Throughput)
+		if len(history) > dashboardHistory {
+			history = history[1:]
+		}
+		d.history[s.Op] = history
+
+		fmt.Fprintf(&b, "%-16s %10.1f  %s", s.Op, s.Throughput, sparkline(history))
+		for _, p := range s.Percentiles {
+			fmt.Fprintf(&b, " %10v", time.Duration(p)*time.Microsecond)
+		}
+		fmt.Fprintf(&b, " %7.2f%%\n", percentOf(errorCount, s.Count+errorCount))
+	}
+	fmt.Print(b.String())
+}
+
+// percentOf returns n as a percentage of total.
+func percentOf(n int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// end restores the screen and the cursor, so that the summary is printed to the
+// terminal as usual.
+func (d *dashboard) end() {
+	fmt.Print("\033[?25h\033[?1049l")
+}
@@ -16,7 +16,9 @@ package client
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/go-ycsb/pkg/measurement"
@@ -28,14 +30,180 @@ type DbWrapper struct {
 	DB ycsb.DB
 }
 
-func measure(start time.Time, op string, err error) {
-	lan := time.Now().Sub(start)
-	if err != nil {
-		measurement.Measure(fmt.Sprintf("%s_ERROR", op), lan)
+// unwrap returns the DB wrapped by db if it is a DbWrapper, to check which optional
+// interfaces it implements, which DbWrapper always does.
+func unwrap(db ycsb.DB) ycsb.DB {
+	if w, wrapped := db.(DbWrapper); wrapped {
+		return w.DB
+	}
+	return db
+}
+
+// errNotSupported is returned by the optional interfaces of DbWrapper
+// when the wrapped DB doesn't implement them.
+var errNotSupported = errors.New("not supported by the DB")
+
+// intendedPrefix prefixes the operation names of the intended latencies, which are measured
+// from the time the operation was scheduled to start at when a target throughput is set.
+// Unlike the service latencies, they include the time an operation waited behind slow
+// ones, so they aren't flattered by coordinated omission.
+const intendedPrefix = "INTENDED_"
+
+// intendedStartKey is the context key of the scheduled start time of the current operation.
+type intendedStartKey struct{}
+
+// callTimeout is the deadline of every DB call, 0 for none. A retried operation has a
+// new deadline for every attempt.
+var callTimeout time.Duration
+
+// callStateKey is the context key of the callState of the current operation.
+type callStateKey struct{}
+
+// callState is the operation whose attempts run with call, and whether its last
+// attempt ran out of time, and with the adaptive deadline.
+type callState struct {
+	op       string
+	timedOut int32
+	adaptive int32
+}
+
+// call runs an attempt of an operation with the deadline of a DB call, the lower of
+// operation.timeout and the adaptive deadline of the operation if any, and flags the
+// operation as timed out if the attempt ran out of time.
+func call(ctx context.Context, fn func(ctx context.Context) error) error {
+	state, ok := ctx.Value(callStateKey{}).(*callState)
+	if !ok {
+		return fn(ctx)
+	}
+	timeout, adaptiveTimeout := callTimeout, false
+	if adaptive != nil {
+		if t := adaptive.timeout(state.op); t > 0 && (timeout <= 0 || t < timeout) {
+			timeout, adaptiveTimeout = t, true
+		}
+	}
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := fn(callCtx)
+	var flag, adaptiveFlag int32
+	if err != nil && callCtx.Err() == context.DeadlineExceeded {
+		flag = 1
+		if adaptiveTimeout {
+			adaptiveFlag = 1
+		}
+	}
+	atomic.StoreInt32(&state.timedOut, flag)
+	atomic.StoreInt32(&state.adaptive, adaptiveFlag)
+	if err == nil && adaptive != nil {
+		adaptive.observe(state.op, time.Since(start))
+	}
+	return err
+}
+
+// timedOut returns whether the last attempt of the operation ran out of time.
+func timedOut(ctx context.Context) bool {
+	state, ok := ctx.Value(callStateKey{}).(*callState)
+	return ok && atomic.LoadInt32(&state.timedOut) != 0
+}
+
+// adaptiveTimedOut returns whether the last attempt of the operation ran out of the
+// adaptive deadline.
+func adaptiveTimedOut(ctx context.Context) bool {
+	state, ok := ctx.Value(callStateKey{}).(*callState)
+	return ok && atomic.LoadInt32(&state.adaptive) != 0
+}
+
+// threadIDKey is the context key of the ID of the client thread running the operation.
+type threadIDKey struct{}
+
+// inTxKey is the context key marking the operations of a transaction, which the
+// concurrency limiter doesn't hold up since the transaction itself already holds a slot.
+type inTxKey struct{}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(inTxKey{}) != nil
+}
+
+func threadID(ctx context.Context) int {
+	if id, ok := ctx.Value(threadIDKey{}).(int); ok {
+		return id
+	}
+	return -1
+}
+
+// writtenBytes counts the logical bytes of keys and values written to the DB.
+var writtenBytes int64
+
+func recordWrite(key string, values map[string][]byte) {
+	n := len(key)
+	for field, value := range values {
+		n += len(field) + len(value)
+	}
+	atomic.AddInt64(&writtenBytes, int64(n))
+}
+
+// begin waits until the concurrency limiter, if any, lets an operation start, and
+// returns the context to run the operation with and its start time. The attempts of
+// the operation run with call, which bounds them by operation.timeout and the adaptive
+// deadline.
+func begin(ctx context.Context, op string, key string) (context.Context, time.Time) {
+	if limiter != nil && !inTx(ctx) {
+		limiter.acquire()
+	}
+	if callTimeout > 0 || adaptive != nil {
+		ctx = context.WithValue(ctx, callStateKey{}, &callState{op: op})
+	}
+	if tracer != nil {
+		ctx = tracer.start(ctx, op, key)
+	}
+	return ctx, measurement.Now()
+}
+
+func (db DbWrapper) measure(ctx context.Context, start time.Time, op string, table string, err error) {
+	now := measurement.Now()
+	lan := now.Sub(start)
+	if limiter != nil && !inTx(ctx) {
+		limiter.release(lan, err)
+	}
+	if tracer != nil {
+		tracer.end(ctx, err)
+	}
+	if err != nil && ctx.Err() == context.Canceled {
+		// the run was interrupted, the operation didn't fail on its own
 		return
 	}
+	if inTx(ctx) {
+		// the transaction is the operation, the operations it runs are only measured
+		// as its OP[tx] breakdown
+		if err != nil {
+			op += "_ERROR"
+		}
+		measurement.Measure(op+"[tx]", lan)
+		return
+	}
+	if budget != nil {
+		budget.record(err)
+	}
+	measurement.RecordSample(start, op, lan, err)
+	if err != nil {
+		attributeError(ctx, err)
+		measurement.MeasureError(db.classifyAttempt(ctx, err))
+		if timedOut(ctx) {
+			// the failed operations which ran out of time are also measured on their own
+			measurement.Measure(op+"_TIMEOUT", lan)
+		}
+		op = fmt.Sprintf("%s_ERROR", op)
+	}
 
 	measurement.Measure(op, lan)
+	measurement.MeasureBreakdown(ctx, op, table, threadID(ctx), lan)
+	if intendedStart, ok := ctx.Value(intendedStartKey{}).(*time.Time); ok {
+		measurement.Measure(intendedPrefix+op, now.Sub(*intendedStart))
+	}
 }
 
 func (db DbWrapper) ToSqlDB() *sql.DB {
@@ -54,63 +222,192 @@ func (db DbWrapper) CleanupThread(ctx context.Context) {
 	db.DB.CleanupThread(ctx)
 }
 
+// Read measures the read of a workload operation, such as the read of a deleted key,
+// as that operation, which expects that the record may not be found.
 func (db DbWrapper) Read(ctx context.Context, table string, key string, fields []string) (_ map[string][]byte, err error) {
-	start := time.Now()
+	recordOp(ctx, "read", table, key, 0)
+	op, named := measurement.OperationOf(ctx)
+	if !named {
+		op = "READ"
+	}
+	ctx, start := begin(ctx, op, key)
 	defer func() {
-		measure(start, "READ", err)
+		if named && errors.Is(err, ycsb.ErrNotFound) {
+			db.measure(ctx, start, op, table, nil)
+			return
+		}
+		db.measure(ctx, start, op, table, err)
 	}()
+	call := historyCall(ctx)
 
-	return db.DB.Read(ctx, table, key, fields)
+	// the reads of a transaction can't be sent twice concurrently on its connection
+	var values map[string][]byte
+	err = retry(ctx, db, op, func(ctx context.Context) (err error) {
+		if hedger != nil && !inTx(ctx) {
+			values, err = hedger.read(ctx, db.DB, table, key, fields)
+		} else {
+			values, err = db.DB.Read(ctx, table, key, fields)
+		}
+		return err
+	})
+	if corrupter != nil && err == nil {
+		values = corrupter.corruptRow(values)
+	}
+	historyRead(ctx, db, call, table, key, fields, values, err)
+	return values, err
 }
 
+// BatchRead, BatchUpdate, BatchInsert and BatchDelete run the batches of DBs which
+// can't batch as an operation per record, each measured, bounded and limited as such.
+// The reads of a workload operation, such as a multi-get, are measured together as
+// that operation either way.
 func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string, fields []string) (_ []map[string][]byte, err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
-	if ok {
-		start := time.Now()
-		defer func() {
-			measure(start, "BATCH_READ", err)
-		}()
-		return batchDB.BatchRead(ctx, table, keys, fields)
+	op, named := measurement.OperationOf(ctx)
+	if !ok && !named {
+		rows := make([]map[string][]byte, len(keys))
+		for i, key := range keys {
+			if rows[i], err = db.Read(ctx, table, key, fields); err != nil {
+				return nil, err
+			}
+		}
+		return rows, nil
+	}
+	if !named {
+		op = "BATCH_READ"
 	}
+
 	for _, key := range keys {
-		_, err := db.DB.Read(ctx, table, key, fields)
-		if err != nil {
-			return nil, err
+		recordOp(ctx, "read", table, key, 0)
+	}
+	ctx, start := begin(ctx, op, "")
+	defer func() {
+		db.measure(ctx, start, op, table, err)
+	}()
+	var rows []map[string][]byte
+	if !ok {
+		// the reads of the workload operation run one after the other
+		rows = make([]map[string][]byte, len(keys))
+		err = retry(ctx, db, op, func(ctx context.Context) error {
+			for i, key := range keys {
+				call := historyCall(ctx)
+				values, err := db.DB.Read(ctx, table, key, fields)
+				if corrupter != nil && err == nil {
+					values = corrupter.corruptRow(values)
+				}
+				historyRead(ctx, db, call, table, key, fields, values, err)
+				if err != nil {
+					return err
+				}
+				rows[i] = values
+			}
+			return nil
+		})
+		return rows, err
+	}
+
+	call := historyCall(ctx)
+	err = retry(ctx, db, op, func(ctx context.Context) (err error) {
+		rows, err = batchDB.BatchRead(ctx, table, keys, fields)
+		return err
+	})
+	if corrupter != nil && err == nil {
+		rows = corrupter.corruptRows(rows)
+	}
+	for i, key := range keys {
+		var row map[string][]byte
+		if err == nil && i < len(rows) {
+			row = rows[i]
 		}
+		historyRead(ctx, db, call, table, key, fields, row, err)
 	}
-	return nil, nil
+	return rows, err
 }
 
 func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (_ []map[string][]byte, err error) {
-	start := time.Now()
+	recordOp(ctx, "scan", table, startKey, int64(count))
+	ctx, start := begin(ctx, "SCAN", startKey)
 	defer func() {
-		measure(start, "SCAN", err)
+		db.measure(ctx, start, "SCAN", table, err)
 	}()
 
-	return db.DB.Scan(ctx, table, startKey, count, fields)
+	var rows []map[string][]byte
+	err = retry(ctx, db, "SCAN", func(ctx context.Context) (err error) {
+		rows, err = db.DB.Scan(ctx, table, startKey, count, fields)
+		return err
+	})
+	if corrupter != nil && err == nil {
+		rows = corrupter.corruptRows(rows)
+	}
+	return rows, err
 }
 
 func (db DbWrapper) Update(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
-	start := time.Now()
+	recordOp(ctx, "update", table, key, valuesSize(values))
+	ctx, start := begin(ctx, "UPDATE", key)
 	defer func() {
-		measure(start, "UPDATE", err)
+		db.measure(ctx, start, "UPDATE", table, err)
 	}()
+	recordWrite(key, values)
 
-	return db.DB.Update(ctx, table, key, values)
+	call := historyCall(ctx)
+	err = retry(ctx, db, "UPDATE", func(ctx context.Context) error {
+		return db.DB.Update(ctx, table, key, values)
+	})
+	historyWrite(ctx, call, "update", table, key, values, err)
+	return err
+}
+
+// CAS measures the updates which found other values than expected as CAS_CONFLICT,
+// rather than as errors, since they are the expected outcome of contention.
+func (db DbWrapper) CAS(ctx context.Context, table string, key string, expected map[string][]byte, values map[string][]byte) (err error) {
+	recordSkip()
+	historySkip(ctx)
+	casDB, ok := db.DB.(ycsb.CASDB)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, start := begin(ctx, "CAS", key)
+	defer func() {
+		atomic.AddInt64(&casAttempts, 1)
+		if errors.Is(err, ycsb.ErrConflict) {
+			atomic.AddInt64(&casConflicts, 1)
+			db.measure(ctx, start, "CAS_CONFLICT", table, nil)
+			return
+		}
+		if err == nil {
+			recordWrite(key, values)
+		}
+		db.measure(ctx, start, "CAS", table, err)
+	}()
+
+	return call(ctx, func(ctx context.Context) error {
+		return casDB.CAS(ctx, table, key, expected, values)
+	})
 }
 
 func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		for i := range keys {
+			recordOp(ctx, "update", table, keys[i], valuesSize(values[i]))
+			recordWrite(keys[i], values[i])
+		}
+		ctx, start := begin(ctx, "BATCH_UPDATE", "")
 		defer func() {
-			measure(start, "BATCH_UPDATE", err)
+			db.measure(ctx, start, "BATCH_UPDATE", table, err)
 		}()
-		return batchDB.BatchUpdate(ctx, table, keys, values)
+		call := historyCall(ctx)
+		err = retry(ctx, db, "BATCH_UPDATE", func(ctx context.Context) error {
+			return batchDB.BatchUpdate(ctx, table, keys, values)
+		})
+		for i := range keys {
+			historyWrite(ctx, call, "update", table, keys[i], values[i], err)
+		}
+		return err
 	}
 	for i := range keys {
-		err := db.DB.Update(ctx, table, keys[i], values[i])
-		if err != nil {
+		if err = db.Update(ctx, table, keys[i], values[i]); err != nil {
 			return err
 		}
 	}
@@ -118,26 +415,205 @@ func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string
 }
 
 func (db DbWrapper) Insert(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
-	start := time.Now()
+	recordOp(ctx, "insert", table, key, valuesSize(values))
+	ctx, start := begin(ctx, "INSERT", key)
 	defer func() {
-		measure(start, "INSERT", err)
+		db.measure(ctx, start, "INSERT", table, err)
 	}()
+	recordWrite(key, values)
 
-	return db.DB.Insert(ctx, table, key, values)
+	call := historyCall(ctx)
+	err = retry(ctx, db, "INSERT", func(ctx context.Context) error {
+		return db.DB.Insert(ctx, table, key, values)
+	})
+	historyWrite(ctx, call, "insert", table, key, values, err)
+	return err
+}
+
+// Increment measures the increments of DBs which can't increment counters as errors.
+func (db DbWrapper) Increment(ctx context.Context, table string, key string, field string, delta int64) (_ int64, err error) {
+	recordSkip()
+	historySkip(ctx)
+	ctx, start := begin(ctx, "INCREMENT", key)
+	defer func() {
+		db.measure(ctx, start, "INCREMENT", table, err)
+	}()
+	incrementDB, ok := db.DB.(ycsb.IncrementDB)
+	if !ok {
+		return 0, errNotSupported
+	}
+
+	var value int64
+	err = call(ctx, func(ctx context.Context) (err error) {
+		value, err = incrementDB.Increment(ctx, table, key, field, delta)
+		return err
+	})
+	return value, err
+}
+
+func (db DbWrapper) CreateIndex(ctx context.Context, table string, field string) error {
+	queryDB, ok := db.DB.(ycsb.QueryDB)
+	if !ok {
+		return errNotSupported
+	}
+
+	return queryDB.CreateIndex(ctx, table, field)
+}
+
+func (db DbWrapper) Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) (_ []map[string][]byte, err error) {
+	recordSkip()
+	ctx, start := begin(ctx, "QUERY", "")
+	defer func() {
+		db.measure(ctx, start, "QUERY", table, err)
+	}()
+	queryDB, ok := db.DB.(ycsb.QueryDB)
+	if !ok {
+		return nil, errNotSupported
+	}
+
+	var rows []map[string][]byte
+	err = call(ctx, func(ctx context.Context) (err error) {
+		rows, err = queryDB.Query(ctx, table, field, value, count, fields)
+		return err
+	})
+	return rows, err
+}
+
+func (db DbWrapper) Push(ctx context.Context, table string, queue string, value []byte) (err error) {
+	recordSkip()
+	ctx, start := begin(ctx, "PUSH", queue)
+	defer func() {
+		db.measure(ctx, start, "PUSH", table, err)
+	}()
+	queueDB, ok := db.DB.(ycsb.QueueDB)
+	if !ok {
+		return errNotSupported
+	}
+	atomic.AddInt64(&writtenBytes, int64(len(queue)+len(value)))
+
+	return call(ctx, func(ctx context.Context) error {
+		return queueDB.Push(ctx, table, queue, value)
+	})
+}
+
+func (db DbWrapper) Pop(ctx context.Context, table string, queue string) (_ []byte, err error) {
+	recordSkip()
+	ctx, start := begin(ctx, "POP", queue)
+	defer func() {
+		db.measure(ctx, start, "POP", table, err)
+	}()
+	queueDB, ok := db.DB.(ycsb.QueueDB)
+	if !ok {
+		return nil, errNotSupported
+	}
+
+	var value []byte
+	err = call(ctx, func(ctx context.Context) (err error) {
+		value, err = queueDB.Pop(ctx, table, queue)
+		return err
+	})
+	return value, err
+}
+
+// RunTx measures the transactions as TX, and their commits, from the end of their last
+// operation, as COMMIT. The transactions which aborted because of concurrent ones are
+// measured as TX_ABORT rather than as errors.
+func (db DbWrapper) RunTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	txDB, ok := db.DB.(ycsb.TransactionDB)
+	if !ok {
+		return errNotSupported
+	}
+	historySkip(ctx)
+	ctx, start := begin(ctx, "TX", "")
+	var commitStart time.Time
+	defer func() {
+		atomic.AddInt64(&txAttempts, 1)
+		if err != nil && db.ClassifyError(err) == ycsb.ErrorClassConflict {
+			atomic.AddInt64(&txAborts, 1)
+			db.measure(ctx, start, "TX_ABORT", "", nil)
+			return
+		}
+		if !commitStart.IsZero() {
+			op := "COMMIT"
+			if err != nil {
+				op = "COMMIT_ERROR"
+			}
+			measurement.Measure(op, measurement.Now().Sub(commitStart))
+		}
+		db.measure(ctx, start, "TX", "", err)
+	}()
+
+	return call(ctx, func(ctx context.Context) error {
+		return txDB.RunTx(ctx, func(ctx context.Context) error {
+			if err := fn(context.WithValue(ctx, inTxKey{}, true)); err != nil {
+				return err
+			}
+			commitStart = measurement.Now()
+			return nil
+		})
+	})
+}
+
+// InsertWithTTL and UpdateWithTTL measure the writes to DBs which can't expire records
+// as errors, so that a recordttl which has no effect doesn't go unnoticed.
+func (db DbWrapper) InsertWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) (err error) {
+	recordOp(ctx, "insert", table, key, valuesSize(values))
+	ctx, start := begin(ctx, "INSERT", key)
+	defer func() {
+		db.measure(ctx, start, "INSERT", table, err)
+	}()
+	ttlDB, ok := db.DB.(ycsb.TTLDB)
+	if !ok {
+		return errNotSupported
+	}
+	recordWrite(key, values)
+	historySkip(ctx)
+
+	return retry(ctx, db, "INSERT", func(ctx context.Context) error {
+		return ttlDB.InsertWithTTL(ctx, table, key, values, ttl)
+	})
+}
+
+func (db DbWrapper) UpdateWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) (err error) {
+	recordOp(ctx, "update", table, key, valuesSize(values))
+	ctx, start := begin(ctx, "UPDATE", key)
+	defer func() {
+		db.measure(ctx, start, "UPDATE", table, err)
+	}()
+	ttlDB, ok := db.DB.(ycsb.TTLDB)
+	if !ok {
+		return errNotSupported
+	}
+	recordWrite(key, values)
+	historySkip(ctx)
+
+	return retry(ctx, db, "UPDATE", func(ctx context.Context) error {
+		return ttlDB.UpdateWithTTL(ctx, table, key, values, ttl)
+	})
 }
 
 func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		for i := range keys {
+			recordOp(ctx, "insert", table, keys[i], valuesSize(values[i]))
+			recordWrite(keys[i], values[i])
+		}
+		ctx, start := begin(ctx, "BATCH_INSERT", "")
 		defer func() {
-			measure(start, "BATCH_INSERT", err)
+			db.measure(ctx, start, "BATCH_INSERT", table, err)
 		}()
-		return batchDB.BatchInsert(ctx, table, keys, values)
+		call := historyCall(ctx)
+		err = retry(ctx, db, "BATCH_INSERT", func(ctx context.Context) error {
+			return batchDB.BatchInsert(ctx, table, keys, values)
+		})
+		for i := range keys {
+			historyWrite(ctx, call, "insert", table, keys[i], values[i], err)
+		}
+		return err
 	}
 	for i := range keys {
-		err := db.DB.Insert(ctx, table, keys[i], values[i])
-		if err != nil {
+		if err = db.Insert(ctx, table, keys[i], values[i]); err != nil {
 			return err
 		}
 	}
@@ -145,35 +621,117 @@ func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string
 }
 
 func (db DbWrapper) Delete(ctx context.Context, table string, key string) (err error) {
-	start := time.Now()
+	recordOp(ctx, "delete", table, key, 0)
+	ctx, start := begin(ctx, "DELETE", key)
 	defer func() {
-		measure(start, "DELETE", err)
+		db.measure(ctx, start, "DELETE", table, err)
 	}()
 
-	return db.DB.Delete(ctx, table, key)
+	call := historyCall(ctx)
+	err = retry(ctx, db, "DELETE", func(ctx context.Context) error {
+		return db.DB.Delete(ctx, table, key)
+	})
+	historyWrite(ctx, call, "delete", table, key, nil, err)
+	return err
 }
 
 func (db DbWrapper) BatchDelete(ctx context.Context, table string, keys []string) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		for _, key := range keys {
+			recordOp(ctx, "delete", table, key, 0)
+		}
+		ctx, start := begin(ctx, "BATCH_DELETE", "")
 		defer func() {
-			measure(start, "BATCH_DELETE", err)
+			db.measure(ctx, start, "BATCH_DELETE", table, err)
 		}()
-		return batchDB.BatchDelete(ctx, table, keys)
+		call := historyCall(ctx)
+		err = retry(ctx, db, "BATCH_DELETE", func(ctx context.Context) error {
+			return batchDB.BatchDelete(ctx, table, keys)
+		})
+		for _, key := range keys {
+			historyWrite(ctx, call, "delete", table, key, nil, err)
+		}
+		return err
 	}
 	for _, key := range keys {
-		err := db.DB.Delete(ctx, table, key)
-		if err != nil {
+		if err = db.Delete(ctx, table, key); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func (db DbWrapper) StreamScan(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) (err error) {
+	streamDB, ok := db.DB.(ycsb.StreamScanDB)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, start := begin(ctx, "STREAM_SCAN", "")
+	defer func() {
+		db.measure(ctx, start, "STREAM_SCAN", table, err)
+	}()
+	return call(ctx, func(ctx context.Context) error {
+		return streamDB.StreamScan(ctx, table, fn)
+	})
+}
+
 func (db DbWrapper) Analyze(ctx context.Context, table string) error {
 	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
 		return analyzeDB.Analyze(ctx, table)
 	}
 	return nil
 }
+
+func (db DbWrapper) EstimateCost(ops map[string]int64) (float64, error) {
+	if costDB, ok := db.DB.(ycsb.CostDB); ok {
+		return costDB.EstimateCost(ops)
+	}
+	return 0, errNotSupported
+}
+
+func (db DbWrapper) StorageSize(ctx context.Context) (int64, error) {
+	if sizeDB, ok := db.DB.(ycsb.StorageSizeDB); ok {
+		return sizeDB.StorageSize(ctx)
+	}
+	return 0, errNotSupported
+}
+
+// classifyAttempt returns the class of the error of the last attempt of an operation,
+// a timeout if it ran out of time whatever error the DB returned.
+func (db DbWrapper) classifyAttempt(ctx context.Context, err error) string {
+	if timedOut(ctx) {
+		return ycsb.ErrorClassTimeout
+	}
+	return db.ClassifyError(err)
+}
+
+func (db DbWrapper) ClassifyError(err error) string {
+	if classifierDB, ok := db.DB.(ycsb.ErrorClassifierDB); ok {
+		if class := classifierDB.ClassifyError(err); class != "" {
+			return class
+		}
+	}
+	return ycsb.ClassifyError(err)
+}
+
+func (db DbWrapper) ExtendedStats() map[string]int64 {
+	if statsDB, ok := db.DB.(ycsb.ExtendedStatsDB); ok {
+		return statsDB.ExtendedStats()
+	}
+	return nil
+}
+
+func (db DbWrapper) Endpoints() []string {
+	if endpointDB, ok := db.DB.(ycsb.EndpointDB); ok {
+		return endpointDB.Endpoints()
+	}
+	return nil
+}
+
+func (db DbWrapper) Events() []ycsb.Event {
+	if eventDB, ok := db.DB.(ycsb.EventDB); ok {
+		return eventDB.Events()
+	}
+	return nil
+}
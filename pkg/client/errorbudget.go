@@ -0,0 +1,114 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// budget is the error budget of the run, nil if disabled.
+var budget *errorBudget
+
+// errorBudget stops the run once the share of the operations which failed over the
+// last window exceeds the budget, rather than letting a misconfigured DB fail for hours.
+type errorBudget struct {
+	limit  float64
+	window time.Duration
+	minOps int64
+
+	ops      int64
+	errors   int64
+	exceeded int32
+}
+
+func newErrorBudget(p *properties.Properties) (*errorBudget, error) {
+	limit := p.GetFloat64(prop.ErrorBudget, 0)
+	if limit <= 0 {
+		return nil, nil
+	}
+	if limit >= 1 {
+		return nil, fmt.Errorf("%s must be a fraction of the operations below 1", prop.ErrorBudget)
+	}
+	window, err := time.ParseDuration(p.GetString(prop.ErrorBudgetWindow, prop.ErrorBudgetWindowDefault))
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid %s", prop.ErrorBudgetWindow)
+	}
+	return &errorBudget{
+		limit:  limit,
+		window: window,
+		minOps: p.GetInt64(prop.ErrorBudgetMinOps, prop.ErrorBudgetMinOpsDefault),
+	}, nil
+}
+
+func (b *errorBudget) record(err error) {
+	atomic.AddInt64(&b.ops, 1)
+	if err != nil {
+		atomic.AddInt64(&b.errors, 1)
+	}
+}
+
+// watch checks the error rate over the window every second, and calls abort when it
+// exceeds the budget.
+func (b *errorBudget) watch(ctx context.Context, abort func()) {
+	step := time.Second
+	if b.window < step {
+		step = b.window
+	}
+	// counts holds the ops and errors counts of the steps of the last window, oldest first
+	type counts struct{ ops, errors int64 }
+	history := make([]counts, 0, int(b.window/step)+1)
+
+	t := time.NewTicker(step)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		now := counts{atomic.LoadInt64(&b.ops), atomic.LoadInt64(&b.errors)}
+		if len(history) == cap(history) {
+			history = append(history[:0], history[1:]...)
+		}
+		history = append(history, now)
+		// until a whole window has passed, the rate is over the run so far
+		var since counts
+		if len(history) == cap(history) {
+			since = history[0]
+		}
+
+		ops, errors := now.ops-since.ops, now.errors-since.errors
+		if ops < b.minOps || ops == 0 {
+			continue
+		}
+		if rate := float64(errors) / float64(ops); rate > b.limit {
+			fmt.Printf("Error budget exceeded - Errors: %d of %d operations (%.2f%%) over the last %s, budget: %.2f%%, stopping the run\n",
+				errors, ops, rate*100, b.window, b.limit*100)
+			atomic.StoreInt32(&b.exceeded, 1)
+			abort()
+			return
+		}
+	}
+}
+
+func (b *errorBudget) isExceeded() bool {
+	return atomic.LoadInt32(&b.exceeded) != 0
+}
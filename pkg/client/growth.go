@@ -0,0 +1,121 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// keyspaceGrower inserts new records at the rate which grows the keyspace by a
+// percentage of its size every growth interval, independently of the workers, so
+// the latencies of their load can be followed as the dataset grows.
+type keyspaceGrower struct {
+	workload ycsb.KeyspaceWorkload
+	db       ycsb.DB
+	sizeDB   ycsb.StorageSizeDB
+	growth   float64
+	interval time.Duration
+
+	initial  int64
+	inserted int64
+	failed   int64
+}
+
+func newKeyspaceGrower(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) (*keyspaceGrower, error) {
+	growth := p.GetFloat64(prop.KeyspaceGrowth, 0)
+	if growth <= 0 || !p.GetBool(prop.DoTransactions, true) {
+		return nil, nil
+	}
+	keyspaceWorkload, ok := workload.(ycsb.KeyspaceWorkload)
+	if !ok {
+		return nil, errors.New("the workload can't grow its keyspace")
+	}
+	interval, err := time.ParseDuration(p.GetString(prop.KeyspaceGrowthInterval, prop.KeyspaceGrowthIntervalDefault))
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid %s", prop.KeyspaceGrowthInterval)
+	}
+
+	g := &keyspaceGrower{
+		workload: keyspaceWorkload,
+		db:       db,
+		growth:   growth,
+		interval: interval,
+		initial:  keyspaceWorkload.KeyspaceSize(),
+	}
+	if sizeDB, ok := db.(ycsb.StorageSizeDB); ok {
+		if _, err := sizeDB.StorageSize(context.Background()); err == nil {
+			g.sizeDB = sizeDB
+		}
+	}
+	return g, nil
+}
+
+// run inserts records until the context is done. The rate follows the size of the
+// keyspace, so it grows exponentially, by the growth percentage every interval. The
+// grower is a thread of the workload of its own, but it shares the DB state of the
+// first worker, since the DBs may not take more threads than threadcount, e.g.
+// pgo-raftkv has a reply point per thread.
+func (g *keyspaceGrower) run(ctx context.Context, threadCount int, workload ycsb.Workload) {
+	ctx = workload.InitThread(ctx, threadCount, threadCount+1)
+	ctx = g.db.InitThread(ctx, 0, threadCount)
+	defer workload.CleanupThread(ctx)
+	defer g.db.CleanupThread(ctx)
+
+	next := time.Now()
+	for {
+		perSecond := float64(g.workload.KeyspaceSize()) * math.Log1p(g.growth/100) / g.interval.Seconds()
+		if perSecond > 0 {
+			next = next.Add(time.Duration(float64(time.Second) / perSecond))
+		} else {
+			next = next.Add(time.Second)
+		}
+		if d := time.Until(next); d > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d):
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		if err := g.workload.GrowKeyspace(ctx, g.db); err != nil {
+			atomic.AddInt64(&g.failed, 1)
+		} else {
+			atomic.AddInt64(&g.inserted, 1)
+		}
+	}
+}
+
+func (g *keyspaceGrower) output(ctx context.Context) {
+	size := g.workload.KeyspaceSize()
+	fmt.Printf("%-6s - Records: %d, Grown: %.1f%%, Inserted: %d, Failed: %d",
+		"KEYSPACE", size, float64(size-g.initial)/float64(g.initial)*100,
+		atomic.LoadInt64(&g.inserted), atomic.LoadInt64(&g.failed))
+	if g.sizeDB != nil {
+		if bytes, err := g.sizeDB.StorageSize(ctx); err == nil {
+			fmt.Printf(", Storage(bytes): %d", bytes)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// hedger is the read hedging used by DbWrapper, nil if disabled.
+var hedger *readHedger
+
+// readHedger issues a second attempt of a read which hasn't completed after the
+// hedge delay, and returns whichever attempt completes first.
+type readHedger struct {
+	percentile float64
+
+	sync.Mutex
+	delay  time.Duration
+	window *latencyWindow
+
+	reads     int64
+	hedges    int64
+	hedgeWins int64
+	// wasted counts the losing attempts which completed anyway, and cancelled those
+	// which were cancelled once the other attempt returned
+	wasted     int64
+	cancelled  int64
+	wastedTime int64
+}
+
+// newReadHedger returns the read hedging, nil if disabled. Both attempts of a read run
+// on the same thread state, so the DB must implement ycsb.ConcurrentDB.
+func newReadHedger(p *properties.Properties, db ycsb.DB) (*readHedger, error) {
+	delay := p.GetParsedDuration(prop.HedgeDelay, 0)
+	percentile := p.GetFloat64(prop.HedgePercentile, 0)
+	if delay <= 0 && percentile <= 0 {
+		return nil, nil
+	}
+	if _, ok := unwrap(db).(ycsb.ConcurrentDB); !ok {
+		return nil, fmt.Errorf("the DB doesn't support concurrent operations within a thread, which hedged reads need")
+	}
+	return &readHedger{
+		percentile: percentile,
+		delay:      delay,
+		window:     newLatencyWindow(percentile),
+	}, nil
+}
+
+func (h *readHedger) hedgeDelay() time.Duration {
+	h.Lock()
+	defer h.Unlock()
+	return h.delay
+}
+
+// observe records the latency of a first attempt, and updates the hedge delay from
+// the recent latencies if it tracks a percentile.
+func (h *readHedger) observe(latency time.Duration) {
+	if h.percentile <= 0 {
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	if delay, ok := h.window.observe(latency); ok {
+		h.delay = delay
+	}
+}
+
+type hedgeResult struct {
+	values  map[string][]byte
+	err     error
+	hedged  bool
+	latency time.Duration
+}
+
+func (h *readHedger) read(ctx context.Context, db ycsb.DB, table string, key string, fields []string) (map[string][]byte, error) {
+	atomic.AddInt64(&h.reads, 1)
+
+	// both attempts send to ch, which is buffered so that the losing one doesn't block,
+	// and run with their own context, so that the losing one is cancelled
+	ch := make(chan hedgeResult, 2)
+	attempt := func(ctx context.Context, hedged bool) {
+		start := time.Now()
+		values, err := db.Read(ctx, table, key, fields)
+		latency := time.Since(start)
+		if !hedged {
+			h.observe(latency)
+		}
+		ch <- hedgeResult{values: values, err: err, hedged: hedged, latency: latency}
+	}
+	firstCtx, cancelFirst := context.WithCancel(ctx)
+	defer cancelFirst()
+	go attempt(firstCtx, false)
+
+	// hedge only once the percentile has been estimated
+	delay := h.hedgeDelay()
+	if delay <= 0 {
+		r := <-ch
+		return r.values, r.err
+	}
+
+	timer := time.NewTimer(delay)
+	select {
+	case r := <-ch:
+		timer.Stop()
+		return r.values, r.err
+	case <-timer.C:
+	}
+
+	atomic.AddInt64(&h.hedges, 1)
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+	go attempt(hedgeCtx, true)
+	r := <-ch
+	if r.hedged {
+		atomic.AddInt64(&h.hedgeWins, 1)
+	}
+	go func() {
+		// the loser is cancelled on return, and only fails with it if it was still running
+		loser := <-ch
+		if errors.Is(loser.err, context.Canceled) && ctx.Err() == nil {
+			atomic.AddInt64(&h.cancelled, 1)
+		} else {
+			atomic.AddInt64(&h.wasted, 1)
+		}
+		atomic.AddInt64(&h.wastedTime, int64(loser.latency))
+	}()
+	return r.values, r.err
+}
+
+func (h *readHedger) output() {
+	reads := atomic.LoadInt64(&h.reads)
+	hedges := atomic.LoadInt64(&h.hedges)
+	rate := float64(0)
+	if reads > 0 {
+		rate = float64(hedges) / float64(reads) * 100
+	}
+	fmt.Printf("Hedged reads - Reads: %d, Hedged: %d (%.2f%%), Hedge wins: %d, Wasted attempts: %d, Cancelled attempts: %d, Wasted time(us): %d, Delay(us): %d\n",
+		reads, hedges, rate, atomic.LoadInt64(&h.hedgeWins), atomic.LoadInt64(&h.wasted), atomic.LoadInt64(&h.cancelled),
+		time.Duration(atomic.LoadInt64(&h.wastedTime))/time.Microsecond, h.hedgeDelay()/time.Microsecond)
+}
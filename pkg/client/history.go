@@ -0,0 +1,250 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// historian is the history recording used by DbWrapper, nil if disabled.
+var historian *historyRecorder
+
+// historyRecorder records the reads and writes of the run with the times they were
+// called and returned at, as a history a linearizability checker such as Porcupine
+// can check.
+type historyRecorder struct {
+	start time.Time
+	// checkTimeout bounds the check of every record, 0 if the history isn't checked
+	checkTimeout time.Duration
+	// edn is the file the history is exported to as a Jepsen history, if any
+	edn string
+
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	e    *json.Encoder
+	// err is the first error writing the history, after which nothing is written
+	err      error
+	recorded int64
+	// skipped counts the writes the history can't represent, e.g. CAS
+	skipped int64
+}
+
+func newHistoryRecorder(p *properties.Properties, db ycsb.DB) (*historyRecorder, error) {
+	name := p.GetString(prop.HistoryFile, "")
+	edn := p.GetString(prop.HistoryEDN, "")
+	if name == "" {
+		if p.GetBool(prop.HistoryCheck, false) {
+			return nil, fmt.Errorf("%s needs a %s", prop.HistoryCheck, prop.HistoryFile)
+		}
+		if edn != "" {
+			return nil, fmt.Errorf("%s needs a %s", prop.HistoryEDN, prop.HistoryFile)
+		}
+		return nil, nil
+	}
+	if valuelessDB, ok := unwrap(db).(ycsb.ValuelessDB); ok && valuelessDB.Valueless() {
+		// the reads would look like they missed every write
+		return nil, fmt.Errorf("the DB reads the records back without their values, e.g. with ycsb.useints or a pgo-raftkv.payloadmode other than full")
+	}
+	var checkTimeout time.Duration
+	if p.GetBool(prop.HistoryCheck, false) {
+		var err error
+		checkTimeout, err = time.ParseDuration(p.GetString(prop.HistoryCheckTimeout, prop.HistoryCheckTimeoutDefault))
+		if err != nil || checkTimeout <= 0 {
+			return nil, fmt.Errorf("invalid %s", prop.HistoryCheckTimeout)
+		}
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	return &historyRecorder{start: time.Now(), checkTimeout: checkTimeout, edn: edn, file: f, w: w, e: json.NewEncoder(w)}, nil
+}
+
+// historyCall returns the call time of an operation to record, or -1 if it isn't
+// recorded. The operations of transactions aren't recorded on their own.
+func historyCall(ctx context.Context) int64 {
+	if historian == nil || inTx(ctx) {
+		return -1
+	}
+	return int64(time.Since(historian.start))
+}
+
+func (h *historyRecorder) record(ctx context.Context, call int64, op util.HistoryOperation, err error) {
+	op.ClientID = threadID(ctx)
+	op.Call = call
+	op.Return = int64(time.Since(h.start))
+	if err != nil {
+		op.Output.Error = err.Error()
+		if op.Input.Op != "read" {
+			// the write may or may not have taken effect
+			op.Return = util.HistoryUnknown
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return
+	}
+	if h.err = h.e.Encode(op); h.err == nil {
+		h.recorded++
+	}
+}
+
+func historyFingerprints(values map[string][]byte) map[string]string {
+	fingerprints := make(map[string]string, len(values))
+	for field, value := range values {
+		fingerprints[field] = util.HistoryFingerprint(value)
+	}
+	return fingerprints
+}
+
+// historyRead records the read called at call, if it is recorded. A read which didn't
+// fail and returned no fields didn't find the record.
+func historyRead(ctx context.Context, db DbWrapper, call int64, table string, key string, fields []string, values map[string][]byte, err error) {
+	if call < 0 || err == errNotSupported {
+		return
+	}
+	op := util.HistoryOperation{Input: util.HistoryInput{Op: "read", Table: table, Key: key, Fields: fields}}
+	if err != nil && db.ClassifyError(err) == ycsb.ErrorClassNotFound {
+		err = nil
+	} else if err == nil && len(values) > 0 {
+		op.Output.Found = true
+		op.Output.Values = historyFingerprints(values)
+	}
+	historian.record(ctx, call, op, err)
+}
+
+// historyWrite records the "insert", "update" or "delete" called at call, if it is recorded.
+func historyWrite(ctx context.Context, call int64, kind string, table string, key string, values map[string][]byte, err error) {
+	if call < 0 || err == errNotSupported {
+		return
+	}
+	op := util.HistoryOperation{Input: util.HistoryInput{Op: kind, Table: table, Key: key}}
+	if values != nil {
+		op.Input.Values = historyFingerprints(values)
+	}
+	historian.record(ctx, call, op, err)
+}
+
+// historySkip counts a write the history can't represent, if it is recorded.
+func historySkip(ctx context.Context) {
+	if historian == nil || inTx(ctx) {
+		return
+	}
+	historian.mu.Lock()
+	historian.skipped++
+	historian.mu.Unlock()
+}
+
+// close flushes the history. No operation may be recorded after it is called.
+func (h *historyRecorder) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err == nil {
+		h.err = h.w.Flush()
+	}
+	if err := h.file.Close(); h.err == nil {
+		h.err = err
+	}
+}
+
+func (h *historyRecorder) output() {
+	fmt.Printf("History - Recorded: %d, Unrecorded writes: %d, File: %s\n", h.recorded, h.skipped, h.file.Name())
+	if h.err != nil {
+		fmt.Printf("History - Record failed: %v\n", h.err)
+	}
+	if h.edn != "" && h.err == nil {
+		if deletes, err := h.exportEDN(); err != nil {
+			fmt.Printf("History - Export EDN failed: %v\n", err)
+		} else {
+			fmt.Printf("History - Exported EDN: %s, Deletes left out: %d\n", h.edn, deletes)
+		}
+	}
+}
+
+// exportEDN exports the closed history to the edn file, returning how many deletes it
+// left out.
+func (h *historyRecorder) exportEDN() (int, error) {
+	ops, err := readHistoryFile(h.file.Name())
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Create(h.edn)
+	if err != nil {
+		return 0, err
+	}
+	deletes, err := util.WriteHistoryEDN(f, ops)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return deletes, err
+}
+
+func readHistoryFile(name string) ([]util.HistoryOperation, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return util.ReadHistory(f)
+}
+
+// maxViolations is the number of records whose violations are printed.
+const maxViolations = 10
+
+// CheckHistory checks that the history recorded by the last run is linearizable if
+// history.check is set, and returns false if it isn't or couldn't be read.
+func CheckHistory() bool {
+	if historian == nil || historian.checkTimeout == 0 {
+		return true
+	}
+	ops, err := readHistoryFile(historian.file.Name())
+	if err != nil {
+		fmt.Printf("Check history failed: %v\n", err)
+		return false
+	}
+
+	start := time.Now()
+	res := util.CheckLinearizability(ops, historian.checkTimeout)
+	fmt.Printf("LINEARIZABILITY - Records: %d, Operations: %d, Violations: %d, Unknown: %d, Takes(s): %.1f\n",
+		res.Keys, res.Operations, len(res.Violations), len(res.Unknown), time.Since(start).Seconds())
+	for i, key := range res.Violations {
+		if i == maxViolations {
+			fmt.Printf("LINEARIZABILITY - ... and %d more\n", len(res.Violations)-maxViolations)
+			break
+		}
+		fmt.Printf("LINEARIZABILITY - The operations of %s aren't linearizable\n", key)
+	}
+	if len(res.Unknown) > 0 {
+		fmt.Printf("LINEARIZABILITY - The check of %d records ran out of %s\n", len(res.Unknown), prop.HistoryCheckTimeout)
+	}
+	if len(res.Violations) > 0 && historian.skipped > 0 {
+		fmt.Printf("LINEARIZABILITY - The history is missing %d writes it can't represent, which may explain the violations\n", historian.skipped)
+	}
+	return len(res.Violations) == 0
+}
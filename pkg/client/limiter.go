@@ -0,0 +1,184 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// limiter is the adaptive concurrency limiter used by DbWrapper, nil if disabled.
+var limiter *concurrencyLimiter
+
+// limitAlgorithm computes the next concurrency limit from a latency sample.
+type limitAlgorithm interface {
+	update(limit float64, rtt time.Duration, dropped bool) float64
+}
+
+// gradientLimit follows Netflix's Gradient2 limit: it compares a short term RTT
+// to a long term average, and shrinks the limit as the ratio shows queueing.
+type gradientLimit struct {
+	longRTT float64
+}
+
+const (
+	gradientWindow    = 600
+	gradientTolerance = 1.5
+	gradientSmoothing = 0.2
+)
+
+func (g *gradientLimit) update(limit float64, rtt time.Duration, dropped bool) float64 {
+	shortRTT := float64(rtt)
+	if g.longRTT == 0 {
+		g.longRTT = shortRTT
+	} else {
+		g.longRTT += (shortRTT - g.longRTT) * 2 / (gradientWindow + 1)
+	}
+	// let the long term RTT recover quickly after a latency spike
+	if g.longRTT/shortRTT > 2 {
+		g.longRTT *= 0.95
+	}
+
+	gradient := math.Max(0.5, math.Min(1, gradientTolerance*g.longRTT/shortRTT))
+	if dropped {
+		gradient = 0.5
+	}
+	newLimit := limit*gradient + math.Sqrt(limit)
+	return limit*(1-gradientSmoothing) + newLimit*gradientSmoothing
+}
+
+// vegasLimit follows TCP Vegas: it estimates the queue size from the ratio of the
+// RTT to the lowest RTT seen, and grows the limit while the queue stays small.
+type vegasLimit struct {
+	noLoadRTT time.Duration
+}
+
+func (v *vegasLimit) update(limit float64, rtt time.Duration, dropped bool) float64 {
+	if v.noLoadRTT == 0 || rtt < v.noLoadRTT {
+		v.noLoadRTT = rtt
+	}
+	step := math.Max(1, math.Log10(limit))
+	if dropped {
+		return limit - step
+	}
+
+	queue := math.Ceil(limit * (1 - float64(v.noLoadRTT)/float64(rtt)))
+	switch {
+	case queue <= step:
+		return limit + 6*step
+	case queue < 3*step:
+		return limit + step
+	case queue > 6*step:
+		return limit - step
+	}
+	return limit
+}
+
+// concurrencyLimiter bounds the number of operations in flight, and adjusts the
+// bound from the observed latencies.
+type concurrencyLimiter struct {
+	sync.Mutex
+	cond      *sync.Cond
+	algorithm limitAlgorithm
+	name      string
+
+	limit    float64
+	minLimit float64
+	maxLimit float64
+	inFlight int
+
+	// the limit over time, sampled on every report
+	samples []float64
+}
+
+func newConcurrencyLimiter(p *properties.Properties) (*concurrencyLimiter, error) {
+	name := strings.ToLower(p.GetString(prop.LimiterAlgorithm, ""))
+	var algorithm limitAlgorithm
+	switch name {
+	case "":
+		return nil, nil
+	case "gradient":
+		algorithm = new(gradientLimit)
+	case "vegas":
+		algorithm = new(vegasLimit)
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting gradient or vegas", prop.LimiterAlgorithm, name)
+	}
+
+	maxLimit := p.GetInt(prop.LimiterMaxLimit, p.GetInt(prop.ThreadCount, 1))
+	l := &concurrencyLimiter{
+		algorithm: algorithm,
+		name:      name,
+		limit:     float64(p.GetInt(prop.LimiterInitialLimit, prop.LimiterInitialLimitDefault)),
+		minLimit:  float64(p.GetInt(prop.LimiterMinLimit, prop.LimiterMinLimitDefault)),
+		maxLimit:  float64(maxLimit),
+	}
+	if l.minLimit < 1 || l.minLimit > l.maxLimit {
+		return nil, fmt.Errorf("%s must be between 1 and %s", prop.LimiterMinLimit, prop.LimiterMaxLimit)
+	}
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, l.limit))
+	l.cond = sync.NewCond(l)
+	return l, nil
+}
+
+// acquire blocks until an operation may start.
+func (l *concurrencyLimiter) acquire() {
+	l.Lock()
+	for l.inFlight >= int(l.limit) {
+		l.cond.Wait()
+	}
+	l.inFlight++
+	l.Unlock()
+}
+
+// release ends an operation, and feeds its latency to the limit algorithm.
+func (l *concurrencyLimiter) release(rtt time.Duration, err error) {
+	l.Lock()
+	// only adjust while the limit is being used, otherwise the
+	// latencies say nothing about the concurrency the DB can take
+	if float64(l.inFlight)*2 >= l.limit && rtt > 0 {
+		limit := l.algorithm.update(l.limit, rtt, err != nil)
+		l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, limit))
+	}
+	l.inFlight--
+	l.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *concurrencyLimiter) output() {
+	l.Lock()
+	limit := int(l.limit)
+	inFlight := l.inFlight
+	l.samples = append(l.samples, l.limit)
+	l.Unlock()
+
+	fmt.Printf("%-6s - Algorithm: %s, Limit: %d, InFlight: %d\n", "LIMIT", l.name, limit, inFlight)
+}
+
+func (l *concurrencyLimiter) summary() {
+	l.Lock()
+	defer l.Unlock()
+
+	samples := make([]string, 0, len(l.samples))
+	for _, sample := range l.samples {
+		samples = append(samples, fmt.Sprintf("%d", int(sample)))
+	}
+	fmt.Printf("Concurrency limit(%s) over time: [%s], final: %d\n", l.name, strings.Join(samples, " "), int(l.limit))
+}
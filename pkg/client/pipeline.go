@@ -0,0 +1,162 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// pipelineInflight returns the number of operations every thread keeps in flight,
+// 1 if the threads wait for every operation to complete before the next one.
+func pipelineInflight(p *properties.Properties) (int, error) {
+	inflight := p.GetInt(prop.PipelineInflight, prop.PipelineInflightDefault)
+	if inflight <= 0 {
+		return 0, fmt.Errorf("%s must be positive", prop.PipelineInflight)
+	}
+	if inflight == 1 {
+		return 1, nil
+	}
+	if p.GetBool(prop.OpenLoop, prop.OpenLoopDefault) {
+		return 0, fmt.Errorf("%s doesn't apply to %s, where the threads don't issue their own operations", prop.PipelineInflight, prop.OpenLoop)
+	}
+	if p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault) != 0 {
+		// the workload seeds the state of a thread from its ID, shared by its slots
+		return 0, fmt.Errorf("%s can't be used with %s, as the slots of a thread would repeat the same operations", prop.PipelineInflight, prop.RandomSeed)
+	}
+	return inflight, nil
+}
+
+// The operations submitted to the pipelines, and how long the threads waited for a
+// free slot since the first submission.
+var (
+	pipelineStart     int64
+	pipelineSubmitted int64
+	pipelineBlocked   int64
+	pipelineWaitTime  int64
+)
+
+// pipeline lets a thread submit its operations without waiting for the previous ones
+// to complete. Every slot has its own workload and DB thread state, like a thread of
+// its own.
+type pipeline struct {
+	// free holds the contexts of the slots which aren't running an operation
+	free  chan context.Context
+	slots []context.Context
+	// done is set once the workload is done
+	done int32
+}
+
+func newPipeline(ctx context.Context, inflight int, initSlot func(ctx context.Context) context.Context) *pipeline {
+	atomic.CompareAndSwapInt64(&pipelineStart, 0, time.Now().UnixNano())
+	p := &pipeline{free: make(chan context.Context, inflight)}
+	for i := 0; i < inflight; i++ {
+		slot := initSlot(ctx)
+		p.slots = append(p.slots, slot)
+		p.free <- slot
+	}
+	return p
+}
+
+// acquire waits for a free slot, and returns false if ctx is done in the meantime.
+func (p *pipeline) acquire(ctx context.Context) (context.Context, bool) {
+	select {
+	case slot := <-p.free:
+		return slot, true
+	default:
+	}
+
+	start := time.Now()
+	atomic.AddInt64(&pipelineBlocked, 1)
+	defer func() {
+		atomic.AddInt64(&pipelineWaitTime, int64(time.Since(start)))
+	}()
+	select {
+	case slot := <-p.free:
+		return slot, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// close waits for the operations in flight to complete, and cleans the slots up.
+func (p *pipeline) close(cleanupSlot func(ctx context.Context)) {
+	for range p.slots {
+		<-p.free
+	}
+	for _, slot := range p.slots {
+		cleanupSlot(slot)
+	}
+}
+
+// submit starts the next operation of the thread on a free slot of its pipeline,
+// waiting for one if they are all in flight, and returns the number of operations it
+// covers. It returns false if ctx is done before a slot is free.
+func (w *worker) submit(ctx context.Context, intendedStart *time.Time) (int, bool) {
+	slot, ok := w.pipeline.acquire(ctx)
+	if !ok {
+		return 0, false
+	}
+	if atomic.LoadInt32(&w.pipeline.done) != 0 {
+		w.pipeline.free <- slot
+		w.done = true
+		return 0, true
+	}
+
+	opCtx := slot
+	if intendedStart != nil {
+		// the operations in flight have their own intended start
+		opStart := *intendedStart
+		opCtx = context.WithValue(opCtx, intendedStartKey{}, &opStart)
+	}
+	atomic.AddInt64(&pipelineSubmitted, 1)
+	go func() {
+		defer func() {
+			w.pipeline.free <- slot
+		}()
+		if _, err := w.execute(opCtx); errors.Is(err, ycsb.ErrWorkloadDone) {
+			atomic.StoreInt32(&w.pipeline.done, 1)
+		}
+	}()
+
+	if w.doBatch {
+		return w.batchSize, true
+	}
+	return 1, true
+}
+
+// outputPipeline prints the rate the operations were submitted at, which the latencies
+// of their completions don't show, and how often the threads waited for a free slot.
+func outputPipeline() {
+	submitted := atomic.LoadInt64(&pipelineSubmitted)
+	start := atomic.LoadInt64(&pipelineStart)
+	if submitted == 0 || start == 0 {
+		return
+	}
+	blocked := atomic.LoadInt64(&pipelineBlocked)
+	elapsed := time.Since(time.Unix(0, start))
+	avgWait := time.Duration(0)
+	if blocked > 0 {
+		avgWait = time.Duration(atomic.LoadInt64(&pipelineWaitTime) / blocked)
+	}
+	fmt.Printf("Pipeline - Submitted: %d, Submission rate(ops/s): %.1f, Waits for a free slot: %d (%.2f%%), Avg wait(us): %d\n",
+		submitted, float64(submitted)/elapsed.Seconds(), blocked, percentOf(blocked, submitted), avgWait/time.Microsecond)
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+)
+
+// Output modes, selected by the outputmode property.
+const (
+	// OutputNormal prints the measurements every measurement.interval.
+	OutputNormal = "normal"
+	// OutputQuiet only prints the summary at the end of the run.
+	OutputQuiet = "quiet"
+	// OutputProgress keeps a single line with the progress of the run up to date.
+	OutputProgress = "progress"
+	// OutputDashboard redraws a live dashboard of the run in the terminal.
+	OutputDashboard = "dashboard"
+)
+
+// progressInterval is how often the progress line is refreshed.
+const progressInterval = time.Second
+
+// progressLine prints the progress of the run over itself.
+type progressLine struct {
+	start     time.Time
+	total     int64
+	prevTime  time.Time
+	prevCount int64
+}
+
+func newProgressLine(total int64) *progressLine {
+	now := time.Now()
+	return &progressLine{start: now, total: total, prevTime: now}
+}
+
+func (l *progressLine) output() {
+	var count, errors int64
+	for op, info := range measurement.Info() {
+		if measurement.IsDerived(op) {
+			continue
+		}
+		n, _ := info.Get(measurement.COUNT).(int64)
+		count += n
+		if strings.HasSuffix(op, "_ERROR") {
+			errors += n
+		}
+	}
+
+	now := time.Now()
+	throughput := float64(count-l.prevCount) / now.Sub(l.prevTime).Seconds()
+	l.prevTime, l.prevCount = now, count
+
+	done := ""
+	if l.total > 0 {
+		done = fmt.Sprintf(" (%.1f%%)", float64(count)/float64(l.total)*100)
+	}
+	// \033[K clears what is left of a longer previous line
+	fmt.Printf("\r%v elapsed, %d operations%s, %.1f OPS, %d errors\033[K",
+		now.Sub(l.start).Round(time.Second), count, done, throughput, errors)
+}
+
+// end prints the progress line with the throughput of the whole run, and moves past
+// it so that the summary starts on its own line.
+func (l *progressLine) end() {
+	l.prevTime, l.prevCount = l.start, 0
+	l.output()
+	fmt.Println()
+}
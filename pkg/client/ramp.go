@@ -0,0 +1,72 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// threadRamp starts the threads in steps spread over the ramp duration, so that a
+// cold DB isn't flooded by all of them at once.
+type threadRamp struct {
+	duration    time.Duration
+	steps       int
+	threadCount int
+}
+
+// newThreadRamp returns nil if the threads all start at once.
+func newThreadRamp(p *properties.Properties, threadCount int) (*threadRamp, error) {
+	duration, err := time.ParseDuration(p.GetString(prop.ThreadRamp, prop.ThreadRampDefault))
+	if err != nil || duration < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.ThreadRamp)
+	}
+	if duration == 0 || threadCount < 2 {
+		return nil, nil
+	}
+
+	steps := p.GetInt(prop.ThreadRampSteps, threadCount)
+	if steps < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.ThreadRampSteps)
+	}
+	if steps > threadCount {
+		steps = threadCount
+	}
+	return &threadRamp{duration: duration, steps: steps, threadCount: threadCount}, nil
+}
+
+// delay returns how long after the first threads the thread starts.
+func (r *threadRamp) delay(threadID int) time.Duration {
+	step := threadID * r.steps / r.threadCount
+	return r.duration * time.Duration(step) / time.Duration(r.steps)
+}
+
+// wait waits until the thread may start, and returns false if the run was stopped
+// in the meantime.
+func (r *threadRamp) wait(ctx context.Context, threadID int) bool {
+	d := r.delay(threadID)
+	if d == 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// recorder is the trace recording used by DbWrapper, nil if disabled.
+var recorder *traceRecorder
+
+// traceRecorder records the operations of the run to a trace, with the time they
+// were scheduled at, so that the trace workload can replay them later.
+type traceRecorder struct {
+	start time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	w    *util.TraceWriter
+	// err is the first error writing the trace, after which nothing is written
+	err      error
+	recorded int64
+	// skipped counts the operations the traces can't represent, e.g. CAS
+	skipped int64
+}
+
+func newTraceRecorder(p *properties.Properties) (*traceRecorder, error) {
+	name := p.GetString(prop.TraceRecord, "")
+	if name == "" {
+		return nil, nil
+	}
+	var binary bool
+	switch format := p.GetString(prop.TraceRecordFormat, prop.TraceRecordFormatDefault); format {
+	case "csv":
+	case "binary":
+		binary = true
+	default:
+		return nil, fmt.Errorf("unknown %s %q", prop.TraceRecordFormat, format)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	w, err := util.NewTraceWriter(f, binary)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &traceRecorder{start: time.Now(), file: f, w: w}, nil
+}
+
+// record records the operation at the time it was scheduled at with a target
+// throughput, or else now.
+func (r *traceRecorder) record(ctx context.Context, op string, table string, key string, size int64) {
+	at := time.Now()
+	if intendedStart, ok := ctx.Value(intendedStartKey{}).(*time.Time); ok {
+		at = *intendedStart
+	}
+	record := util.TraceRecord{
+		Op:        op,
+		Table:     table,
+		Key:       key,
+		Size:      size,
+		Timestamp: int64(at.Sub(r.start) / time.Microsecond),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if r.err = r.w.Write(record); r.err == nil {
+		r.recorded++
+	}
+}
+
+func (r *traceRecorder) skip() {
+	r.mu.Lock()
+	r.skipped++
+	r.mu.Unlock()
+}
+
+// close flushes the trace. No operation may be recorded after it is called.
+func (r *traceRecorder) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if err := r.file.Close(); r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *traceRecorder) output() {
+	fmt.Printf("Trace - Recorded: %d, Skipped: %d, File: %s\n", r.recorded, r.skipped, r.file.Name())
+	if r.err != nil {
+		fmt.Printf("Trace - Record failed: %v\n", r.err)
+	}
+}
+
+// recordOp records the operation if the recording is enabled.
+func recordOp(ctx context.Context, op string, table string, key string, size int64) {
+	if recorder != nil {
+		recorder.record(ctx, op, table, key, size)
+	}
+}
+
+// recordSkip counts an operation the trace can't represent, if the recording is enabled.
+func recordSkip() {
+	if recorder != nil {
+		recorder.skip()
+	}
+}
+
+func valuesSize(values map[string][]byte) int64 {
+	var n int64
+	for _, value := range values {
+		n += int64(len(value))
+	}
+	return n
+}
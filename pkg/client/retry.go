@@ -0,0 +1,129 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// retrier is the retry policy used by DbWrapper, nil if disabled.
+var retrier *retryPolicy
+
+// retryPolicy retries the failed operations whose errors are of the given classes,
+// the same way for every DB, with an exponential backoff between the attempts.
+type retryPolicy struct {
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	// classes are the error classes to retry on
+	classes map[string]bool
+
+	retried int64
+	gaveUp  int64
+}
+
+func newRetryPolicy(p *properties.Properties) (*retryPolicy, error) {
+	retries := p.GetInt(prop.OperationRetries, 0)
+	if retries <= 0 {
+		return nil, nil
+	}
+
+	r := &retryPolicy{retries: retries, classes: make(map[string]bool)}
+	var err error
+	if r.backoff, err = time.ParseDuration(p.GetString(prop.OperationBackoff, prop.OperationBackoffDefault)); err != nil || r.backoff < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.OperationBackoff)
+	}
+	if r.maxBackoff, err = time.ParseDuration(p.GetString(prop.OperationBackoffMax, prop.OperationBackoffMaxDefault)); err != nil || r.maxBackoff < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.OperationBackoffMax)
+	}
+	for _, class := range strings.Split(p.GetString(prop.OperationRetryOn, prop.OperationRetryOnDefault), ",") {
+		class = strings.TrimSpace(class)
+		switch class {
+		case ycsb.ErrorClassTimeout, ycsb.ErrorClassNotFound, ycsb.ErrorClassConflict, ycsb.ErrorClassOther:
+			r.classes[class] = true
+		default:
+			return nil, fmt.Errorf("unknown %s %q; expecting timeout, not-found, conflict or other", prop.OperationRetryOn, class)
+		}
+	}
+	return r, nil
+}
+
+// wait sleeps for the backoff before the given retry, doubled from the first one up to
+// the maximum, with jitter so that the threads failing together don't retry together.
+// It returns false if ctx is done in the meantime.
+func (r *retryPolicy) wait(ctx context.Context, retry int) bool {
+	backoff := r.backoff
+	for i := 1; i < retry && (r.maxBackoff <= 0 || backoff < r.maxBackoff); i++ {
+		backoff *= 2
+	}
+	if r.maxBackoff > 0 && backoff > r.maxBackoff {
+		backoff = r.maxBackoff
+	}
+	if backoff <= 0 {
+		return ctx.Err() == nil
+	}
+	backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+	t := time.NewTimer(backoff)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// retry runs fn until it succeeds, fails with an error the policy doesn't retry on,
+// or runs out of retries, and measures the latency of every failed attempt which is
+// retried as op_RETRY. Every attempt runs with call, so that an attempt which ran out
+// of time is retried with a deadline of its own. The operations of transactions
+// aren't retried on their own.
+func retry(ctx context.Context, db DbWrapper, op string, fn func(ctx context.Context) error) error {
+	if retrier == nil || inTx(ctx) {
+		return call(ctx, fn)
+	}
+
+	for i := 0; ; i++ {
+		start := measurement.Now()
+		err := call(ctx, fn)
+		if err == nil || err == errNotSupported || ctx.Err() != nil || !retrier.classes[db.classifyAttempt(ctx, err)] {
+			return err
+		}
+		if i == retrier.retries {
+			atomic.AddInt64(&retrier.gaveUp, 1)
+			return err
+		}
+		measurement.Measure(op+"_RETRY", measurement.Now().Sub(start))
+		atomic.AddInt64(&retrier.retried, 1)
+		if !retrier.wait(ctx, i+1) {
+			return err
+		}
+	}
+}
+
+func (r *retryPolicy) output() {
+	fmt.Printf("Retries - Retried attempts: %d, Given up operations: %d\n",
+		atomic.LoadInt64(&r.retried), atomic.LoadInt64(&r.gaveUp))
+}
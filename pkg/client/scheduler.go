@@ -0,0 +1,326 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// Shed policies of the open loop backlog.
+const (
+	shedDropNewest = "drop-newest"
+	shedDropOldest = "drop-oldest"
+)
+
+// arrival is an operation generated by the open loop scheduler.
+type arrival struct {
+	class int
+	time  time.Time
+}
+
+// memoryPollInterval is how often the heap of the client is sampled against the
+// openloop.memorylimit.
+const memoryPollInterval = 10 * time.Millisecond
+
+// scheduler generates operations at the target throughput regardless of how fast
+// the workers complete them. Operations wait in a bounded backlog, with one queue
+// per priority class, and are shed from the lowest priority class once it is full.
+type scheduler struct {
+	sync.Mutex
+	cond *sync.Cond
+	// space is signaled when an arrival leaves the backlog
+	space *sync.Cond
+
+	classes    []string
+	weights    []float64
+	queues     [][]arrival
+	size       int
+	backlog    int
+	dropNewest bool
+	closed     bool
+
+	// memoryLimit caps the heap of the client, sampled every memoryPollInterval
+	// into heapBytes, which covers the backlog and the values of the operations
+	memoryLimit int64
+	heapBytes   int64
+
+	dispatched []int64
+	shed       []int64
+
+	backpressureWaits int64
+	backpressureTime  time.Duration
+}
+
+// parseClasses parses "name:weight,..." into the class names and their
+// cumulative weights. The classes are given from the highest to the lowest priority.
+func parseClasses(s string) ([]string, []float64, error) {
+	var (
+		names   []string
+		weights []float64
+		total   float64
+	)
+	for _, class := range strings.Split(s, ",") {
+		pair := strings.SplitN(strings.TrimSpace(class), ":", 2)
+		weight := float64(1)
+		if len(pair) == 2 {
+			var err error
+			if weight, err = strconv.ParseFloat(pair[1], 64); err != nil || weight <= 0 {
+				return nil, nil, fmt.Errorf("invalid weight of class %q in %s", pair[0], prop.OpenLoopClasses)
+			}
+		}
+		total += weight
+		names = append(names, pair[0])
+		weights = append(weights, total)
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return names, weights, nil
+}
+
+func newScheduler(p *properties.Properties) (*scheduler, error) {
+	if !p.GetBool(prop.OpenLoop, prop.OpenLoopDefault) {
+		return nil, nil
+	}
+	if p.GetInt64(prop.Target, 0) <= 0 && p.GetString(prop.TargetSchedule, "") == "" {
+		return nil, fmt.Errorf("%s needs a %s throughput", prop.OpenLoop, prop.Target)
+	}
+
+	classes, weights, err := parseClasses(p.GetString(prop.OpenLoopClasses, prop.OpenLoopClassesDefault))
+	if err != nil {
+		return nil, err
+	}
+	s := &scheduler{
+		classes:     classes,
+		weights:     weights,
+		queues:      make([][]arrival, len(classes)),
+		backlog:     p.GetInt(prop.OpenLoopBacklog, prop.OpenLoopBacklogDefault),
+		memoryLimit: p.GetInt64(prop.OpenLoopMemoryLimit, 0),
+		dispatched:  make([]int64, len(classes)),
+		shed:        make([]int64, len(classes)),
+	}
+	switch policy := p.GetString(prop.OpenLoopShedPolicy, prop.OpenLoopShedPolicyDefault); policy {
+	case shedDropNewest:
+		s.dropNewest = true
+	case shedDropOldest:
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting %s or %s", prop.OpenLoopShedPolicy, policy, shedDropNewest, shedDropOldest)
+	}
+	if s.backlog < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.OpenLoopBacklog)
+	}
+	if s.memoryLimit < 0 {
+		return nil, fmt.Errorf("%s can't be negative", prop.OpenLoopMemoryLimit)
+	}
+	s.cond = sync.NewCond(s)
+	s.space = sync.NewCond(s)
+	return s, nil
+}
+
+// generate pushes count arrivals at the scheduled throughput, then closes the scheduler.
+func (s *scheduler) generate(ctx context.Context, count int64, schedule *targetSchedule) {
+	defer s.close()
+	if s.memoryLimit > 0 {
+		go s.pollMemory()
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	start, base := time.Now(), int64(0)
+	version, target := int64(-1), int64(0)
+	for i := int64(0); count == 0 || i < count; i++ {
+		if control.changed(version) {
+			var (
+				newTarget int64
+				paused    time.Duration
+				ok        bool
+			)
+			if version, newTarget, paused, ok = control.wait(ctx); !ok {
+				return
+			}
+			if newTarget > 0 && newTarget != target {
+				// the new target applies from now on
+				target = newTarget
+				schedule = constantTarget(target)
+				start, base = time.Now(), i
+			} else {
+				// the schedule resumes where it was paused
+				start = start.Add(paused)
+			}
+		}
+
+		next := start.Add(schedule.offset(float64(i - base)))
+		if d := time.Until(next); d > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d):
+			}
+		}
+
+		x := r.Float64()
+		class := 0
+		for class < len(s.weights)-1 && x >= s.weights[class] {
+			class++
+		}
+		s.push(arrival{class: class, time: next})
+	}
+}
+
+// pollMemory samples the heap of the client until the scheduler is closed, and wakes
+// up the generation held up by the memory limit once it is sampled.
+func (s *scheduler) pollMemory() {
+	ticker := time.NewTicker(memoryPollInterval)
+	defer ticker.Stop()
+	var stats runtime.MemStats
+	for {
+		runtime.ReadMemStats(&stats)
+		s.Lock()
+		s.heapBytes = int64(stats.HeapAlloc)
+		closed := s.closed
+		s.Unlock()
+		s.space.Broadcast()
+		if closed {
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// overMemory returns whether the heap is over the memory limit while the backlog,
+// which the generation can hold up, isn't empty.
+func (s *scheduler) overMemory() bool {
+	return s.memoryLimit > 0 && s.heapBytes >= s.memoryLimit && s.size > 0
+}
+
+func (s *scheduler) push(a arrival) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.overMemory() {
+		// hold up the generation rather than grow the backlog, the arrival
+		// keeps its scheduled time so the wait shows in the intended latencies
+		start := time.Now()
+		for s.overMemory() && !s.closed {
+			s.space.Wait()
+		}
+		s.backpressureWaits++
+		s.backpressureTime += time.Since(start)
+	}
+	if s.closed {
+		return
+	}
+
+	if s.size >= s.backlog {
+		victim := len(s.queues) - 1
+		for len(s.queues[victim]) == 0 {
+			victim--
+		}
+		if a.class >= victim && (s.dropNewest || a.class > victim) {
+			// the new arrival is the one to shed
+			s.shed[a.class]++
+			return
+		}
+		q := s.queues[victim]
+		if s.dropNewest {
+			s.queues[victim] = q[:len(q)-1]
+		} else {
+			s.queues[victim] = q[1:]
+		}
+		s.shed[victim]++
+		s.size--
+	}
+
+	s.queues[a.class] = append(s.queues[a.class], a)
+	s.size++
+	s.cond.Signal()
+}
+
+// pop returns the oldest arrival of the highest priority class, blocking until
+// there is one. It returns false once the scheduler is closed and drained.
+func (s *scheduler) pop() (arrival, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	for s.size == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if s.size == 0 {
+		return arrival{}, false
+	}
+	for class, q := range s.queues {
+		if len(q) != 0 {
+			s.queues[class] = q[1:]
+			s.size--
+			s.dispatched[class]++
+			s.space.Signal()
+			return q[0], true
+		}
+	}
+	panic("unreachable")
+}
+
+func (s *scheduler) close() {
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+	s.cond.Broadcast()
+	s.space.Broadcast()
+}
+
+func (s *scheduler) output() {
+	s.Lock()
+	defer s.Unlock()
+
+	for class, name := range s.classes {
+		fmt.Printf("Open loop class %s - Dispatched: %d, Shed: %d\n", name, s.dispatched[class], s.shed[class])
+	}
+	if s.memoryLimit > 0 {
+		fmt.Printf("Open loop backpressure - Memory limit(bytes): %d, Heap(bytes): %d, Waits: %d, Wait time(ms): %d\n",
+			s.memoryLimit, s.heapBytes, s.backpressureWaits, s.backpressureTime/time.Millisecond)
+	}
+}
+
+// runOpenLoop executes the arrivals of the scheduler until it is drained.
+func (w *worker) runOpenLoop(ctx context.Context) {
+	intendedStart := new(time.Time)
+	ctx = context.WithValue(ctx, intendedStartKey{}, intendedStart)
+
+	for {
+		a, ok := w.sched.pop()
+		if !ok {
+			return
+		}
+		*intendedStart = a.time
+		w.doOperation(ctx)
+		if w.done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// selfProfiler samples the resources the go-ycsb process itself uses, to tell
+// whether the harness rather than the DB is the bottleneck.
+type selfProfiler struct {
+	start    time.Time
+	startCPU time.Duration
+	startGC  uint32
+	// startPause is the total GC pause time before the run, in nanoseconds
+	startPause uint64
+
+	prevTime  time.Time
+	prevCPU   time.Duration
+	prevGC    uint32
+	prevPause uint64
+
+	peakRSS       int64
+	maxGoroutines int
+	maxPause      uint64
+}
+
+func newSelfProfiler(p *properties.Properties) *selfProfiler {
+	if !p.GetBool(prop.SelfProfile, prop.SelfProfileDefault) {
+		return nil
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	now := time.Now()
+	cpu := processCPUTime()
+	return &selfProfiler{
+		start:      now,
+		startCPU:   cpu,
+		startGC:    ms.NumGC,
+		startPause: ms.PauseTotalNs,
+		prevTime:   now,
+		prevCPU:    cpu,
+		prevGC:     ms.NumGC,
+		prevPause:  ms.PauseTotalNs,
+	}
+}
+
+// output prints the CPU usage, the memory, the goroutines and the GC pauses of the
+// process since the last output.
+func (s *selfProfiler) output() {
+	fmt.Println(s.sample())
+}
+
+// sample describes the resource usage since the last sample, and updates the peaks.
+func (s *selfProfiler) sample() string {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	now := time.Now()
+	cpu := processCPUTime()
+	goroutines := runtime.NumGoroutine()
+	rss := processRSS()
+
+	// PauseNs is a circular buffer of the most recent GC pause times
+	maxPause := uint64(0)
+	for gc := s.prevGC + 1; gc <= ms.NumGC && ms.NumGC-gc < uint32(len(ms.PauseNs)); gc++ {
+		if pause := ms.PauseNs[(gc+uint32(len(ms.PauseNs))-1)%uint32(len(ms.PauseNs))]; pause > maxPause {
+			maxPause = pause
+		}
+	}
+
+	line := fmt.Sprintf("%-6s - CPU: %.1f%% of %d%%, RSS(MB): %.1f, Heap(MB): %.1f, Goroutines: %d, GCs: %d, GC pause total(ms): %.2f, GC pause max(ms): %.2f",
+		"CLIENT", float64(cpu-s.prevCPU)/float64(now.Sub(s.prevTime))*100, runtime.NumCPU()*100,
+		float64(rss)/(1<<20), float64(ms.HeapAlloc)/(1<<20), goroutines, ms.NumGC-s.prevGC,
+		float64(ms.PauseTotalNs-s.prevPause)/1e6, float64(maxPause)/1e6)
+
+	s.prevTime, s.prevCPU, s.prevGC, s.prevPause = now, cpu, ms.NumGC, ms.PauseTotalNs
+	if rss > s.peakRSS {
+		s.peakRSS = rss
+	}
+	if goroutines > s.maxGoroutines {
+		s.maxGoroutines = goroutines
+	}
+	if maxPause > s.maxPause {
+		s.maxPause = maxPause
+	}
+	return line
+}
+
+// summary prints the resource usage of the process over the whole run.
+func (s *selfProfiler) summary() {
+	s.sample()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	fmt.Printf("Client resources - Avg CPU: %.1f%% of %d%%, Peak RSS(MB): %.1f, Max goroutines: %d, GCs: %d, GC pause total(ms): %.2f, GC pause max(ms): %.2f\n",
+		float64(s.prevCPU-s.startCPU)/float64(s.prevTime.Sub(s.start))*100, runtime.NumCPU()*100,
+		float64(s.peakRSS)/(1<<20), s.maxGoroutines, ms.NumGC-s.startGC,
+		float64(ms.PauseTotalNs-s.startPause)/1e6, float64(s.maxPause)/1e6)
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import "time"
+
+// processCPUTime isn't available on Windows, and returns 0.
+func processCPUTime() time.Duration {
+	return 0
+}
+
+// processRSS isn't available on Windows, and returns 0.
+func processRSS() int64 {
+	return 0
+}
@@ -0,0 +1,418 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// shadowQueue is the number of operations a thread can have waiting to be mirrored
+// before it waits for the shadow DB to catch up.
+const shadowQueue = 1000
+
+// shadowMaxEvents is the number of divergences reported as events, after which they
+// are only counted.
+const shadowMaxEvents = 10
+
+// shadowDB executes every operation on the primary DB, which is measured, and mirrors
+// it to the shadow DB afterwards, in the order of every thread, to validate a DB against
+// a trusted one under the same load. The writes which failed on the primary aren't
+// mirrored, as they may or may not have been applied. With compare, the results of the
+// reads of both are compared, except the reads of the records such a write left
+// indeterminate.
+type shadowDB struct {
+	primary ycsb.DB
+	shadow  ycsb.DB
+	compare bool
+
+	mirrored  int64
+	failed    int64
+	compared  int64
+	divergent int64
+
+	mu     sync.Mutex
+	events []ycsb.Event
+	// indeterminate holds the records of every table which failed writes on the
+	// primary left indeterminate
+	indeterminate      map[string]map[string]struct{}
+	indeterminateCount int64
+}
+
+// batchShadowDB is the shadowDB of a primary and a shadow DB which both batch. The
+// batches of the others run as an operation per record, as DbWrapper does for the
+// DBs which don't implement ycsb.BatchDB.
+type batchShadowDB struct {
+	*shadowDB
+}
+
+// shadowThreadKey is the context key of the mirroring state of the thread.
+type shadowThreadKey struct{}
+
+// shadowThread mirrors the operations of a thread to the shadow DB, on the shadow
+// DB state of the thread.
+type shadowThread struct {
+	ctx  context.Context
+	ops  chan func(ctx context.Context)
+	done chan struct{}
+}
+
+// NewShadowDB returns the DB executing the operations on primary and mirroring them
+// to shadow. Only the basic and batch operations are supported. The reads can only be
+// compared with a single thread, since the threads mirror their writes independently,
+// so the concurrent writes of a record may be applied to the shadow DB in another
+// order than to the primary one, which then diverge for good.
+func NewShadowDB(p *properties.Properties, primary ycsb.DB, shadow ycsb.DB) (ycsb.DB, error) {
+	s := &shadowDB{
+		primary:       primary,
+		shadow:        shadow,
+		compare:       p.GetBool(prop.ShadowCompare, false),
+		indeterminate: make(map[string]map[string]struct{}),
+	}
+	if s.compare && p.GetInt(prop.ThreadCount, 1) > 1 {
+		return nil, fmt.Errorf("%s needs a single thread, as the threads mirror the writes of a record in any order", prop.ShadowCompare)
+	}
+	_, primaryBatches := primary.(ycsb.BatchDB)
+	_, shadowBatches := shadow.(ycsb.BatchDB)
+	if primaryBatches && shadowBatches {
+		return batchShadowDB{s}, nil
+	}
+	return s, nil
+}
+
+func (s *shadowDB) ToSqlDB() *sql.DB {
+	return s.primary.ToSqlDB()
+}
+
+func (s *shadowDB) Close() error {
+	err := s.primary.Close()
+	if shadowErr := s.shadow.Close(); err == nil {
+		err = shadowErr
+	}
+	return err
+}
+
+func (s *shadowDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	t := &shadowThread{
+		ctx:  s.shadow.InitThread(ctx, threadID, threadCount),
+		ops:  make(chan func(ctx context.Context), shadowQueue),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(t.done)
+		for op := range t.ops {
+			op(t.ctx)
+		}
+	}()
+	ctx = s.primary.InitThread(ctx, threadID, threadCount)
+	return context.WithValue(ctx, shadowThreadKey{}, t)
+}
+
+// CleanupThread waits for the operations of the thread to be mirrored.
+func (s *shadowDB) CleanupThread(ctx context.Context) {
+	if t, ok := ctx.Value(shadowThreadKey{}).(*shadowThread); ok {
+		close(t.ops)
+		<-t.done
+		s.shadow.CleanupThread(t.ctx)
+	}
+	s.primary.CleanupThread(ctx)
+}
+
+// mirror queues the operation for the shadow DB, unless the run was interrupted.
+func (s *shadowDB) mirror(ctx context.Context, op func(ctx context.Context) error) {
+	t, ok := ctx.Value(shadowThreadKey{}).(*shadowThread)
+	if !ok || ctx.Err() == context.Canceled {
+		return
+	}
+	t.ops <- func(ctx context.Context) {
+		atomic.AddInt64(&s.mirrored, 1)
+		if err := op(ctx); err != nil {
+			atomic.AddInt64(&s.failed, 1)
+		}
+	}
+}
+
+// mirrorWrite mirrors the write if it succeeded on the primary, and otherwise marks
+// its records as indeterminate.
+func (s *shadowDB) mirrorWrite(ctx context.Context, table string, keys []string, err error, op func(ctx context.Context) error) {
+	if err == nil {
+		s.mirror(ctx, op)
+		return
+	}
+	if ctx.Err() == context.Canceled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, ok := s.indeterminate[table]
+	if !ok {
+		records = make(map[string]struct{})
+		s.indeterminate[table] = records
+	}
+	for _, key := range keys {
+		if _, ok := records[key]; !ok {
+			records[key] = struct{}{}
+			s.indeterminateCount++
+		}
+	}
+}
+
+// determinate returns whether none of the records is indeterminate, any record of
+// the table if keys is nil, as for a scan.
+func (s *shadowDB) determinate(table string, keys []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := s.indeterminate[table]
+	if keys == nil {
+		return len(records) == 0
+	}
+	for _, key := range keys {
+		if _, ok := records[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareRows reports a divergence if the rows of the primary and the shadow DB differ.
+// The reads which failed on either are only compared if one of them didn't find the
+// records the other did.
+func (s *shadowDB) compareRows(op string, table string, key string, fields []string, rows []map[string][]byte, err error, shadowRows []map[string][]byte, shadowErr error) {
+	if err != nil || shadowErr != nil {
+		notFound := ycsb.ClassifyError(err) == ycsb.ErrorClassNotFound
+		shadowNotFound := ycsb.ClassifyError(shadowErr) == ycsb.ErrorClassNotFound
+		if (err == nil && shadowNotFound) || (notFound && shadowErr == nil) {
+			atomic.AddInt64(&s.compared, 1)
+			s.diverge(op, table, key, fmt.Sprintf("primary error: %v, shadow error: %v", err, shadowErr))
+		}
+		return
+	}
+
+	atomic.AddInt64(&s.compared, 1)
+	if len(rows) != len(shadowRows) {
+		s.diverge(op, table, key, fmt.Sprintf("%d rows on the primary, %d on the shadow", len(rows), len(shadowRows)))
+		return
+	}
+	for i := range rows {
+		if field, ok := sameRow(fields, rows[i], shadowRows[i]); !ok {
+			s.diverge(op, table, key, fmt.Sprintf("field %s of row %d differs", field, i))
+			return
+		}
+	}
+}
+
+// sameRow returns whether the fields, all of them if nil, are the same in a and b,
+// and the first one which isn't.
+func sameRow(fields []string, a map[string][]byte, b map[string][]byte) (string, bool) {
+	if fields == nil {
+		for field := range a {
+			fields = append(fields, field)
+		}
+		for field := range b {
+			if _, ok := a[field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+	}
+	for _, field := range fields {
+		va, okA := a[field]
+		vb, okB := b[field]
+		if okA != okB || !bytes.Equal(va, vb) {
+			return field, false
+		}
+	}
+	return "", true
+}
+
+func (s *shadowDB) diverge(op string, table string, key string, detail string) {
+	if atomic.AddInt64(&s.divergent, 1) > shadowMaxEvents {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ycsb.Event{
+		Time:    time.Now(),
+		Message: fmt.Sprintf("Shadow divergence of %s %s/%s: %s", op, table, key, detail),
+	})
+}
+
+// copyRows copies the rows the workload may reuse before they are mirrored or compared.
+func copyRows(rows []map[string][]byte) []map[string][]byte {
+	copied := make([]map[string][]byte, len(rows))
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		copied[i] = make(map[string][]byte, len(row))
+		for field, value := range row {
+			copied[i][field] = append([]byte(nil), value...)
+		}
+	}
+	return copied
+}
+
+func (s *shadowDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	values, err := s.primary.Read(ctx, table, key, fields)
+	var compared []map[string][]byte
+	if s.compare {
+		compared = copyRows([]map[string][]byte{values})
+	}
+	s.mirror(ctx, func(ctx context.Context) error {
+		shadowValues, shadowErr := s.shadow.Read(ctx, table, key, fields)
+		if s.compare && s.determinate(table, []string{key}) {
+			s.compareRows("READ", table, key, fields, compared, err, []map[string][]byte{shadowValues}, shadowErr)
+		}
+		return shadowErr
+	})
+	return values, err
+}
+
+func (s *shadowDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	rows, err := s.primary.Scan(ctx, table, startKey, count, fields)
+	var compared []map[string][]byte
+	if s.compare {
+		compared = copyRows(rows)
+	}
+	s.mirror(ctx, func(ctx context.Context) error {
+		shadowRows, shadowErr := s.shadow.Scan(ctx, table, startKey, count, fields)
+		if s.compare && s.determinate(table, nil) {
+			s.compareRows("SCAN", table, startKey, fields, compared, err, shadowRows, shadowErr)
+		}
+		return shadowErr
+	})
+	return rows, err
+}
+
+func (s *shadowDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	err := s.primary.Update(ctx, table, key, values)
+	values = copyRows([]map[string][]byte{values})[0]
+	s.mirrorWrite(ctx, table, []string{key}, err, func(ctx context.Context) error {
+		return s.shadow.Update(ctx, table, key, values)
+	})
+	return err
+}
+
+func (s *shadowDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	err := s.primary.Insert(ctx, table, key, values)
+	values = copyRows([]map[string][]byte{values})[0]
+	s.mirrorWrite(ctx, table, []string{key}, err, func(ctx context.Context) error {
+		return s.shadow.Insert(ctx, table, key, values)
+	})
+	return err
+}
+
+func (s *shadowDB) Delete(ctx context.Context, table string, key string) error {
+	err := s.primary.Delete(ctx, table, key)
+	s.mirrorWrite(ctx, table, []string{key}, err, func(ctx context.Context) error {
+		return s.shadow.Delete(ctx, table, key)
+	})
+	return err
+}
+
+func (s batchShadowDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	rows, err := s.primary.(ycsb.BatchDB).BatchRead(ctx, table, keys, fields)
+	var compared []map[string][]byte
+	if s.compare {
+		compared = copyRows(rows)
+	}
+	keys = append([]string(nil), keys...)
+	s.mirror(ctx, func(ctx context.Context) error {
+		shadowRows, shadowErr := s.shadow.(ycsb.BatchDB).BatchRead(ctx, table, keys, fields)
+		if s.compare && len(keys) > 0 && s.determinate(table, keys) {
+			s.compareRows("BATCH_READ", table, keys[0], fields, compared, err, shadowRows, shadowErr)
+		}
+		return shadowErr
+	})
+	return rows, err
+}
+
+func (s batchShadowDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	err := s.primary.(ycsb.BatchDB).BatchUpdate(ctx, table, keys, values)
+	keys, values = append([]string(nil), keys...), copyRows(values)
+	s.mirrorWrite(ctx, table, keys, err, func(ctx context.Context) error {
+		return s.shadow.(ycsb.BatchDB).BatchUpdate(ctx, table, keys, values)
+	})
+	return err
+}
+
+func (s batchShadowDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	err := s.primary.(ycsb.BatchDB).BatchInsert(ctx, table, keys, values)
+	keys, values = append([]string(nil), keys...), copyRows(values)
+	s.mirrorWrite(ctx, table, keys, err, func(ctx context.Context) error {
+		return s.shadow.(ycsb.BatchDB).BatchInsert(ctx, table, keys, values)
+	})
+	return err
+}
+
+func (s batchShadowDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	err := s.primary.(ycsb.BatchDB).BatchDelete(ctx, table, keys)
+	keys = append([]string(nil), keys...)
+	s.mirrorWrite(ctx, table, keys, err, func(ctx context.Context) error {
+		return s.shadow.(ycsb.BatchDB).BatchDelete(ctx, table, keys)
+	})
+	return err
+}
+
+func (s *shadowDB) ClassifyError(err error) string {
+	if classifierDB, ok := s.primary.(ycsb.ErrorClassifierDB); ok {
+		return classifierDB.ClassifyError(err)
+	}
+	return ""
+}
+
+// ExtendedStats adds the mirrored operations, the records left indeterminate, and the
+// reads compared, to the statistics of the primary DB.
+func (s *shadowDB) ExtendedStats() map[string]int64 {
+	stats := make(map[string]int64)
+	if statsDB, ok := s.primary.(ycsb.ExtendedStatsDB); ok {
+		for name, value := range statsDB.ExtendedStats() {
+			stats[name] = value
+		}
+	}
+	stats["shadow_mirrored"] = atomic.LoadInt64(&s.mirrored)
+	stats["shadow_failed"] = atomic.LoadInt64(&s.failed)
+	if s.compare {
+		stats["shadow_compared"] = atomic.LoadInt64(&s.compared)
+		stats["shadow_divergent"] = atomic.LoadInt64(&s.divergent)
+	}
+	s.mu.Lock()
+	stats["shadow_indeterminate"] = s.indeterminateCount
+	s.mu.Unlock()
+	return stats
+}
+
+// Events adds the first divergences to the events of the primary DB.
+func (s *shadowDB) Events() []ycsb.Event {
+	var events []ycsb.Event
+	if eventDB, ok := s.primary.(ycsb.EventDB); ok {
+		events = eventDB.Events()
+	}
+	s.mu.Lock()
+	events = append(events, s.events...)
+	s.events = nil
+	s.mu.Unlock()
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// OutputExtendedStats prints the binding specific statistics of the DB, if it collects any.
+func OutputExtendedStats(db ycsb.DB) {
+	statsDB, ok := db.(ycsb.ExtendedStatsDB)
+	if !ok {
+		return
+	}
+	stats := statsDB.ExtendedStats()
+	if len(stats) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, fmt.Sprintf("%s: %d", name, stats[name]))
+	}
+	fmt.Printf("%-6s - %s\n", "DB", strings.Join(fields, ", "))
+}
+
+// connReporter prints the connection events of the DB, with the change since the last report.
+type connReporter struct {
+	prev util.ConnStats
+}
+
+func (r *connReporter) output() {
+	stats := util.LoadConnStats()
+	if stats == (util.ConnStats{}) {
+		// the DB doesn't manage its own connections
+		return
+	}
+	prev := r.prev
+	r.prev = stats
+	fmt.Printf("%-6s - Established: %d (+%d), Reconnects: %d (+%d), Dial failures: %d (+%d), Keep-alive failures: %d (+%d)\n", "CONN",
+		stats.Established, stats.Established-prev.Established,
+		stats.Reconnects, stats.Reconnects-prev.Reconnects,
+		stats.DialFailures, stats.DialFailures-prev.DialFailures,
+		stats.KeepAliveFailures, stats.KeepAliveFailures-prev.KeepAliveFailures)
+}
+
+// outputEvents prints the events the DB observed since the last output, if it reports any.
+func outputEvents(db ycsb.DB) {
+	eventDB, ok := db.(ycsb.EventDB)
+	if !ok {
+		return
+	}
+	for _, event := range eventDB.Events() {
+		fmt.Printf("%-6s - %s %s\n", "EVENT", event.Time.Format("15:04:05.000"), event.Message)
+	}
+}
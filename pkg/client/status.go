@@ -0,0 +1,155 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+)
+
+// Phases of a run, reported by the status endpoint.
+const (
+	PhaseStarting = "starting"
+	PhaseWarmUp   = "warm-up"
+	PhaseRunning  = "running"
+	PhaseFinished = "finished"
+)
+
+// runStatus is the state of the run reported by the status endpoint.
+type runStatus struct {
+	sync.Mutex
+	phase string
+	// stage is "load" or "run"
+	stage string
+	start time.Time
+	total int64
+}
+
+var status = &runStatus{phase: PhaseStarting}
+
+func (s *runStatus) begin(stage string, total int64, phase string) {
+	s.Lock()
+	s.stage, s.total, s.phase, s.start = stage, total, phase, time.Now()
+	s.Unlock()
+}
+
+func (s *runStatus) setPhase(phase string) {
+	s.Lock()
+	s.phase = phase
+	s.Unlock()
+}
+
+type statusOperation struct {
+	Count  int64   `json:"count"`
+	OPS    float64 `json:"ops"`
+	Avg    int64   `json:"avg_us"`
+	P99    int64   `json:"p99_us"`
+	P999   int64   `json:"p99.9_us"`
+	Errors int64   `json:"errors"`
+}
+
+type statusReport struct {
+	Phase           string                      `json:"phase"`
+	Stage           string                      `json:"stage,omitempty"`
+	Paused          bool                        `json:"paused,omitempty"`
+	Elapsed         float64                     `json:"elapsed_s"`
+	Operations      int64                       `json:"operations"`
+	TotalOperations int64                       `json:"total_operations,omitempty"`
+	Progress        float64                     `json:"progress_percent,omitempty"`
+	OPS             float64                     `json:"ops"`
+	Errors          int64                       `json:"errors"`
+	ByOperation     map[string]*statusOperation `json:"by_operation"`
+}
+
+func (s *runStatus) report() *statusReport {
+	s.Lock()
+	r := &statusReport{
+		Phase:           s.phase,
+		Stage:           s.stage,
+		Paused:          control.state().Paused,
+		TotalOperations: s.total,
+		ByOperation:     make(map[string]*statusOperation),
+	}
+	if !s.start.IsZero() {
+		r.Elapsed = time.Since(s.start).Seconds()
+	}
+	s.Unlock()
+
+	operation := func(op string) *statusOperation {
+		o, ok := r.ByOperation[op]
+		if !ok {
+			o = new(statusOperation)
+			r.ByOperation[op] = o
+		}
+		return o
+	}
+	for op, info := range measurement.Info() {
+		if measurement.IsDerived(op) {
+			continue
+		}
+		count, _ := info.Get(measurement.COUNT).(int64)
+		r.Operations += count
+		if strings.HasSuffix(op, "_ERROR") {
+			r.Errors += count
+			operation(strings.TrimSuffix(op, "_ERROR")).Errors = count
+			continue
+		}
+		o := operation(op)
+		o.Count = count
+		o.OPS, _ = info.Get(measurement.QPS).(float64)
+		o.Avg, _ = info.Get(measurement.AVG).(int64)
+		if p99, ok := info.Get(measurement.PER99TH).(int); ok {
+			o.P99 = int64(p99)
+		}
+		if p999, ok := info.Get(measurement.PER999TH).(int); ok {
+			o.P999 = int64(p999)
+		}
+		r.OPS += o.OPS
+	}
+	if r.TotalOperations > 0 {
+		r.Progress = float64(r.Operations) / float64(r.TotalOperations) * 100
+	}
+	return r
+}
+
+func serveStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(status.report())
+}
+
+// ServeStatus exposes the live state of the run as JSON at /status on addr: its phase,
+// progress, throughput, latencies and errors, along with the control endpoint at
+// /control. It returns once the address is bound, and serves in the background.
+func ServeStatus(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", serveStatus)
+	mux.HandleFunc("/control", serveControl)
+	mux.HandleFunc("/control/", serveControl)
+	go func() {
+		_ = http.Serve(l, mux)
+	}()
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// targetSchedule is a target throughput which changes at given offsets from the
+// start of the run.
+type targetSchedule struct {
+	offsets []time.Duration
+	// rates are the operations per second from the offset of the same index
+	rates []float64
+}
+
+// newTargetSchedule parses prop.TargetSchedule, and returns nil if it isn't set.
+func newTargetSchedule(p *properties.Properties) (*targetSchedule, error) {
+	spec := p.GetString(prop.TargetSchedule, "")
+	if spec == "" {
+		return nil, nil
+	}
+
+	s := new(targetSchedule)
+	for _, step := range strings.Split(spec, ",") {
+		pair := strings.SplitN(strings.TrimSpace(step), ":", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("step %q of %s isn't an offset and a target", step, prop.TargetSchedule)
+		}
+		offset, err := parseOffset(pair[0])
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset of step %q of %s", step, prop.TargetSchedule)
+		}
+		if len(s.offsets) == 0 && offset != 0 {
+			return nil, fmt.Errorf("the first step of %s must start at 0", prop.TargetSchedule)
+		}
+		if len(s.offsets) > 0 && offset <= s.offsets[len(s.offsets)-1] {
+			return nil, fmt.Errorf("the offsets of %s must increase", prop.TargetSchedule)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(pair[1]), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("the target of step %q of %s must be positive", step, prop.TargetSchedule)
+		}
+		s.offsets = append(s.offsets, offset)
+		s.rates = append(s.rates, rate)
+	}
+	return s, nil
+}
+
+// parseOffset parses a number of seconds, or a duration such as "2m".
+func parseOffset(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// constantTarget returns the schedule of a target throughput which doesn't change.
+func constantTarget(opsPerSec int64) *targetSchedule {
+	return &targetSchedule{offsets: []time.Duration{0}, rates: []float64{float64(opsPerSec)}}
+}
+
+// offset returns the offset from the start of the run at which the n-th operation
+// is scheduled, with the operations before the last step spread evenly over their
+// step, and the others at the rate of the last step.
+func (s *targetSchedule) offset(n float64) time.Duration {
+	var done float64
+	for i, start := range s.offsets {
+		if i < len(s.offsets)-1 {
+			steps := s.rates[i] * (s.offsets[i+1] - start).Seconds()
+			if n >= done+steps {
+				done += steps
+				continue
+			}
+		}
+		return start + time.Duration((n-done)/s.rates[i]*float64(time.Second))
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// Think time distributions.
+const (
+	thinkConstant    = "constant"
+	thinkExponential = "exponential"
+	thinkUniform     = "uniform"
+)
+
+// thinkTime is the pause of every thread between its operations, like the users of
+// an application between their requests. All the distributions have the same mean.
+type thinkTime struct {
+	mean         time.Duration
+	distribution string
+}
+
+// newThinkTime returns nil if the threads don't pause between their operations.
+func newThinkTime(p *properties.Properties) (*thinkTime, error) {
+	mean, err := time.ParseDuration(p.GetString(prop.ThinkTime, prop.ThinkTimeDefault))
+	if err != nil || mean < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.ThinkTime)
+	}
+	if mean == 0 {
+		return nil, nil
+	}
+	if p.GetBool(prop.OpenLoop, prop.OpenLoopDefault) {
+		return nil, fmt.Errorf("%s doesn't apply to %s, where the threads don't issue their own operations", prop.ThinkTime, prop.OpenLoop)
+	}
+
+	switch distribution := p.GetString(prop.ThinkTimeDistribution, prop.ThinkTimeDistributionDefault); distribution {
+	case thinkConstant, thinkExponential, thinkUniform:
+		return &thinkTime{mean: mean, distribution: distribution}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting %s, %s or %s", prop.ThinkTimeDistribution, distribution, thinkConstant, thinkExponential, thinkUniform)
+	}
+}
+
+// next returns the next pause, uniform ones ranging from 0 to twice the mean.
+func (t *thinkTime) next(r *rand.Rand) time.Duration {
+	switch t.distribution {
+	case thinkExponential:
+		return time.Duration(r.ExpFloat64() * float64(t.mean))
+	case thinkUniform:
+		return time.Duration(r.Int63n(2*int64(t.mean) + 1))
+	default:
+		return t.mean
+	}
+}
+
+// pause pauses for the next think time, and returns how long it paused, or false if
+// ctx is done in the meantime.
+func (t *thinkTime) pause(ctx context.Context, r *rand.Rand) (time.Duration, bool) {
+	d := t.next(r)
+	select {
+	case <-ctx.Done():
+		return 0, false
+	case <-time.After(d):
+		return d, true
+	}
+}
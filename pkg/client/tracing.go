@@ -0,0 +1,261 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// tracer is the operation tracing used by DbWrapper, nil if disabled.
+var tracer *opTracer
+
+const (
+	// spans are sent once this many are pending, or every traceFlushInterval
+	traceBatchSize     = 512
+	traceQueueSize     = 8 * traceBatchSize
+	traceFlushInterval = 5 * time.Second
+
+	otlpSpanKindClient = 3
+	otlpStatusOK       = 1
+	otlpStatusError    = 2
+)
+
+// The OTLP/HTTP JSON encoding of spans, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// span is a sampled operation.
+type span struct {
+	traceID [16]byte
+	spanID  [8]byte
+	op      string
+	key     string
+	start   time.Time
+}
+
+type spanKey struct{}
+
+// opTracer creates a span for a sample of the operations, and exports them to an
+// OTLP/HTTP collector in the background.
+type opTracer struct {
+	endpoint    string
+	serviceName string
+	dbSystem    string
+	sampleRate  float64
+
+	mu sync.Mutex
+	r  *rand.Rand
+
+	queue chan otlpSpan
+	done  chan struct{}
+
+	exported int64
+	dropped  int64
+	failed   int64
+}
+
+func newOpTracer(p *properties.Properties) (*opTracer, error) {
+	endpoint := p.GetString(prop.TracingEndpoint, "")
+	if endpoint == "" {
+		return nil, nil
+	}
+	t := &opTracer{
+		endpoint:    endpoint,
+		serviceName: p.GetString(prop.TracingServiceName, prop.TracingServiceNameDefault),
+		dbSystem:    p.GetString(prop.DB, ""),
+		sampleRate:  p.GetFloat64(prop.TracingSampleRate, prop.TracingSampleRateDefault),
+		r:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		queue:       make(chan otlpSpan, traceQueueSize),
+		done:        make(chan struct{}),
+	}
+	if t.sampleRate < 0 || t.sampleRate > 1 {
+		return nil, fmt.Errorf("%s must be between 0 and 1", prop.TracingSampleRate)
+	}
+	go t.export()
+	return t, nil
+}
+
+// start starts a span for the operation if it is sampled, and returns the context
+// to run the operation with.
+func (t *opTracer) start(ctx context.Context, op string, key string) context.Context {
+	s := &span{op: op, key: key, start: time.Now()}
+	t.mu.Lock()
+	sampled := t.r.Float64() < t.sampleRate
+	if sampled {
+		t.r.Read(s.traceID[:])
+		t.r.Read(s.spanID[:])
+	}
+	t.mu.Unlock()
+	if !sampled {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, spanKey{}, s)
+	return util.WithTraceParent(ctx, fmt.Sprintf("00-%x-%x-01", s.traceID, s.spanID))
+}
+
+// end ends the span of the operation, if it was sampled.
+func (t *opTracer) end(ctx context.Context, err error) {
+	s, ok := ctx.Value(spanKey{}).(*span)
+	if !ok {
+		return
+	}
+
+	attrs := []otlpAttribute{{Key: "db.operation", Value: otlpValue{s.op}}}
+	if t.dbSystem != "" {
+		attrs = append(attrs, otlpAttribute{Key: "db.system", Value: otlpValue{t.dbSystem}})
+	}
+	if s.key != "" {
+		attrs = append(attrs, otlpAttribute{Key: "ycsb.key", Value: otlpValue{s.key}})
+	}
+	status := otlpStatus{Code: otlpStatusOK}
+	if err != nil {
+		status = otlpStatus{Code: otlpStatusError, Message: err.Error()}
+	}
+
+	select {
+	case t.queue <- otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.op,
+		Kind:              otlpSpanKindClient,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(time.Now().UnixNano(), 10),
+		Attributes:        attrs,
+		Status:            status,
+	}:
+	default:
+		// never hold up operations on a slow collector
+		atomic.AddInt64(&t.dropped, 1)
+	}
+}
+
+func (t *opTracer) export() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(traceFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpSpan, 0, traceBatchSize)
+	for {
+		select {
+		case s, ok := <-t.queue:
+			if !ok {
+				t.send(batch)
+				return
+			}
+			if batch = append(batch, s); len(batch) >= traceBatchSize {
+				t.send(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			t.send(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (t *opTracer) send(spans []otlpSpan) {
+	if len(spans) == 0 {
+		return
+	}
+
+	rs := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{{Spans: spans}}}
+	rs.Resource.Attributes = []otlpAttribute{{Key: "service.name", Value: otlpValue{t.serviceName}}}
+	rs.ScopeSpans[0].Scope.Name = "go-ycsb"
+	body, err := json.Marshal(otlpTraces{ResourceSpans: []otlpResourceSpans{rs}})
+	if err != nil {
+		atomic.AddInt64(&t.failed, int64(len(spans)))
+		return
+	}
+
+	resp, err := http.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+	}
+	if err != nil {
+		atomic.AddInt64(&t.failed, int64(len(spans)))
+		return
+	}
+	atomic.AddInt64(&t.exported, int64(len(spans)))
+}
+
+// close exports the pending spans. No span may end after it is called.
+func (t *opTracer) close() {
+	close(t.queue)
+	<-t.done
+}
+
+func (t *opTracer) output() {
+	fmt.Printf("Tracing - Exported spans: %d, Dropped: %d, Failed: %d\n",
+		atomic.LoadInt64(&t.exported), atomic.LoadInt64(&t.dropped), atomic.LoadInt64(&t.failed))
+}
@@ -0,0 +1,36 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// The transactions, and those of them which aborted because of concurrent ones.
+var (
+	txAttempts int64
+	txAborts   int64
+)
+
+// outputTxAborts prints the share of the transactions which aborted because of
+// concurrent ones, if there were any transactions.
+func outputTxAborts() {
+	attempts := atomic.LoadInt64(&txAttempts)
+	if attempts == 0 {
+		return
+	}
+	aborts := atomic.LoadInt64(&txAborts)
+	fmt.Printf("TX - Attempts: %d, Aborts: %d (%.2f%%)\n", attempts, aborts, percentOf(aborts, attempts))
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// maxReportedRecords is how many of the missing or corrupt records are printed.
+const maxReportedRecords = 10
+
+// verifyStats counts the records read back by RunVerify.
+type verifyStats struct {
+	records int64
+	missing int64
+	corrupt int64
+	// failed are the records which couldn't be read
+	failed   int64
+	reported int64
+}
+
+func (s *verifyStats) add(err error) {
+	atomic.AddInt64(&s.records, 1)
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, ycsb.ErrNotFound):
+		atomic.AddInt64(&s.missing, 1)
+	case errors.Is(err, ycsb.ErrUnexpectedValue):
+		atomic.AddInt64(&s.corrupt, 1)
+	default:
+		atomic.AddInt64(&s.failed, 1)
+	}
+	if atomic.AddInt64(&s.reported, 1) <= maxReportedRecords {
+		fmt.Printf("Verify record failed: %v\n", err)
+	}
+}
+
+// RunVerify reads back every record the workload acknowledged writing, with threadcount
+// threads, and prints how many are missing or corrupt. It returns an error if any is.
+func RunVerify(ctx context.Context, p *properties.Properties, workload ycsb.Workload, db ycsb.DB) error {
+	records, ok := workload.(ycsb.RecordsWorkload)
+	if !ok {
+		return errors.New("the workload can't read back its records")
+	}
+	if w, wrapped := db.(DbWrapper); wrapped {
+		// the reads aren't part of the measured operations
+		db = w.DB
+	}
+	threadCount := p.GetInt(prop.ThreadCount, 1)
+
+	keyNums := make(chan int64, threadCount)
+	go func() {
+		defer close(keyNums)
+		records.AckedRecords(func(keyNum int64) bool {
+			select {
+			case keyNums <- keyNum:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	s := new(verifyStats)
+	start := time.Now()
+	var wg sync.WaitGroup
+	wg.Add(threadCount)
+	for i := 0; i < threadCount; i++ {
+		go func(threadID int) {
+			defer wg.Done()
+			ctx := workload.InitThread(ctx, threadID, threadCount)
+			ctx = db.InitThread(ctx, threadID, threadCount)
+			defer workload.CleanupThread(ctx)
+			defer db.CleanupThread(ctx)
+			for keyNum := range keyNums {
+				s.add(records.VerifyRecord(ctx, db, keyNum))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	secs := time.Since(start).Seconds()
+	fmt.Printf("%-8s - Records: %d, Missing: %d, Corrupt: %d, Failed: %d, Takes(s): %.1f, Records/s: %.1f\n",
+		"READBACK", s.records, s.missing, s.corrupt, s.failed, secs, float64(s.records)/secs)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if s.missing+s.corrupt+s.failed > 0 {
+		return fmt.Errorf("%d records are missing, %d are corrupt and %d couldn't be read", s.missing, s.corrupt, s.failed)
+	}
+	return nil
+}
@@ -90,10 +90,20 @@ func (a *AcknowledgedCounter) Acknowledge(value int64) {
 	}
 
 	defer a.lock.Unlock()
+	a.advance()
+}
 
-	// move a contiguous sequence from the window
-	// over to the "limit" variable
+// Flush makes all the acknowledged counters available via Last, including the
+// ones whose Acknowledge couldn't take the lock.
+func (a *AcknowledgedCounter) Flush() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.advance()
+}
 
+// advance moves a contiguous sequence from the window
+// over to the "limit" variable, with the lock held.
+func (a *AcknowledgedCounter) advance() {
 	limit := atomic.LoadInt64(&a.limit)
 	beforeFirstSlot := limit & WindowMask
 	index := limit + 1
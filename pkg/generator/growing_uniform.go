@@ -0,0 +1,44 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"math/rand"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// GrowingUniform generates integers randomly between a lower bound and the last
+// value of a basis, so the range grows with the basis.
+type GrowingUniform struct {
+	Number
+	lb    int64
+	basis ycsb.Generator
+}
+
+// NewGrowingUniform creates the GrowingUniform generator.
+// basis is Counter or AcknowledgedCounter
+func NewGrowingUniform(lb int64, basis ycsb.Generator) *GrowingUniform {
+	return &GrowingUniform{
+		lb:    lb,
+		basis: basis,
+	}
+}
+
+// Next implements the Generator Next interface.
+func (u *GrowingUniform) Next(r *rand.Rand) int64 {
+	n := r.Int63n(u.basis.Last()-u.lb+1) + u.lb
+	u.SetLastValue(n)
+	return n
+}
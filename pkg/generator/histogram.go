@@ -73,7 +73,9 @@ type bucketInfo struct {
 	value    int64
 }
 
-// NewHistogramFromFile creates a Histogram generator from file.
+// NewHistogramFromFile creates a Histogram generator from a file in the format of
+// Java YCSB: a "BlockSize<TAB><size>" line, then "<bucket><TAB><count>" lines, where
+// bucket i counts the values of i*size.
 func NewHistogramFromFile(name string) *Histogram {
 	data, err := ioutil.ReadFile(name)
 	if err != nil {
@@ -81,50 +83,62 @@ func NewHistogramFromFile(name string) *Histogram {
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
-	line := strings.Split(strings.TrimSpace(lines[0]), "\t")
-	if line[0] != "BlockSize" {
-		util.Fatalf("First line of histogram is not the BlockSize but %s", line)
+	line := strings.Fields(lines[0])
+	if len(line) != 2 || line[0] != "BlockSize" {
+		util.Fatalf("First line of histogram is not the BlockSize but %s", lines[0])
 	}
 
 	blockSize, err := strconv.ParseInt(line[1], 10, 64)
-	if err != nil {
+	if err != nil || blockSize <= 0 {
 		util.Fatalf("parse BlockSize failed %v", err)
 	}
 
 	ay := make([]bucketInfo, 0, len(lines[1:]))
 	maxLocation := int64(0)
-	for _, s := range lines[1:] {
-		s = strings.TrimSpace(s)
-		if len(s) == 0 {
-			break
+	for i, s := range lines[1:] {
+		line = strings.Fields(s)
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) != 2 {
+			util.Fatalf("line %d of histogram %s isn't a bucket and a count", i+2, name)
+		}
+		location, err := strconv.ParseInt(line[0], 10, 64)
+		if err != nil || location < 0 {
+			util.Fatalf("parse bucket of line %d of histogram %s failed %v", i+2, name, err)
+		}
+		value, err := strconv.ParseInt(line[1], 10, 64)
+		if err != nil || value < 0 {
+			util.Fatalf("parse count of line %d of histogram %s failed %v", i+2, name, err)
 		}
-
-		line = strings.Split(s, "\t")
-		location, _ := strconv.ParseInt(line[0], 10, 64)
 		if maxLocation < location {
 			maxLocation = location
 		}
-		value, _ := strconv.ParseInt(line[1], 10, 64)
 		ay = append(ay, bucketInfo{location: location, value: value})
 	}
 
 	buckets := make([]int64, maxLocation+1)
 	for _, b := range ay {
-		buckets[b.location] = b.value
+		buckets[b.location] += b.value
 	}
 
-	return NewHistogram(buckets, blockSize)
+	h := NewHistogram(buckets, blockSize)
+	if h.area == 0 {
+		util.Fatalf("histogram %s has no values", name)
+	}
+	return h
 }
 
-// Next implements the Generator Next interface.
+// Next implements the Generator Next interface. It returns i*blockSize with the
+// probability of bucket i.
 func (h *Histogram) Next(r *rand.Rand) int64 {
 	n := r.Int63n(h.area)
 
 	i := int64(0)
 	for ; i < int64(len(h.buckets))-1; i++ {
 		n -= h.buckets[i]
-		if n <= 0 {
-			return (i + 1) * h.blockSize
+		if n < 0 {
+			break
 		}
 	}
 
@@ -47,7 +47,7 @@ type Hotspot struct {
 // NewHotspot creates a Hotspot generator.
 // lowerBound: the lower bound of the distribution.
 // upperBound: the upper bound of the distribution.
-// hotsetFraction: percentage of data itme.
+// hotsetFraction: percentage of data items in the hot set.
 // hotOpnFraction: percentage of operations accessing the hot set.
 func NewHotspot(lowerBound int64, upperBound int64, hotsetFraction float64, hotOpnFraction float64) *Hotspot {
 	if hotsetFraction < 0.0 || hotsetFraction > 1.0 {
@@ -77,7 +77,8 @@ func NewHotspot(lowerBound int64, upperBound int64, hotsetFraction float64, hotO
 // Next implements the Generator Next interface.
 func (h *Hotspot) Next(r *rand.Rand) int64 {
 	value := int64(0)
-	if r.Float64() < h.hotOpnFraction {
+	// a hot or cold set rounded down to no items takes none of the operations
+	if h.coldInterval == 0 || (h.hotInterval > 0 && r.Float64() < h.hotOpnFraction) {
 		value = h.lowerBound + r.Int63n(h.hotInterval)
 	} else {
 		value = h.lowerBound + h.hotInterval + r.Int63n(h.coldInterval)
@@ -0,0 +1,62 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Pareto generates integers between a lower and an upper bound following a Pareto
+// (type II, or Lomax) distribution truncated to the range, so the lower bound is the
+// most popular item and the popularity falls off as a power law past the scale. The
+// smaller the shape, the heavier the tail; the larger the scale, the flatter the head.
+type Pareto struct {
+	Number
+	lowerBound int64
+	upperBound int64
+	shape      float64
+	// scale is in items
+	scale float64
+	// cdf is the share of the untruncated distribution within the range
+	cdf float64
+}
+
+// NewPareto creates a Pareto generator with a positive shape, and a positive scale
+// as a fraction of the range.
+func NewPareto(lowerBound int64, upperBound int64, shape float64, scale float64) *Pareto {
+	if lowerBound > upperBound {
+		lowerBound, upperBound = upperBound, lowerBound
+	}
+	items := float64(upperBound - lowerBound + 1)
+	return &Pareto{
+		lowerBound: lowerBound,
+		upperBound: upperBound,
+		shape:      shape,
+		scale:      scale * items,
+		cdf:        1 - math.Pow(1+1/scale, -shape),
+	}
+}
+
+// Next implements the Generator Next interface.
+func (p *Pareto) Next(r *rand.Rand) int64 {
+	// inverse of the CDF 1 - (1 + x/scale)^-shape, over the range
+	x := p.scale * (math.Pow(1-r.Float64()*p.cdf, -1/p.shape) - 1)
+	v := p.lowerBound + int64(x)
+	if v > p.upperBound {
+		v = p.upperBound
+	}
+	p.SetLastValue(v)
+	return v
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ShiftingHotset generates integers between a lower and an upper bound following a
+// zipfian distribution whose popular items move over time: the hot set, the most
+// popular items, advances to the next items of the range every shift interval, and
+// wraps around at the upper bound. Caches then keep facing items which turn hot,
+// instead of warming up once.
+type ShiftingHotset struct {
+	Number
+	lowerBound int64
+	items      int64
+	hotItems   int64
+	interval   time.Duration
+	start      time.Time
+	zipfian    *Zipfian
+}
+
+// NewShiftingHotset creates a ShiftingHotset generator whose hot set holds hotsetFraction
+// of the items, and shifts every interval.
+func NewShiftingHotset(lowerBound int64, upperBound int64, hotsetFraction float64, interval time.Duration) *ShiftingHotset {
+	if lowerBound > upperBound {
+		lowerBound, upperBound = upperBound, lowerBound
+	}
+	items := upperBound - lowerBound + 1
+	hotItems := int64(float64(items) * hotsetFraction)
+	if hotItems < 1 {
+		hotItems = 1
+	}
+	return &ShiftingHotset{
+		lowerBound: lowerBound,
+		items:      items,
+		hotItems:   hotItems,
+		interval:   interval,
+		start:      time.Now(),
+		zipfian:    NewZipfianWithItems(items, ZipfianConstant),
+	}
+}
+
+// Next implements the Generator Next interface.
+func (s *ShiftingHotset) Next(r *rand.Rand) int64 {
+	shifts := int64(time.Since(s.start) / s.interval)
+	offset := shifts % s.items * s.hotItems % s.items
+	v := s.lowerBound + (s.zipfian.Next(r)+offset)%s.items
+	s.SetLastValue(v)
+	return v
+}
@@ -43,26 +43,42 @@ type SkewedLatest struct {
 	Number
 	basis   ycsb.Generator
 	zipfian *Zipfian
+	// window is the number of most recent items chosen from, 0 for all of them
+	window int64
 }
 
 // NewSkewedLatest creates the SkewedLatest generator.
 // basis is Counter or AcknowledgedCounter
 func NewSkewedLatest(basis ycsb.Generator) *SkewedLatest {
-	zipfian := NewZipfianWithItems(basis.Last(), ZipfianConstant)
+	return NewSkewedLatestWithWindow(basis, 0, ZipfianConstant)
+}
+
+// NewSkewedLatestWithWindow creates the SkewedLatest generator choosing from the
+// window most recent items, or all of them if window is 0, with the zipfian constant
+// in (0, 1) as skew, the larger the more skewed to the most recent one.
+func NewSkewedLatestWithWindow(basis ycsb.Generator, window int64, zipfianConstant float64) *SkewedLatest {
 	s := &SkewedLatest{
-		basis:   basis,
-		zipfian: zipfian,
+		basis:  basis,
+		window: window,
 	}
+	s.zipfian = NewZipfianWithItems(s.items(basis.Last()), zipfianConstant)
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	s.Next(r)
 	return s
 }
 
+func (s *SkewedLatest) items(max int64) int64 {
+	if s.window > 0 && max > s.window {
+		return s.window
+	}
+	return max
+}
+
 // Next implements the Generator Next interface.
 func (s *SkewedLatest) Next(r *rand.Rand) int64 {
 	max := s.basis.Last()
-	next := max - s.zipfian.next(r, max)
+	next := max - s.zipfian.next(r, s.items(max))
 	s.SetLastValue(next)
 	return next
 }
@@ -0,0 +1,121 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// The breakdowns enabled by measurement.breakdown. A breakdown measures an operation
+// a second time under <op>[table=<table>], <op>[thread=<id>] or <op>[tenant=<id>].
+var (
+	breakdownByTable  bool
+	breakdownByThread bool
+	breakdownByTenant bool
+)
+
+func initBreakdowns(p *properties.Properties) error {
+	breakdownByTable, breakdownByThread, breakdownByTenant = false, false, false
+	s := p.GetString(prop.Breakdown, "")
+	if s == "" {
+		// the keys spread over several tables are measured by table by default
+		breakdownByTable = p.GetInt(prop.TableCount, prop.TableCountDefault) > 1
+		return nil
+	}
+	for _, b := range strings.Split(s, ",") {
+		switch strings.TrimSpace(b) {
+		case "table":
+			breakdownByTable = true
+		case "thread":
+			breakdownByThread = true
+		case "tenant":
+			breakdownByTenant = true
+		default:
+			return fmt.Errorf("unknown %s %q; expecting table, thread or tenant", prop.Breakdown, b)
+		}
+	}
+	return nil
+}
+
+// splitBreakdown splits a measured operation name into the operation and the
+// breakdown, which is "" if it isn't one.
+func splitBreakdown(op string) (string, string) {
+	if i := strings.IndexByte(op, '['); i >= 0 && strings.HasSuffix(op, "]") {
+		return op[:i], op[i+1 : len(op)-1]
+	}
+	return op, ""
+}
+
+// IsBreakdown returns whether the measured operation is the breakdown of an operation
+// by table or thread, which is already counted under the operation itself.
+func IsBreakdown(op string) bool {
+	_, breakdown := splitBreakdown(op)
+	return breakdown != ""
+}
+
+// IsDerived returns whether the measured operation is derived from the operations
+// counted under another name, so that it isn't counted as operations of its own: a
+// breakdown, an intended latency, or the attempts which timed out or were retried.
+func IsDerived(op string) bool {
+	base, breakdown := splitBreakdown(op)
+	return breakdown != "" || strings.HasPrefix(base, "INTENDED_") ||
+		strings.HasSuffix(base, "_TIMEOUT") || strings.HasSuffix(base, "_RETRY")
+}
+
+// MeasureBreakdown measures the operation of the table, client thread and tenant
+// under the enabled breakdowns. A negative threadID means the thread is unknown, and
+// an empty table that the operation isn't on a single table.
+func MeasureBreakdown(ctx context.Context, op string, table string, threadID int, lan time.Duration) {
+	if breakdownByTable && table != "" {
+		Measure(op+"[table="+table+"]", lan)
+	}
+	if breakdownByThread && threadID >= 0 {
+		Measure(op+"[thread="+strconv.Itoa(threadID)+"]", lan)
+	}
+	if tenant, ok := ctx.Value(tenantKey{}).(int); ok && breakdownByTenant {
+		Measure(op+"[tenant="+strconv.Itoa(tenant)+"]", lan)
+	}
+}
+
+// operationKey is the context key of the workload operation the DB operations of the
+// context are measured together as.
+type operationKey struct{}
+
+// WithOperation returns the context of the DB operations which a workload runs as a
+// single operation, such as a multi-get, so that they are measured together as op.
+func WithOperation(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationOf returns the workload operation set by WithOperation, if any.
+func OperationOf(ctx context.Context) (string, bool) {
+	op, ok := ctx.Value(operationKey{}).(string)
+	return op, ok
+}
+
+// tenantKey is the context key of the tenant of the current operation.
+type tenantKey struct{}
+
+// WithTenant returns the context of an operation of the tenant, which the operation
+// is measured under with the tenant breakdown.
+func WithTenant(ctx context.Context, tenant int) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
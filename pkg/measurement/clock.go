@@ -0,0 +1,90 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// The clock which timestamps the operations, selected by measurement.clock. The coarse
+// clock is a time a background goroutine advances every coarseResolution, which is
+// cheaper to read than time.Now at millions of operations per second, at the cost of
+// latencies only as precise as the resolution.
+var (
+	coarseClock      bool
+	coarseResolution time.Duration
+	// coarseBase is when the coarse clock started, and coarseElapsed the nanoseconds
+	// since, so that the coarse times keep the monotonic clock reading of coarseBase
+	coarseBase    time.Time
+	coarseElapsed int64
+	coarseStop    chan struct{}
+)
+
+func initClock(p *properties.Properties) error {
+	if coarseStop != nil {
+		close(coarseStop)
+		coarseStop = nil
+	}
+	coarseClock = false
+	switch clock := p.GetString(prop.Clock, prop.ClockDefault); clock {
+	case "monotonic":
+		return nil
+	case "coarse":
+	default:
+		return fmt.Errorf("unknown %s %q; expecting monotonic or coarse", prop.Clock, clock)
+	}
+	resolution, err := time.ParseDuration(p.GetString(prop.ClockResolution, prop.ClockResolutionDefault))
+	if err != nil || resolution <= 0 {
+		return fmt.Errorf("invalid %s", prop.ClockResolution)
+	}
+
+	coarseClock, coarseResolution = true, resolution
+	coarseBase = time.Now()
+	atomic.StoreInt64(&coarseElapsed, 0)
+	coarseStop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				atomic.StoreInt64(&coarseElapsed, int64(time.Since(coarseBase)))
+			case <-stop:
+				return
+			}
+		}
+	}(coarseStop)
+	return nil
+}
+
+// Now returns the current time of the clock the operations are measured with.
+func Now() time.Time {
+	if coarseClock {
+		return coarseBase.Add(time.Duration(atomic.LoadInt64(&coarseElapsed)))
+	}
+	return time.Now()
+}
+
+// clockSource describes the clock the operations are measured with.
+func clockSource() string {
+	if coarseClock {
+		return fmt.Sprintf("coarse, resolution %v", coarseResolution)
+	}
+	return "monotonic"
+}
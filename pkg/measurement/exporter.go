@@ -0,0 +1,139 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// exportPercentiles are the percentiles written by the JSON exporter unless measurement.percentiles is set.
+var exportPercentiles = []float64{50, 90, 95, 99, 99.9, 99.99}
+
+type opSummary struct {
+	Count       int64            `json:"count"`
+	Takes       float64          `json:"takes_s"`
+	OPS         float64          `json:"ops"`
+	Avg         int64            `json:"avg_us"`
+	Min         int64            `json:"min_us"`
+	Max         int64            `json:"max_us"`
+	Percentiles map[string]int64 `json:"percentiles_us"`
+}
+
+type runSummary struct {
+	Properties map[string]string     `json:"properties"`
+	Operations map[string]*opSummary `json:"operations"`
+	// Errors holds the failed operations, measured as <op>_ERROR, by operation.
+	Errors      map[string]*opSummary `json:"errors"`
+	TotalErrors int64                 `json:"total_errors"`
+	// Derived holds the measurements derived from the operations, such as their
+	// breakdowns, intended latencies, timeouts and retried attempts.
+	Derived map[string]*opSummary `json:"derived"`
+	// ErrorClasses counts the failed operations by the class of their error, e.g. "timeout".
+	ErrorClasses map[string]int64 `json:"error_classes"`
+	// ThroughputStability describes how the throughput varied over the measurement intervals.
+	ThroughputStability *stabilitySummary `json:"throughput_stability,omitempty"`
+}
+
+func (m *measurement) summary() *runSummary {
+	m.RLock()
+	defer m.RUnlock()
+
+	s := &runSummary{
+		Properties:   util.RedactedProperties(m.p),
+		Operations:   make(map[string]*opSummary),
+		Errors:       make(map[string]*opSummary),
+		Derived:      make(map[string]*opSummary),
+		ErrorClasses: make(map[string]int64, len(m.errorClasses)),
+	}
+	for class, count := range m.errorClasses {
+		s.ErrorClasses[class] = count
+	}
+	ps := m.reportPercentiles(exportPercentiles)
+	for op, opM := range m.opMeasurement {
+		h, ok := opM.(*histogram)
+		if !ok {
+			continue
+		}
+		info := h.getInfo()
+		summary := &opSummary{
+			Count:       info[COUNT].(int64),
+			Takes:       info[ELAPSED].(float64),
+			OPS:         info[QPS].(float64),
+			Avg:         info[AVG].(int64),
+			Min:         info[MIN].(int64),
+			Max:         info[MAX].(int64),
+			Percentiles: make(map[string]int64, len(ps)),
+		}
+		for i, v := range h.percentiles(ps) {
+			summary.Percentiles[fmt.Sprintf("p%g", ps[i])] = v
+		}
+
+		switch {
+		case IsDerived(op):
+			s.Derived[op] = summary
+		case strings.HasSuffix(op, "_ERROR"):
+			s.Errors[strings.TrimSuffix(op, "_ERROR")] = summary
+			s.TotalErrors += summary.Count
+		default:
+			s.Operations[op] = summary
+		}
+	}
+	return s
+}
+
+// Export writes the run summary with the exporter selected by the exporter property,
+// "json" or "junit", to the exportfile property or stdout. The default "text" exporter
+// writes nothing beyond the summary printed by Output. The summary is also written
+// to InfluxDB if influxdb.url or influxdb.file is set.
+func Export() error {
+	if err := globalMeasure.writeInfluxSummary(); err != nil {
+		return err
+	}
+
+	p := globalMeasure.p
+	exporter := p.GetString(prop.Exporter, "text")
+	switch exporter {
+	case "text":
+		return nil
+	case "json", "junit":
+	default:
+		return fmt.Errorf("unknown %s %q; expecting text, json or junit", prop.Exporter, exporter)
+	}
+
+	var w io.Writer = os.Stdout
+	if path := p.GetString(prop.ExportFile, ""); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	summary := globalMeasure.summary()
+	if exporter == "junit" {
+		return writeJUnit(w, summary, globalMeasure.slaResults())
+	}
+	summary.ThroughputStability = globalMeasure.stability()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summary)
+}
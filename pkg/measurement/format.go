@@ -0,0 +1,75 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// reportFormat is how the summaries and the CSV time series present latencies,
+// durations and timestamps.
+type reportFormat struct {
+	// summaryPercentiles are the percentiles printed by the histogram summaries
+	summaryPercentiles []float64
+	latencyUnit        string
+	durationFormat     string
+	timeFormat         string
+}
+
+func newReportFormat(p *properties.Properties, summaryPercentiles []float64) (*reportFormat, error) {
+	f := &reportFormat{
+		summaryPercentiles: summaryPercentiles,
+		latencyUnit:        p.GetString(prop.LatencyUnit, prop.LatencyUnitDefault),
+		durationFormat:     p.GetString(prop.DurationFormat, prop.DurationFormatDefault),
+		timeFormat:         p.GetString(prop.TimeSeriesTimeFormat, prop.TimeSeriesTimeFormatDefault),
+	}
+	if f.latencyUnit != "us" && f.latencyUnit != "ms" {
+		return nil, fmt.Errorf("unknown %s %q; expecting us or ms", prop.LatencyUnit, f.latencyUnit)
+	}
+	if f.durationFormat != "seconds" && f.durationFormat != "go" {
+		return nil, fmt.Errorf("unknown %s %q; expecting seconds or go", prop.DurationFormat, f.durationFormat)
+	}
+	if f.timeFormat != "rfc3339" && f.timeFormat != "epoch-ms" {
+		return nil, fmt.Errorf("unknown %s %q; expecting rfc3339 or epoch-ms", prop.TimeSeriesTimeFormat, f.timeFormat)
+	}
+	return f, nil
+}
+
+// latency formats a latency in microseconds in the latency unit.
+func (f *reportFormat) latency(us int64) string {
+	if f.latencyUnit == "ms" {
+		return strconv.FormatFloat(float64(us)/1000, 'f', 3, 64)
+	}
+	return strconv.FormatInt(us, 10)
+}
+
+// duration formats an elapsed time with its label, e.g. "Takes(s): 1.5".
+func (f *reportFormat) duration(label string, d time.Duration) string {
+	if f.durationFormat == "go" {
+		return fmt.Sprintf("%s: %v", label, d.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s(s): %.1f", label, d.Seconds())
+}
+
+func (f *reportFormat) timestamp(t time.Time) string {
+	if f.timeFormat == "epoch-ms" {
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// hdrHistogram is a minimal HdrHistogram with 3 significant digits, covering values
+// from 1 to hdrHighestTrackable. It uses the same bucket layout as the reference
+// implementation, so the percentile output matches the one of the HdrHistogram tools.
+type hdrHistogram struct {
+	counts []int64
+}
+
+const (
+	// an hour, in microseconds
+	hdrHighestTrackable = int64(3600 * 1000 * 1000)
+	// 2 * 10^3 rounded up to a power of two, for 3 significant digits
+	hdrSubBucketCount          = 2048
+	hdrSubBucketHalfCount      = hdrSubBucketCount / 2
+	hdrSubBucketHalfCountMagn  = 10
+	hdrSubBucketMask           = int64(hdrSubBucketCount - 1)
+	hdrTicksPerHalfDistance    = 5
+	hdrOutputValueScalingRatio = 1000.0 // microseconds to milliseconds
+)
+
+var hdrBucketCount = func() int {
+	smallestUntrackable := int64(hdrSubBucketCount)
+	count := 1
+	for smallestUntrackable <= hdrHighestTrackable {
+		smallestUntrackable <<= 1
+		count++
+	}
+	return count
+}()
+
+func newHdrHistogram() *hdrHistogram {
+	return &hdrHistogram{
+		counts: make([]int64, (hdrBucketCount+1)*hdrSubBucketHalfCount),
+	}
+}
+
+func hdrBucketIndex(v int64) int {
+	return 64 - bits.LeadingZeros64(uint64(v|hdrSubBucketMask)) - (hdrSubBucketHalfCountMagn + 1)
+}
+
+func hdrCountsIndex(v int64) int {
+	bucketIdx := hdrBucketIndex(v)
+	subBucketIdx := int(v >> uint(bucketIdx))
+	return (bucketIdx+1)<<hdrSubBucketHalfCountMagn + subBucketIdx - hdrSubBucketHalfCount
+}
+
+func hdrValueFromIndex(idx int) int64 {
+	bucketIdx := idx>>hdrSubBucketHalfCountMagn - 1
+	subBucketIdx := idx&(hdrSubBucketHalfCount-1) + hdrSubBucketHalfCount
+	if bucketIdx < 0 {
+		subBucketIdx -= hdrSubBucketHalfCount
+		bucketIdx = 0
+	}
+	return int64(subBucketIdx) << uint(bucketIdx)
+}
+
+// hdrEquivalentRange returns the lowest value and the size of the range of values
+// which are counted together with v.
+func hdrEquivalentRange(v int64) (int64, int64) {
+	bucketIdx := hdrBucketIndex(v)
+	subBucketIdx := v >> uint(bucketIdx)
+	size := int64(1) << uint(bucketIdx)
+	if subBucketIdx >= hdrSubBucketCount {
+		size <<= 1
+	}
+	return subBucketIdx << uint(bucketIdx), size
+}
+
+func hdrHighestEquivalent(v int64) int64 {
+	lowest, size := hdrEquivalentRange(v)
+	return lowest + size - 1
+}
+
+func hdrMedianEquivalent(v int64) int64 {
+	lowest, size := hdrEquivalentRange(v)
+	return lowest + size>>1
+}
+
+// Record records a value in microseconds. Values out of range are clamped.
+func (h *hdrHistogram) Record(v int64) {
+	if v < 0 {
+		v = 0
+	} else if v > hdrHighestTrackable {
+		v = hdrHighestTrackable
+	}
+	atomic.AddInt64(&h.counts[hdrCountsIndex(v)], 1)
+}
+
+// WritePercentiles writes the percentile distribution in the .hgrm format of
+// HdrHistogram's outputPercentileDistribution, with values in milliseconds.
+func (h *hdrHistogram) WritePercentiles(w io.Writer) error {
+	counts := make([]int64, len(h.counts))
+	total := int64(0)
+	for i := range counts {
+		counts[i] = atomic.LoadInt64(&h.counts[i])
+		total += counts[i]
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+
+	mean, stdDev := float64(0), float64(0)
+	maxValue := int64(0)
+	if total > 0 {
+		for i, count := range counts {
+			if count != 0 {
+				mean += float64(hdrMedianEquivalent(hdrValueFromIndex(i))) * float64(count)
+			}
+		}
+		mean /= float64(total)
+		for i, count := range counts {
+			if count != 0 {
+				d := float64(hdrMedianEquivalent(hdrValueFromIndex(i))) - mean
+				stdDev += d * d * float64(count)
+			}
+		}
+		stdDev = math.Sqrt(stdDev / float64(total))
+
+		percentile := float64(0)
+		cumulative := int64(0)
+		for i, count := range counts {
+			if count == 0 {
+				continue
+			}
+			cumulative += count
+			value := float64(hdrHighestEquivalent(hdrValueFromIndex(i))) / hdrOutputValueScalingRatio
+			if cumulative == total {
+				maxValue = hdrHighestEquivalent(hdrValueFromIndex(i))
+			}
+			current := 100 * float64(cumulative) / float64(total)
+			for percentile <= current {
+				fmt.Fprintf(buf, "%12.3f %2.12f %10d %14.2f\n", value, percentile/100, cumulative, 1/(1-percentile/100))
+				halfDistance := math.Pow(2, math.Floor(math.Log2(100/(100-percentile)))+1)
+				percentile += 100 / (hdrTicksPerHalfDistance * halfDistance)
+				// like the reference iterator, the last value gets a single line before the final 100% one
+				if cumulative == total {
+					break
+				}
+			}
+			if cumulative == total {
+				fmt.Fprintf(buf, "%12.3f %2.12f %10d\n", value, 1.0, cumulative)
+			}
+		}
+	}
+
+	fmt.Fprintf(buf, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", mean/hdrOutputValueScalingRatio, stdDev/hdrOutputValueScalingRatio)
+	fmt.Fprintf(buf, "#[Max     = %12.3f, Total count    = %12d]\n", float64(maxValue)/hdrOutputValueScalingRatio, total)
+	fmt.Fprintf(buf, "#[Buckets = %12d, SubBuckets     = %12d]\n", hdrBucketCount, hdrSubBucketCount)
+	return buf.Flush()
+}
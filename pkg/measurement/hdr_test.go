@@ -0,0 +1,54 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHdrHistogramIndex(t *testing.T) {
+	for _, v := range []int64{0, 1, 1000, 2047, 2048, 4097, 123456789, hdrHighestTrackable} {
+		lowest := hdrValueFromIndex(hdrCountsIndex(v))
+		if v < lowest || v > hdrHighestEquivalent(lowest) {
+			t.Fatalf("value %d is outside of its bucket [%d, %d]", v, lowest, hdrHighestEquivalent(lowest))
+		}
+		// 3 significant digits
+		if float64(hdrHighestEquivalent(v)-lowest) > float64(v)/1000+1 {
+			t.Fatalf("bucket of value %d is too wide: [%d, %d]", v, lowest, hdrHighestEquivalent(v))
+		}
+	}
+}
+
+func TestHdrHistogramPercentiles(t *testing.T) {
+	h := newHdrHistogram()
+	for v := int64(1); v <= 10000; v++ {
+		h.Record(v)
+	}
+	var buf bytes.Buffer
+	if err := h.WritePercentiles(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"       5.003 0.500000000000       5003           2.00\n",
+		"      10.007 1.000000000000      10000\n",
+		"#[Max     =       10.007, Total count    =        10000]\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("missing %q in\n%s", line, out)
+		}
+	}
+}
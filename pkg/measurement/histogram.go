@@ -34,8 +34,15 @@ type histogram struct {
 	min           int64
 	max           int64
 	startTime     time.Time
+	// hdr additionally records the latencies in full resolution if HdrHistogram output is enabled
+	hdr *hdrHistogram
+	// format is how Summary presents the measurements
+	format *reportFormat
 }
 
+// summaryPercentiles are the percentiles printed by Summary unless measurement.percentiles is set.
+var summaryPercentiles = []float64{99, 99.9, 99.99}
+
 // Metric name.
 const (
 	HistogramBuckets        = "histogram.buckets"
@@ -51,6 +58,13 @@ const (
 	PER99TH                 = "PER99TH"
 	PER999TH                = "PER999TH"
 	PER9999TH               = "PER9999TH"
+
+	// HdrHistogramFileOutput enables writing the latencies of every operation to
+	// <HdrHistogramOutputPath><op>.hgrm at the end of the run.
+	HdrHistogramFileOutput        = "hdrhistogram.fileoutput"
+	HdrHistogramFileOutputDefault = false
+	HdrHistogramOutputPath        = "hdrhistogram.output.path"
+	HdrHistogramOutputPathDefault = ""
 )
 
 func (h *histogram) Info() ycsb.MeasurementInfo {
@@ -59,19 +73,26 @@ func (h *histogram) Info() ycsb.MeasurementInfo {
 	return newHistogramInfo(res)
 }
 
-func newHistogram(p *properties.Properties) *histogram {
+func newHistogram(p *properties.Properties, format *reportFormat) *histogram {
 	h := new(histogram)
+	h.format = format
 	h.startTime = time.Now()
 	h.boundCounts = util.New(p.GetInt(ShardCount, ShardCountDefault))
 	h.boundInterval = p.GetInt64(HistogramBuckets, HistogramBucketsDefault)
 	h.min = math.MaxInt64
 	h.max = math.MinInt64
+	if p.GetBool(HdrHistogramFileOutput, HdrHistogramFileOutputDefault) {
+		h.hdr = newHdrHistogram()
+	}
 	return h
 }
 
 func (h *histogram) Measure(latency time.Duration) {
 	n := int64(latency / time.Microsecond)
 
+	if h.hdr != nil {
+		h.hdr.Record(n)
+	}
 	atomic.AddInt64(&h.sum, n)
 	atomic.AddInt64(&h.count, 1)
 	bound := int(n / h.boundInterval)
@@ -109,19 +130,89 @@ func (h *histogram) Summary() string {
 	res := h.getInfo()
 
 	buf := new(bytes.Buffer)
-	buf.WriteString(fmt.Sprintf("Takes(s): %.1f, ", res[ELAPSED]))
+	f := h.format
+	unit := f.latencyUnit
+	buf.WriteString(f.duration("Takes", time.Duration(res[ELAPSED].(float64)*float64(time.Second))) + ", ")
 	buf.WriteString(fmt.Sprintf("Count: %d, ", res[COUNT]))
 	buf.WriteString(fmt.Sprintf("OPS: %.1f, ", res[QPS]))
-	buf.WriteString(fmt.Sprintf("Avg(us): %d, ", res[AVG]))
-	buf.WriteString(fmt.Sprintf("Min(us): %d, ", res[MIN]))
-	buf.WriteString(fmt.Sprintf("Max(us): %d, ", res[MAX]))
-	buf.WriteString(fmt.Sprintf("99th(us): %d, ", res[PER99TH]))
-	buf.WriteString(fmt.Sprintf("99.9th(us): %d, ", res[PER999TH]))
-	buf.WriteString(fmt.Sprintf("99.99th(us): %d", res[PER9999TH]))
+	buf.WriteString(fmt.Sprintf("Avg(%s): %s, ", unit, f.latency(res[AVG].(int64))))
+	buf.WriteString(fmt.Sprintf("Min(%s): %s, ", unit, f.latency(res[MIN].(int64))))
+	buf.WriteString(fmt.Sprintf("Max(%s): %s", unit, f.latency(res[MAX].(int64))))
+	for i, v := range h.percentiles(f.summaryPercentiles) {
+		buf.WriteString(fmt.Sprintf(", %gth(%s): %s", f.summaryPercentiles[i], unit, f.latency(v)))
+	}
 
 	return buf.String()
 }
 
+type histogramSnapshot struct {
+	count    int64
+	sum      int64
+	interval int64
+	bounds   map[int]int64
+}
+
+// snapshot returns the raw counts of the histogram, with latencies in microseconds.
+func (h *histogram) snapshot() histogramSnapshot {
+	// Measure bumps count before the bounds, so read the bounds first to
+	// never report more bucketed latencies than the total count.
+	bounds := h.boundCounts.Items()
+	return histogramSnapshot{
+		count:    atomic.LoadInt64(&h.count),
+		sum:      atomic.LoadInt64(&h.sum),
+		interval: h.boundInterval,
+		bounds:   bounds,
+	}
+}
+
+// percentiles returns the upper bound in microseconds of the bucket holding each of
+// the given percentiles.
+func (h *histogram) percentiles(ps []float64) []int64 {
+	return h.snapshot().percentiles(ps)
+}
+
+// percentiles returns the upper bound in microseconds of the bucket holding each of
+// the given percentiles, which must be in increasing order.
+func (s histogramSnapshot) percentiles(ps []float64) []int64 {
+	bounds := make([]int, 0, len(s.bounds))
+	for bound := range s.bounds {
+		bounds = append(bounds, bound)
+	}
+	sort.Ints(bounds)
+
+	res := make([]int64, len(ps))
+	opCount := int64(0)
+	i := 0
+	for _, bound := range bounds {
+		opCount += s.bounds[bound]
+		for i < len(ps) && float64(opCount) >= ps[i]/100*float64(s.count) {
+			res[i] = (int64(bound) + 1) * s.interval
+			i++
+		}
+	}
+	// latencies counted but not bucketed yet are at most in the highest bucket so far
+	for ; i < len(ps) && len(bounds) > 0; i++ {
+		res[i] = (int64(bounds[len(bounds)-1]) + 1) * s.interval
+	}
+	return res
+}
+
+// sub returns the measurements recorded since the prev snapshot.
+func (s histogramSnapshot) sub(prev histogramSnapshot) histogramSnapshot {
+	bounds := make(map[int]int64, len(s.bounds))
+	for bound, count := range s.bounds {
+		if count -= prev.bounds[bound]; count > 0 {
+			bounds[bound] = count
+		}
+	}
+	return histogramSnapshot{
+		count:    s.count - prev.count,
+		sum:      s.sum - prev.sum,
+		interval: s.interval,
+		bounds:   bounds,
+	}
+}
+
 func (h *histogram) getInfo() map[string]interface{} {
 	min := atomic.LoadInt64(&h.min)
 	max := atomic.LoadInt64(&h.max)
@@ -0,0 +1,183 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// influxWriter writes measurements in the InfluxDB line protocol, to the write
+// endpoint of an InfluxDB server, a file, or both.
+type influxWriter struct {
+	url   string
+	token string
+	path  string
+	// tags are the tags shared by all the points, already escaped
+	tags string
+}
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+func influxEnabled(p *properties.Properties) bool {
+	return p.GetString(prop.InfluxDBURL, "") != "" || p.GetString(prop.InfluxDBFile, "") != ""
+}
+
+// influxWriter returns the InfluxDB writer of the measurement. The caller must
+// hold the time series lock.
+func (m *measurement) influxWriter() *influxWriter {
+	ts := &m.timeSeries
+	if ts.influx != nil {
+		return ts.influx
+	}
+
+	p := m.p
+	runID := p.GetString(prop.InfluxDBRunID, "")
+	if runID == "" {
+		runID = time.Now().Format("20060102-150405")
+	}
+	ts.influx = &influxWriter{
+		url:   p.GetString(prop.InfluxDBURL, ""),
+		token: p.GetString(prop.InfluxDBToken, ""),
+		path:  p.GetString(prop.InfluxDBFile, ""),
+		tags: fmt.Sprintf("run_id=%s,workload=%s,db=%s",
+			influxTagEscaper.Replace(runID),
+			influxTagEscaper.Replace(p.GetString(prop.Workload, "core")),
+			influxTagEscaper.Replace(p.GetString(prop.DB, "unknown"))),
+	}
+	return ts.influx
+}
+
+func (w *influxWriter) write(lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	body := []byte(strings.Join(lines, "\n") + "\n")
+
+	if w.path != "" {
+		// append, so that the file keeps the history of the runs
+		f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(body)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.url != "" {
+		req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if w.token != "" {
+			req.Header.Set("Authorization", "Token "+w.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("write to InfluxDB failed: %s", resp.Status)
+		}
+	}
+	return nil
+}
+
+func (w *influxWriter) writeInterval(now time.Time, stats []intervalStats, ps []float64) error {
+	lines := make([]string, 0, len(stats))
+	for _, s := range stats {
+		fields := []string{
+			fmt.Sprintf("count=%di", s.count),
+			fmt.Sprintf("throughput=%g", s.throughput),
+		}
+		for i, v := range s.percentiles {
+			fields = append(fields, fmt.Sprintf("p%g_us=%di", ps[i], v))
+		}
+		lines = append(lines, fmt.Sprintf("ycsb_interval,%s,op=%s %s %d",
+			w.tags, influxTagEscaper.Replace(s.op), strings.Join(fields, ","), now.UnixNano()))
+	}
+	return w.write(lines)
+}
+
+func (w *influxWriter) writeSummary(now time.Time, s *runSummary, ps []float64) error {
+	var lines []string
+	add := func(op string, summary *opSummary, errors bool) {
+		fields := []string{
+			fmt.Sprintf("count=%di", summary.Count),
+			fmt.Sprintf("takes_s=%g", summary.Takes),
+			fmt.Sprintf("ops=%g", summary.OPS),
+			fmt.Sprintf("avg_us=%di", summary.Avg),
+			fmt.Sprintf("min_us=%di", summary.Min),
+			fmt.Sprintf("max_us=%di", summary.Max),
+		}
+		for _, p := range ps {
+			name := fmt.Sprintf("p%g", p)
+			fields = append(fields, fmt.Sprintf("%s_us=%di", name, summary.Percentiles[name]))
+		}
+		lines = append(lines, fmt.Sprintf("ycsb_summary,%s,op=%s,errors=%t %s %d",
+			w.tags, influxTagEscaper.Replace(op), errors, strings.Join(fields, ","), now.UnixNano()))
+	}
+
+	ops := make([]string, 0, len(s.Operations))
+	for op := range s.Operations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		add(op, s.Operations[op], false)
+	}
+	ops = ops[:0]
+	for op := range s.Errors {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		add(op, s.Errors[op], true)
+	}
+	ops = ops[:0]
+	for op := range s.Derived {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		add(op, s.Derived[op], strings.Contains(op, "_ERROR"))
+	}
+	return w.write(lines)
+}
+
+func (m *measurement) writeInfluxSummary() error {
+	if !influxEnabled(m.p) {
+		return nil
+	}
+
+	ts := &m.timeSeries
+	ts.Lock()
+	defer ts.Unlock()
+	return m.influxWriter().writeSummary(time.Now(), m.summary(), m.reportPercentiles(exportPercentiles))
+}
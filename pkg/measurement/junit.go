@@ -0,0 +1,136 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitTestCase is an operation type, with its measurements as attributes and
+// properties, or an SLA check, which fails if the threshold is violated.
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       float64          `xml:"time,attr"`
+	Count      int64            `xml:"count,attr,omitempty"`
+	OPS        string           `xml:"ops,attr,omitempty"`
+	Avg        int64            `xml:"avg_us,attr,omitempty"`
+	Max        int64            `xml:"max_us,attr,omitempty"`
+	Errors     int64            `xml:"errors,attr,omitempty"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName    xml.Name        `xml:"testsuite"`
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       float64         `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+// writeJUnit writes the run summary as a JUnit XML test suite, with a test case for
+// every operation type, and for every SLA threshold.
+func writeJUnit(w io.Writer, s *runSummary, slas []slaResult) error {
+	suite := junitTestSuite{Name: "go-ycsb"}
+	if workload, ok := s.Properties["workload"]; ok {
+		suite.Name += "." + workload
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		suite.Properties = append(suite.Properties, junitProperty{Name: name, Value: s.Properties[name]})
+	}
+
+	ops := make([]string, 0, len(s.Operations))
+	for op := range s.Operations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		summary := s.Operations[op]
+		tc := junitTestCase{
+			Name:      op,
+			ClassName: "go-ycsb.operations",
+			Time:      summary.Takes,
+			Count:     summary.Count,
+			OPS:       fmt.Sprintf("%.1f", summary.OPS),
+			Avg:       summary.Avg,
+			Max:       summary.Max,
+		}
+		if errors, ok := s.Errors[op]; ok {
+			tc.Errors = errors.Count
+		}
+		percentiles := make([]string, 0, len(summary.Percentiles))
+		for p := range summary.Percentiles {
+			percentiles = append(percentiles, p)
+		}
+		sort.Strings(percentiles)
+		tc.Properties = new(junitProperties)
+		for _, p := range percentiles {
+			tc.Properties.Properties = append(tc.Properties.Properties,
+				junitProperty{Name: p + "_us", Value: fmt.Sprint(summary.Percentiles[p])})
+		}
+		if summary.Takes > suite.Time {
+			suite.Time = summary.Takes
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, r := range slas {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%s=%s", r.threshold.property, r.threshold.value),
+			ClassName: "go-ycsb.sla",
+		}
+		if r.violation != "" {
+			tc.Failure = &junitFailure{Message: r.violation}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
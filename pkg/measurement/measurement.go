@@ -15,13 +15,17 @@ package measurement
 
 import (
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
@@ -31,6 +35,40 @@ type measurement struct {
 	p *properties.Properties
 
 	opMeasurement map[string]ycsb.Measurement
+	// errorClasses counts the failed operations by the class of their error
+	errorClasses map[string]int64
+
+	timeSeries timeSeries
+
+	// percentiles are the percentiles set by measurement.percentiles, nil if unset
+	percentiles []float64
+	format      *reportFormat
+
+	// slas are the thresholds of the sla.* properties
+	slas []slaThreshold
+}
+
+// parsePercentiles parses a comma separated list of percentiles, and sorts them.
+func parsePercentiles(s string) ([]float64, error) {
+	var ps []float64
+	for _, field := range strings.Split(s, ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q", field)
+		}
+		ps = append(ps, p)
+	}
+	sort.Float64s(ps)
+	return ps, nil
+}
+
+// reportPercentiles returns the percentiles a report should include, def unless
+// measurement.percentiles is set.
+func (m *measurement) reportPercentiles(def []float64) []float64 {
+	if m.percentiles != nil {
+		return m.percentiles
+	}
+	return def
 }
 
 func (m *measurement) measure(op string, lan time.Duration) {
@@ -39,7 +77,7 @@ func (m *measurement) measure(op string, lan time.Duration) {
 	m.RUnlock()
 
 	if !ok {
-		opM = newHistogram(m.p)
+		opM = newHistogram(m.p, m.format)
 		m.Lock()
 		m.opMeasurement[op] = opM
 		m.Unlock()
@@ -62,6 +100,55 @@ func (m *measurement) output() {
 	for _, op := range keys {
 		fmt.Printf("%-6s - %s\n", op, m.opMeasurement[op].Summary())
 	}
+
+	if len(m.errorClasses) != 0 {
+		classes := make([]string, 0, len(m.errorClasses))
+		for _, class := range errorClassOrder {
+			if count, ok := m.errorClasses[class]; ok {
+				classes = append(classes, fmt.Sprintf("%s: %d", class, count))
+			}
+		}
+		fmt.Printf("%-6s - %s\n", "ERRORS", strings.Join(classes, ", "))
+	}
+}
+
+// errorClassOrder is the order the error classes are printed in.
+var errorClassOrder = []string{ycsb.ErrorClassTimeout, ycsb.ErrorClassNotFound, ycsb.ErrorClassConflict, ycsb.ErrorClassOther}
+
+func (m *measurement) measureError(class string) {
+	switch class {
+	case ycsb.ErrorClassTimeout, ycsb.ErrorClassNotFound, ycsb.ErrorClassConflict:
+	default:
+		class = ycsb.ErrorClassOther
+	}
+	m.Lock()
+	m.errorClasses[class]++
+	m.Unlock()
+}
+
+func (m *measurement) writeHdrHistograms() error {
+	m.RLock()
+	defer m.RUnlock()
+
+	path := m.p.GetString(HdrHistogramOutputPath, HdrHistogramOutputPathDefault)
+	for op, opM := range m.opMeasurement {
+		h, ok := opM.(*histogram)
+		if !ok || h.hdr == nil {
+			continue
+		}
+		f, err := os.Create(fmt.Sprintf("%s%s.hgrm", path, op))
+		if err != nil {
+			return err
+		}
+		err = h.hdr.WritePercentiles(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *measurement) info() map[string]ycsb.MeasurementInfo {
@@ -86,17 +173,67 @@ func (m *measurement) getOpName() []string {
 	return res
 }
 
+func newMeasurement(p *properties.Properties) *measurement {
+	m := new(measurement)
+	m.p = p
+	m.opMeasurement = make(map[string]ycsb.Measurement, 16)
+	m.errorClasses = make(map[string]int64)
+	m.timeSeries.prevTime = time.Now()
+	if s := p.GetString(prop.Percentiles, ""); s != "" {
+		var err error
+		if m.percentiles, err = parsePercentiles(s); err != nil {
+			util.Fatalf("parse %s failed %v", prop.Percentiles, err)
+		}
+	}
+	var err error
+	if m.format, err = newReportFormat(p, m.reportPercentiles(summaryPercentiles)); err != nil {
+		util.Fatalf("parse report format failed %v", err)
+	}
+	return m
+}
+
 // InitMeasure initializes the global measurement.
 func InitMeasure(p *properties.Properties) {
-	globalMeasure = new(measurement)
-	globalMeasure.p = p
-	globalMeasure.opMeasurement = make(map[string]ycsb.Measurement, 16)
+	globalMeasure = newMeasurement(p)
+	var err error
+	if samples, err = globalMeasure.newSampleWriter(); err != nil {
+		util.Fatalf("create raw sample file failed %v", err)
+	}
+	if err = initBreakdowns(p); err != nil {
+		util.Fatalf("parse breakdowns failed %v", err)
+	}
+	if err = initClock(p); err != nil {
+		util.Fatalf("init clock failed %v", err)
+	}
+	if globalMeasure.slas, err = parseSLAs(p); err != nil {
+		util.Fatalf("parse SLA thresholds failed %v", err)
+	}
+	warmUpMeasure = nil
+	if p.GetBool(prop.WarmUpReport, prop.WarmUpReportDefault) {
+		warmUpMeasure = newMeasurement(p)
+	}
 	EnableWarmUp(p.GetInt64(prop.WarmUpTime, 0) > 0)
 }
 
-// Output prints the measurement summary.
+// Output prints the measurement summary, and the clock the operations were measured with.
 func Output() {
 	globalMeasure.output()
+	fmt.Printf("%-6s - %s\n", "CLOCK", clockSource())
+}
+
+// OutputWarmUp prints the summary of the operations executed during warm-up, if they were measured.
+func OutputWarmUp() {
+	if warmUpMeasure == nil {
+		return
+	}
+	fmt.Println("Warm-up summary:")
+	warmUpMeasure.output()
+}
+
+// WriteHdrHistograms writes the HdrHistogram percentile distribution of every operation
+// to a .hgrm file, if enabled by hdrhistogram.fileoutput.
+func WriteHdrHistograms() error {
+	return globalMeasure.writeHdrHistograms()
 }
 
 // EnableWarmUp sets whether to enable warm-up.
@@ -105,6 +242,7 @@ func EnableWarmUp(b bool) {
 		atomic.StoreInt32(&warmUp, 1)
 	} else {
 		atomic.StoreInt32(&warmUp, 0)
+		globalMeasure.resetInterval()
 	}
 }
 
@@ -113,10 +251,23 @@ func IsWarmUpFinished() bool {
 	return atomic.LoadInt32(&warmUp) == 0
 }
 
-// Measure measures the operation.
+// Measure measures the operation. Operations during warm-up are left out of the
+// summary, and only measured separately if warmup.report is enabled.
 func Measure(op string, lan time.Duration) {
 	if IsWarmUpFinished() {
 		globalMeasure.measure(op, lan)
+	} else if warmUpMeasure != nil {
+		warmUpMeasure.measure(op, lan)
+	}
+}
+
+// MeasureError counts a failed operation under the class of its error, one of the
+// ycsb.ErrorClass constants.
+func MeasureError(class string) {
+	if IsWarmUpFinished() {
+		globalMeasure.measureError(class)
+	} else if warmUpMeasure != nil {
+		warmUpMeasure.measureError(class)
 	}
 }
 
@@ -132,4 +283,5 @@ func GetOpNames() []string {
 }
 
 var globalMeasure *measurement
+var warmUpMeasure *measurement
 var warmUp int32 // use as bool, 1 means in warmup progress, 0 means warmup finished.
@@ -0,0 +1,148 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// HistogramDump holds the raw counts of the histogram of an operation, with the
+// latencies in microseconds, which histograms of the same bucket size can be merged from.
+type HistogramDump struct {
+	Elapsed  float64 `json:"elapsed_s"`
+	Count    int64   `json:"count"`
+	Sum      int64   `json:"sum"`
+	Min      int64   `json:"min"`
+	Max      int64   `json:"max"`
+	Interval int64   `json:"interval"`
+	// Bounds are the counts of the buckets, keyed by bucket index
+	Bounds map[int]int64 `json:"bounds"`
+}
+
+// Histograms are the raw measurements of a run.
+type Histograms struct {
+	Operations   map[string]*HistogramDump `json:"operations"`
+	ErrorClasses map[string]int64          `json:"error_classes"`
+}
+
+func (m *measurement) histograms() *Histograms {
+	m.RLock()
+	defer m.RUnlock()
+
+	hs := &Histograms{
+		Operations:   make(map[string]*HistogramDump, len(m.opMeasurement)),
+		ErrorClasses: make(map[string]int64, len(m.errorClasses)),
+	}
+	for class, count := range m.errorClasses {
+		hs.ErrorClasses[class] = count
+	}
+	for op, opM := range m.opMeasurement {
+		h, ok := opM.(*histogram)
+		if !ok {
+			continue
+		}
+		s := h.snapshot()
+		hs.Operations[op] = &HistogramDump{
+			Elapsed:  time.Since(h.startTime).Seconds(),
+			Count:    s.count,
+			Sum:      s.sum,
+			Min:      h.min,
+			Max:      h.max,
+			Interval: s.interval,
+			Bounds:   s.bounds,
+		}
+	}
+	return hs
+}
+
+// WriteHistograms writes the raw histograms of every operation to measurement.histograms.file,
+// if set.
+func WriteHistograms() error {
+	path := globalMeasure.p.GetString(prop.HistogramsFile, "")
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(f).Encode(globalMeasure.histograms())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// InitMerged initializes the global measurement with the merged histograms of several
+// runs, e.g. to Output and Export their combined results. The throughput of an
+// operation is its total count over the longest of the runs.
+func InitMerged(p *properties.Properties, runs []*Histograms) error {
+	m := newMeasurement(p)
+	var err error
+	if m.slas, err = parseSLAs(p); err != nil {
+		return err
+	}
+	// the clock is only initialized to report the one the runs were measured with
+	if err = initClock(p); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		for class, count := range run.ErrorClasses {
+			m.errorClasses[class] += count
+		}
+		for op, d := range run.Operations {
+			opM, ok := m.opMeasurement[op]
+			if !ok {
+				h := newHistogram(p, m.format)
+				h.boundInterval = d.Interval
+				opM = h
+				m.opMeasurement[op] = h
+			}
+			if err := opM.(*histogram).merge(d); err != nil {
+				return fmt.Errorf("merge %s failed %v", op, err)
+			}
+		}
+	}
+	globalMeasure = m
+	warmUpMeasure = nil
+	return nil
+}
+
+func (h *histogram) merge(d *HistogramDump) error {
+	if d.Interval != h.boundInterval {
+		return fmt.Errorf("the bucket sizes %d and %d differ", d.Interval, h.boundInterval)
+	}
+	h.count += d.Count
+	h.sum += d.Sum
+	if d.Min < h.min {
+		h.min = d.Min
+	}
+	if d.Max > h.max {
+		h.max = d.Max
+	}
+	for bound, count := range d.Bounds {
+		prev, _ := h.boundCounts.Get(bound)
+		h.boundCounts.Set(bound, prev+count)
+	}
+	if start := time.Now().Add(-time.Duration(d.Elapsed * float64(time.Second))); start.Before(h.startTime) {
+		h.startTime = start
+	}
+	return nil
+}
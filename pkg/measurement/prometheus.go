@@ -0,0 +1,111 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// prometheusBuckets are the upper bounds in seconds of the exported latency buckets.
+var prometheusBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// writePrometheus writes the measurements in the Prometheus text exposition format.
+// Failed operations, measured as <op>_ERROR, are exported as the error count of <op>.
+// The measurements derived from the operations aren't exported, so that summing the
+// operations doesn't count them again.
+func (m *measurement) writePrometheus(w io.Writer) {
+	m.RLock()
+	ops := make([]string, 0, len(m.opMeasurement))
+	histograms := make(map[string]*histogram, len(m.opMeasurement))
+	for op, opM := range m.opMeasurement {
+		if IsDerived(op) {
+			continue
+		}
+		if h, ok := opM.(*histogram); ok {
+			ops = append(ops, op)
+			histograms[op] = h
+		}
+	}
+	m.RUnlock()
+	sort.Strings(ops)
+
+	fmt.Fprintln(w, "# HELP ycsb_operations_total Number of successful operations.")
+	fmt.Fprintln(w, "# TYPE ycsb_operations_total counter")
+	for _, op := range ops {
+		if !strings.HasSuffix(op, "_ERROR") {
+			fmt.Fprintf(w, "ycsb_operations_total{operation=%q} %d\n", op, histograms[op].snapshot().count)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP ycsb_operation_errors_total Number of failed operations.")
+	fmt.Fprintln(w, "# TYPE ycsb_operation_errors_total counter")
+	for _, op := range ops {
+		if strings.HasSuffix(op, "_ERROR") {
+			fmt.Fprintf(w, "ycsb_operation_errors_total{operation=%q} %d\n", strings.TrimSuffix(op, "_ERROR"), histograms[op].snapshot().count)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP ycsb_operation_latency_seconds Latency of successful operations.")
+	fmt.Fprintln(w, "# TYPE ycsb_operation_latency_seconds histogram")
+	for _, op := range ops {
+		if strings.HasSuffix(op, "_ERROR") {
+			continue
+		}
+		s := histograms[op].snapshot()
+		cumulative := make([]int64, len(prometheusBuckets))
+		for bound, count := range s.bounds {
+			// a bound counts the latencies below its upper end
+			upper := float64((int64(bound)+1)*s.interval) / 1e6
+			for i, le := range prometheusBuckets {
+				if upper <= le {
+					cumulative[i] += count
+				}
+			}
+		}
+		for i, le := range prometheusBuckets {
+			fmt.Fprintf(w, "ycsb_operation_latency_seconds_bucket{operation=%q,le=\"%g\"} %d\n", op, le, cumulative[i])
+		}
+		fmt.Fprintf(w, "ycsb_operation_latency_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op, s.count)
+		fmt.Fprintf(w, "ycsb_operation_latency_seconds_sum{operation=%q} %g\n", op, float64(s.sum)/1e6)
+		fmt.Fprintf(w, "ycsb_operation_latency_seconds_count{operation=%q} %d\n", op, s.count)
+	}
+}
+
+func servePrometheusMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	buf := bufio.NewWriter(w)
+	globalMeasure.writePrometheus(buf)
+	buf.Flush()
+}
+
+// ServePrometheus exposes the live measurements as Prometheus metrics at /metrics on addr.
+// It returns once the address is bound, and serves in the background.
+func ServePrometheus(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", servePrometheusMetrics)
+	go func() {
+		_ = http.Serve(l, mux)
+	}()
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// sampleBufferSize is the write buffer of the raw sample file.
+const sampleBufferSize = 1 << 20
+
+// sampleWriter writes every measured operation to the raw sample file.
+type sampleWriter struct {
+	sync.Mutex
+	binary bool
+	format *reportFormat
+	f      *os.File
+	w      *bufio.Writer
+	// err is the first write error, after which the samples are dropped
+	err error
+}
+
+func (m *measurement) newSampleWriter() (*sampleWriter, error) {
+	path := m.p.GetString(prop.SamplesFile, "")
+	if path == "" {
+		return nil, nil
+	}
+	s := &sampleWriter{format: m.format}
+	switch format := m.p.GetString(prop.SamplesFormat, prop.SamplesFormatDefault); format {
+	case "csv":
+	case "binary":
+		s.binary = true
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting csv or binary", prop.SamplesFormat, format)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	s.f = f
+	s.w = bufio.NewWriterSize(f, sampleBufferSize)
+	if !s.binary {
+		fmt.Fprintf(s.w, "timestamp,op,latency_%s,status\n", s.format.latencyUnit)
+	}
+	return s, nil
+}
+
+// write writes a sample. A binary sample is, in little endian, the start time in
+// nanoseconds since the epoch (int64), the latency in nanoseconds (int64), the
+// status (0 ok, 1 error), the length of the operation name (uint8) and the name.
+func (s *sampleWriter) write(start time.Time, op string, latency time.Duration, failed bool) {
+	s.Lock()
+	defer s.Unlock()
+	if s.err != nil {
+		return
+	}
+
+	if s.binary {
+		var header [18]byte
+		binary.LittleEndian.PutUint64(header[0:], uint64(start.UnixNano()))
+		binary.LittleEndian.PutUint64(header[8:], uint64(latency))
+		if failed {
+			header[16] = 1
+		}
+		header[17] = byte(len(op))
+		s.w.Write(header[:])
+		_, s.err = s.w.WriteString(op)
+		return
+	}
+
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	_, s.err = fmt.Fprintf(s.w, "%s,%s,%s,%s\n", s.format.timestamp(start), op,
+		s.format.latency(int64(latency/time.Microsecond)), status)
+}
+
+func (s *sampleWriter) close() error {
+	s.Lock()
+	defer s.Unlock()
+	err := s.w.Flush()
+	if closeErr := s.f.Close(); err == nil {
+		err = closeErr
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return err
+}
+
+var samples *sampleWriter
+
+// RecordSample writes an operation to the raw sample file, if measurement.samples.file
+// is set. Like Measure, it leaves out the operations during warm-up.
+func RecordSample(start time.Time, op string, latency time.Duration, err error) {
+	if samples == nil || !IsWarmUpFinished() {
+		return
+	}
+	samples.write(start, op, latency, err != nil)
+}
+
+// CloseSamples flushes and closes the raw sample file. No sample may be recorded after it.
+func CloseSamples() error {
+	if samples == nil {
+		return nil
+	}
+	err := samples.close()
+	samples = nil
+	return err
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// slaThreshold is a limit on a measurement of the run, set by a sla.* property.
+type slaThreshold struct {
+	// property is the sla.* property of the threshold
+	property string
+	value    string
+
+	// op is the measured operation of a latency threshold, empty for the
+	// throughput and error thresholds
+	op         string
+	stat       string
+	percentile float64
+	latency    time.Duration
+	limit      float64
+}
+
+// parseSLAs parses the thresholds of the sla.<op>.<p99|avg|max>=<duration>,
+// sla.throughput.min=<ops> and sla.errors.max=<count> properties.
+func parseSLAs(p *properties.Properties) ([]slaThreshold, error) {
+	slas := p.FilterStripPrefix(prop.SLAPrefix)
+	keys := slas.Keys()
+	sort.Strings(keys)
+
+	thresholds := make([]slaThreshold, 0, len(keys))
+	for _, key := range keys {
+		t := slaThreshold{property: prop.SLAPrefix + key, value: slas.GetString(key, "")}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid %s, expecting %s<op>.<stat>", t.property, prop.SLAPrefix)
+		}
+		target, stat := key[:i], key[i+1:]
+		t.stat = stat
+
+		var err error
+		switch {
+		case target == "throughput" && stat == "min", target == "errors" && stat == "max":
+			t.limit, err = strconv.ParseFloat(t.value, 64)
+		case stat == "avg" || stat == "max":
+			t.op = strings.ToUpper(target)
+			t.latency, err = time.ParseDuration(t.value)
+		case strings.HasPrefix(stat, "p"):
+			t.op = strings.ToUpper(target)
+			if t.percentile, err = strconv.ParseFloat(stat[1:], 64); err != nil || t.percentile <= 0 || t.percentile > 100 {
+				return nil, fmt.Errorf("invalid percentile of %s", t.property)
+			}
+			t.latency, err = time.ParseDuration(t.value)
+		default:
+			return nil, fmt.Errorf("unknown %s; expecting %s<op>.<pNN|avg|max>, %sthroughput.min or %serrors.max",
+				t.property, prop.SLAPrefix, prop.SLAPrefix, prop.SLAPrefix)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q of %s: %v", t.value, t.property, err)
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// checkSLA returns a description of the violation of the threshold, or an empty string
+// if the measurements are within it.
+func (m *measurement) checkSLA(t slaThreshold) string {
+	m.RLock()
+	defer m.RUnlock()
+
+	if t.op == "" {
+		total := float64(0)
+		for op, opM := range m.opMeasurement {
+			h, ok := opM.(*histogram)
+			if !ok || IsDerived(op) {
+				continue
+			}
+			isError := strings.HasSuffix(op, "_ERROR")
+			info := h.getInfo()
+			if t.stat == "min" && !isError {
+				total += info[QPS].(float64)
+			} else if t.stat == "max" && isError {
+				total += float64(info[COUNT].(int64))
+			}
+		}
+		if t.stat == "min" && total < t.limit {
+			return fmt.Sprintf("measured %.1f OPS", total)
+		}
+		if t.stat == "max" && total > t.limit {
+			return fmt.Sprintf("measured %d errors", int64(total))
+		}
+		return ""
+	}
+
+	h, ok := m.opMeasurement[t.op].(*histogram)
+	if !ok {
+		return fmt.Sprintf("no %s operations were measured", t.op)
+	}
+	var measured int64
+	switch t.stat {
+	case "avg":
+		measured = h.getInfo()[AVG].(int64)
+	case "max":
+		measured = h.getInfo()[MAX].(int64)
+	default:
+		measured = h.percentiles([]float64{t.percentile})[0]
+	}
+	if latency := time.Duration(measured) * time.Microsecond; latency > t.latency {
+		return fmt.Sprintf("measured %v", latency)
+	}
+	return ""
+}
+
+// slaResult is a threshold with the description of its violation, empty if it was met.
+type slaResult struct {
+	threshold slaThreshold
+	violation string
+}
+
+func (m *measurement) slaResults() []slaResult {
+	results := make([]slaResult, 0, len(m.slas))
+	for _, t := range m.slas {
+		results = append(results, slaResult{threshold: t, violation: m.checkSLA(t)})
+	}
+	return results
+}
+
+// CheckSLA checks the measurements against the thresholds of the sla.* properties,
+// prints the violated ones, and returns whether they were all met.
+func CheckSLA() bool {
+	m := globalMeasure
+	if len(m.slas) == 0 {
+		return true
+	}
+
+	violations := 0
+	for _, r := range m.slaResults() {
+		if r.violation != "" {
+			fmt.Printf("SLA violated - %s=%s, %s\n", r.threshold.property, r.threshold.value, r.violation)
+			violations++
+		}
+	}
+	if violations > 0 {
+		return false
+	}
+	fmt.Printf("SLA met - %d thresholds\n", len(m.slas))
+	return true
+}
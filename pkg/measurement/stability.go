@@ -0,0 +1,122 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// stabilitySummary describes how the throughput of the successful operations varied
+// over the measurement intervals.
+type stabilitySummary struct {
+	Windows   int     `json:"windows"`
+	WindowSec float64 `json:"window_s"`
+	Min       float64 `json:"min_ops"`
+	Max       float64 `json:"max_ops"`
+	Avg       float64 `json:"avg_ops"`
+	StdDev    float64 `json:"stddev_ops"`
+	// Threshold is the percentage of Avg below which a window counts as a stall.
+	Threshold float64 `json:"threshold_pct"`
+	// LongestStall is the longest run of consecutive windows below the threshold.
+	LongestStall    int     `json:"longest_stall_windows"`
+	LongestStallSec float64 `json:"longest_stall_s"`
+	// WriteStalls are the intervals in which the median write latency jumped while the
+	// median read latency stayed flat.
+	WriteStalls   int     `json:"write_stalls"`
+	WriteStallSec float64 `json:"write_stall_s"`
+}
+
+// recordWindow keeps the throughput of the successful operations of an interval.
+// An interval shorter than half the measurement interval, like the last one of a
+// run, is left out as it would skew the statistics.
+func (m *measurement) recordWindow(elapsed time.Duration, stats []intervalStats) {
+	interval := time.Duration(m.p.GetInt64(prop.LogInterval, 10)) * time.Second
+	if elapsed < interval/2 {
+		return
+	}
+
+	total := float64(0)
+	for _, s := range stats {
+		if IsDerived(s.op) || strings.HasSuffix(s.op, "_ERROR") {
+			continue
+		}
+		total += s.throughput
+	}
+	m.timeSeries.windows = append(m.timeSeries.windows, total)
+	m.timeSeries.windowTime += elapsed
+}
+
+func (m *measurement) stability() *stabilitySummary {
+	ts := &m.timeSeries
+	ts.Lock()
+	defer ts.Unlock()
+
+	if len(ts.windows) == 0 {
+		return nil
+	}
+	s := &stabilitySummary{
+		Windows:   len(ts.windows),
+		WindowSec: ts.windowTime.Seconds() / float64(len(ts.windows)),
+		Min:       math.Inf(1),
+		Threshold: m.p.GetFloat64(prop.StabilityThreshold, prop.StabilityThresholdDefault),
+	}
+	for _, ops := range ts.windows {
+		s.Min = math.Min(s.Min, ops)
+		s.Max = math.Max(s.Max, ops)
+		s.Avg += ops
+	}
+	s.Avg /= float64(len(ts.windows))
+
+	stall := 0
+	for _, ops := range ts.windows {
+		d := ops - s.Avg
+		s.StdDev += d * d
+		if ops < s.Avg*s.Threshold/100 {
+			stall++
+			if stall > s.LongestStall {
+				s.LongestStall = stall
+			}
+		} else {
+			stall = 0
+		}
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(len(ts.windows)))
+	s.LongestStallSec = float64(s.LongestStall) * s.WindowSec
+	s.WriteStalls = ts.writeStalls.count
+	s.WriteStallSec = ts.writeStalls.time.Seconds()
+	return s
+}
+
+// OutputStability prints the min, max and standard deviation of the throughput over
+// the measurement intervals, the longest run of intervals below
+// measurement.stability.threshold percent of the average, and the write stalls.
+func OutputStability() {
+	s := globalMeasure.stability()
+	if s == nil {
+		return
+	}
+	f := globalMeasure.format
+	fmt.Printf("THROUGHPUT - Windows: %d, Min: %.1f, Max: %.1f, Avg: %.1f, StdDev: %.1f, Longest below %g%% of avg: %d windows, %s\n",
+		s.Windows, s.Min, s.Max, s.Avg, s.StdDev, s.Threshold, s.LongestStall,
+		f.duration("Stall", time.Duration(s.LongestStallSec*float64(time.Second))))
+	if s.WriteStalls > 0 {
+		fmt.Printf("%-6s - Intervals: %d, %s\n", "WRITE STALL", s.WriteStalls,
+			f.duration("Total", time.Duration(s.WriteStallSec*float64(time.Second))))
+	}
+}
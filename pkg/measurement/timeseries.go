@@ -0,0 +1,185 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+var timeSeriesPercentiles = []float64{50, 95, 99}
+
+// intervalStats are the measurements of an operation over an interval.
+type intervalStats struct {
+	op          string
+	count       int64
+	throughput  float64
+	percentiles []int64
+	p50         int64
+}
+
+// intervalTracker keeps the snapshots of the histograms at the end of the last interval.
+type intervalTracker struct {
+	prev     map[string]histogramSnapshot
+	prevTime time.Time
+}
+
+// timeSeries writes the measurements of every interval as CSV rows, and to InfluxDB.
+type timeSeries struct {
+	sync.Mutex
+	intervalTracker
+	f      *os.File
+	w      *bufio.Writer
+	influx *influxWriter
+
+	// the throughput of every interval, for the stability summary
+	windows    []float64
+	windowTime time.Duration
+
+	writeStalls writeStalls
+}
+
+// nextInterval returns the measurements since the last interval, by operation name,
+// with the given percentiles.
+func (m *measurement) nextInterval(now time.Time, ps []float64) []intervalStats {
+	return m.timeSeries.next(m, now, ps)
+}
+
+func (ts *intervalTracker) next(m *measurement, now time.Time, ps []float64) []intervalStats {
+	if ts.prev == nil {
+		ts.prev = make(map[string]histogramSnapshot)
+	}
+
+	m.RLock()
+	ops := make([]string, 0, len(m.opMeasurement))
+	snapshots := make(map[string]histogramSnapshot, len(m.opMeasurement))
+	for op, opM := range m.opMeasurement {
+		if h, ok := opM.(*histogram); ok {
+			ops = append(ops, op)
+			snapshots[op] = h.snapshot()
+		}
+	}
+	m.RUnlock()
+	sort.Strings(ops)
+
+	elapsed := now.Sub(ts.prevTime).Seconds()
+	stats := make([]intervalStats, 0, len(ops))
+	for _, op := range ops {
+		s := snapshots[op].sub(ts.prev[op])
+		throughput := float64(0)
+		if elapsed > 0 {
+			throughput = float64(s.count) / elapsed
+		}
+		stats = append(stats, intervalStats{
+			op:          op,
+			count:       s.count,
+			throughput:  throughput,
+			percentiles: s.percentiles(ps),
+			p50:         s.percentiles([]float64{50})[0],
+		})
+		ts.prev[op] = snapshots[op]
+	}
+	ts.prevTime = now
+	return stats
+}
+
+func (m *measurement) writeInterval() error {
+	path := m.p.GetString(prop.TimeSeriesFile, "")
+	influx := influxEnabled(m.p)
+
+	ts := &m.timeSeries
+	ts.Lock()
+	defer ts.Unlock()
+	now := time.Now()
+	ps := m.reportPercentiles(timeSeriesPercentiles)
+	elapsed := now.Sub(ts.prevTime)
+	stats := m.nextInterval(now, ps)
+	m.recordWindow(elapsed, stats)
+	m.recordWriteStall(elapsed, stats)
+
+	if influx {
+		if err := m.influxWriter().writeInterval(now, stats, ps); err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	if ts.f == nil {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		ts.f = f
+		ts.w = bufio.NewWriter(f)
+		header := "timestamp,op,count,throughput"
+		for _, p := range ps {
+			header += fmt.Sprintf(",p%g_%s", p, m.format.latencyUnit)
+		}
+		fmt.Fprintln(ts.w, header)
+	}
+	for _, s := range stats {
+		fmt.Fprintf(ts.w, "%s,%s,%d,%.1f", m.format.timestamp(now), s.op, s.count, s.throughput)
+		for _, v := range s.percentiles {
+			fmt.Fprintf(ts.w, ",%s", m.format.latency(v))
+		}
+		fmt.Fprintln(ts.w)
+	}
+	return ts.w.Flush()
+}
+
+// OutputInterval ends a measurement interval. It writes the measurements since the
+// last call to the CSV time series file if measurement.timeseries.file is set, and
+// to InfluxDB if influxdb.url or influxdb.file is set.
+func OutputInterval() error {
+	return globalMeasure.writeInterval()
+}
+
+// IntervalStats are the measurements of an operation over an interval, with the
+// latency percentiles in microseconds.
+type IntervalStats struct {
+	Op          string
+	Count       int64
+	Throughput  float64
+	Percentiles []int64
+}
+
+// NewIntervals returns a function which returns the measurements of every operation
+// since its previous call, or since NewIntervals for the first one, independently of
+// the intervals of OutputInterval.
+func NewIntervals() func(ps []float64) []IntervalStats {
+	ts := &intervalTracker{prevTime: time.Now()}
+	return func(ps []float64) []IntervalStats {
+		stats := ts.next(globalMeasure, time.Now(), ps)
+		res := make([]IntervalStats, 0, len(stats))
+		for _, s := range stats {
+			res = append(res, IntervalStats{Op: s.op, Count: s.count, Throughput: s.throughput, Percentiles: s.percentiles})
+		}
+		return res
+	}
+}
+
+// resetInterval starts the first interval, once warm-up is over.
+func (m *measurement) resetInterval() {
+	ts := &m.timeSeries
+	ts.Lock()
+	ts.prevTime = time.Now()
+	ts.Unlock()
+}
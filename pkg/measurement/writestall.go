@@ -0,0 +1,95 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// baselineDecay is the weight of a new interval in the baseline median latencies.
+const baselineDecay = 0.3
+
+var (
+	writeStallWrites = map[string]bool{
+		"INSERT": true, "UPDATE": true, "DELETE": true,
+		"BATCH_INSERT": true, "BATCH_UPDATE": true, "BATCH_DELETE": true,
+	}
+	writeStallReads = map[string]bool{"READ": true, "SCAN": true, "BATCH_READ": true}
+)
+
+// writeStalls detects the intervals in which the median write latency jumps while
+// the median read latency stays flat, as compactions and flushes of LSM trees do.
+type writeStalls struct {
+	writeBaseline float64
+	readBaseline  float64
+
+	count int
+	time  time.Duration
+	// last describes the stall of the last interval, empty if it didn't stall
+	last string
+}
+
+// medians returns the highest median latency of the operations of the interval
+// the ops map selects, and 0 if there were none.
+func medians(stats []intervalStats, ops map[string]bool) float64 {
+	median := float64(0)
+	for _, s := range stats {
+		if ops[s.op] && s.count > 0 && float64(s.p50) > median {
+			median = float64(s.p50)
+		}
+	}
+	return median
+}
+
+// recordWriteStall checks whether the interval stalled against the baselines of the
+// previous intervals which didn't.
+func (m *measurement) recordWriteStall(elapsed time.Duration, stats []intervalStats) {
+	factor := m.p.GetFloat64(prop.WriteStallFactor, prop.WriteStallFactorDefault)
+	readFactor := m.p.GetFloat64(prop.WriteStallReadFactor, prop.WriteStallReadFactorDefault)
+	w := &m.timeSeries.writeStalls
+	w.last = ""
+	write, read := medians(stats, writeStallWrites), medians(stats, writeStallReads)
+	if factor <= 0 || write == 0 || read == 0 {
+		return
+	}
+	if w.writeBaseline == 0 {
+		w.writeBaseline, w.readBaseline = write, read
+		return
+	}
+
+	if write >= factor*w.writeBaseline && read <= readFactor*w.readBaseline {
+		w.count++
+		w.time += elapsed
+		f := m.format
+		w.last = fmt.Sprintf("Write p50(%s): %s (%.1fx baseline), Read p50(%s): %s (%.1fx baseline)",
+			f.latencyUnit, f.latency(int64(write)), write/w.writeBaseline,
+			f.latencyUnit, f.latency(int64(read)), read/w.readBaseline)
+		return
+	}
+	w.writeBaseline += baselineDecay * (write - w.writeBaseline)
+	w.readBaseline += baselineDecay * (read - w.readBaseline)
+}
+
+// OutputWriteStall prints a marker if the last interval of OutputInterval was a write stall.
+func OutputWriteStall() {
+	ts := &globalMeasure.timeSeries
+	ts.Lock()
+	defer ts.Unlock()
+	if ts.writeStalls.last != "" {
+		fmt.Printf("%-6s - %s\n", "WRITE STALL", ts.writeStalls.last)
+	}
+}
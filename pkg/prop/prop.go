@@ -19,6 +19,20 @@ const (
 	InsertCount        = "insertcount"
 	InsertStartDefault = int64(0)
 
+	// LoaderIndex and LoaderTotal split the records between LoaderTotal go-ycsb
+	// instances, deriving the insertstart and insertcount of the instance LoaderIndex
+	// (from 0) from the ones of the whole keyspace.
+	LoaderIndex = "loader.index"
+	LoaderTotal = "loader.total"
+
+	// LoadCheckpoint is the file the load saves how far it got to every
+	// LoadCheckpointInterval, so that with LoadResume an interrupted load resumes
+	// from there.
+	LoadCheckpoint                = "load.checkpoint"
+	LoadCheckpointInterval        = "load.checkpointinterval"
+	LoadCheckpointIntervalDefault = "10s"
+	LoadResume                    = "load.resume"
+
 	OperationCount     = "operationcount"
 	RecordCount        = "recordcount"
 	RecordCountDefault = int64(0)
@@ -28,16 +42,72 @@ const (
 	ExportFile         = "exportfile"
 	ThreadCount        = "threadcount"
 	ThreadCountDefault = int64(200)
-	Target             = "target"
-	MaxExecutiontime   = "maxexecutiontime"
-	WarmUpTime         = "warmuptime"
-	DoTransactions     = "dotransactions"
-	Status             = "status"
-	Label              = "label"
+	// ThreadPools dedicates groups of threads to some operation types, with independent
+	// targets and optionally their own proportions, e.g. "scans:4:scan:100,point:28:
+	// read=0.9|update=0.1". It sets the thread count to the total of the pools.
+	ThreadPools = "threadpools"
+	Target      = "target"
+	// MaxExecutiontime is the seconds after which the run ends, unless OperationCount
+	// operations were executed first.
+	MaxExecutiontime = "maxexecutiontime"
+	// ShutdownGrace is how long the process may take to output the results and exit
+	// once interrupted, before it exits anyway, 0 for no limit.
+	ShutdownGrace        = "shutdowngrace"
+	ShutdownGraceDefault = "30s"
+	WarmUpTime           = "warmuptime"
+	// WarmUpReport prints the operations executed during warm-up in a separate summary.
+	WarmUpReport        = "warmup.report"
+	WarmUpReportDefault = false
+	DoTransactions      = "dotransactions"
+	Status              = "status"
+	Label               = "label"
 	// batch mode
 	BatchSize        = "batch.size"
 	DefaultBatchSize = int(1)
 
+	// ThreadRamp starts the threads gradually over this duration rather than all at
+	// once, in ThreadRampSteps steps of as many threads, one thread per step by default.
+	ThreadRamp        = "threadramp"
+	ThreadRampDefault = "0s"
+	ThreadRampSteps   = "threadramp.steps"
+
+	// TargetSchedule changes the target throughput during the run, as "offset:target"
+	// steps with the offsets in seconds or as durations, e.g. "0:1000,2m:5000". It
+	// replaces Target.
+	TargetSchedule = "targetschedule"
+
+	// ThinkTime pauses every thread for this mean duration between its operations,
+	// on top of the Target throttling, drawn from ThinkTimeDistribution.
+	ThinkTime                    = "thinktime"
+	ThinkTimeDefault             = "0s"
+	ThinkTimeDistribution        = "thinktime.distribution"
+	ThinkTimeDistributionDefault = "constant"
+
+	// PipelineInflight is the number of operations every thread keeps in flight, submitting
+	// the next one without waiting for the previous ones to complete.
+	PipelineInflight        = "pipeline.inflight"
+	PipelineInflightDefault = 1
+
+	// BackupFile is the file the backup-restore command backs the table up to, a
+	// temporary file if unset, and BackupRestoreTable the other table it restores it
+	// to, which is required.
+	BackupFile         = "backup.file"
+	BackupRestoreTable = "backup.restoretable"
+
+	// KeyspaceGrowth grows the keyspace by this percentage of its size every
+	// KeyspaceGrowthInterval during the transaction phase, with inserts issued
+	// independently of the workers.
+	KeyspaceGrowth                = "keyspace.growth"
+	KeyspaceGrowthInterval        = "keyspace.growthinterval"
+	KeyspaceGrowthIntervalDefault = "1m"
+
+	// CacheProbeKeys is the number of random loaded keys the cache probe reads after
+	// the run, CacheProbeBurst times in a row each, to compare the latency of the
+	// first, cold, read to the next, warm, ones on every endpoint. 0 disables it.
+	CacheProbeKeys         = "cacheprobe.keys"
+	CacheProbeBurst        = "cacheprobe.burst"
+	CacheProbeBurstDefault = int64(5)
+
 	TableName         = "table"
 	TableNameDefault  = "usertable"
 	FieldCount        = "fieldcount"
@@ -48,24 +118,104 @@ const (
 	FieldLength                    = "fieldlength"
 	FieldLengthDefault             = int64(100)
 	// Used if fieldlengthdistribution is "histogram"
-	FieldLengthHistogramFile         = "fieldlengthhistogram"
-	FieldLengthHistogramFileDefault  = "hist.txt"
-	ReadAllFields                    = "readallfields"
-	ReadALlFieldsDefault             = true
-	WriteAllFields                   = "writeallfields"
-	WriteAllFieldsDefault            = false
-	DataIntegrity                    = "dataintegrity"
-	DataIntegrityDefault             = false
-	ReadProportion                   = "readproportion"
-	ReadProportionDefault            = float64(0.95)
-	UpdateProportion                 = "updateproportion"
-	UpdateProportionDefault          = float64(0.05)
-	InsertProportion                 = "insertproportion"
-	InsertProportionDefault          = float64(0.0)
-	ScanProportion                   = "scanproportion"
-	ScanProportionDefault            = float64(0.0)
-	ReadModifyWriteProportion        = "readmodifywriteproportion"
-	ReadModifyWriteProportionDefault = float64(0.0)
+	FieldLengthHistogramFile        = "fieldlengthhistogram"
+	FieldLengthHistogramFileDefault = "hist.txt"
+	ReadAllFields                   = "readallfields"
+	ReadALlFieldsDefault            = true
+	// ReadFieldCount is the number of fields every read projects, instead of all or one
+	// as selected by ReadAllFields, 0 to leave it to ReadAllFields. With a "uniform" or
+	// "zipfian" ReadFieldCountDistribution, the count of every read is drawn between 1
+	// and ReadFieldCount. The fields are drawn by ReadFieldDistribution, "uniform" or
+	// "zipfian" to favor the first ones.
+	ReadFieldCount                    = "readfieldcount"
+	ReadFieldCountDistribution        = "readfieldcountdistribution"
+	ReadFieldCountDistributionDefault = "constant"
+	ReadFieldDistribution             = "readfielddistribution"
+	ReadFieldDistributionDefault      = "uniform"
+	WriteAllFields                    = "writeallfields"
+	WriteAllFieldsDefault             = false
+	DataIntegrity                     = "dataintegrity"
+	DataIntegrityDefault              = false
+	ReadProportion                    = "readproportion"
+	ReadProportionDefault             = float64(0.95)
+	UpdateProportion                  = "updateproportion"
+	UpdateProportionDefault           = float64(0.05)
+	InsertProportion                  = "insertproportion"
+	InsertProportionDefault           = float64(0.0)
+	ScanProportion                    = "scanproportion"
+	ScanProportionDefault             = float64(0.0)
+	ReadModifyWriteProportion         = "readmodifywriteproportion"
+	ReadModifyWriteProportionDefault  = float64(0.0)
+	CASProportion                     = "casproportion"
+	CASProportionDefault              = float64(0.0)
+	DeleteProportion                  = "deleteproportion"
+	DeleteProportionDefault           = float64(0.0)
+	BatchReadProportion               = "batchreadproportion"
+	BatchReadProportionDefault        = float64(0.0)
+	BatchUpdateProportion             = "batchupdateproportion"
+	BatchUpdateProportionDefault      = float64(0.0)
+	BatchInsertProportion             = "batchinsertproportion"
+	BatchInsertProportionDefault      = float64(0.0)
+	BatchDeleteProportion             = "batchdeleteproportion"
+	BatchDeleteProportionDefault      = float64(0.0)
+	// BatchOperationSize is the number of records of the batch operations chosen by
+	// their proportions, unless the batch.size of the batch mode is larger than 1.
+	BatchOperationSize        = "batchoperationsize"
+	BatchOperationSizeDefault = int(10)
+	// DeletedKeys is whether the operations "avoid" the keys the transactions deleted,
+	// or "read" them like the others, to measure the latency of the not found reads.
+	DeletedKeys        = "deletedkeys"
+	DeletedKeysDefault = "avoid"
+	// DeletedReadProportion is the proportion of the reads which read one of the
+	// recently deleted keys with deletedkeys "read", measured as READ_DELETED.
+	DeletedReadProportion        = "deletedreadproportion"
+	DeletedReadProportionDefault = float64(0)
+	// FieldCompressibility is the ratio the random field values compress by, 0 for
+	// the default values of random letters.
+	FieldCompressibility        = "fieldcompressibility"
+	FieldCompressibilityDefault = float64(0)
+	// DataIntegrityChecksum builds the values dataintegrity verifies from the table, the
+	// key, the field and a write counter, with a checksum, so that reads are verified on
+	// their own, whatever the field lengths and the order of the writes.
+	DataIntegrityChecksum        = "dataintegrity.checksum"
+	DataIntegrityChecksumDefault = false
+	// MultiGetProportion is the proportion of the operations which read multiget.size
+	// random records at once.
+	MultiGetProportion        = "multigetproportion"
+	MultiGetProportionDefault = float64(0.0)
+	MultiGetSize              = "multiget.size"
+	MultiGetSizeDefault       = int(10)
+	// RecordTTL is the time after which the inserted and updated records expire, for
+	// DBs which support it. 0 means they never expire.
+	RecordTTL        = "recordttl"
+	RecordTTLDefault = "0s"
+	// TableCount spreads the keys over this many tables named <table><i>, measured
+	// by table unless measurement.breakdown is set.
+	TableCount        = "tablecount"
+	TableCountDefault = int(1)
+	// TenantCount splits the keys between this many tenants, whose keys are prefixed
+	// by tenantprefix and the tenant. Every transaction is on the keys of a tenant
+	// chosen by tenantdistribution, "uniform" or "zipfian".
+	TenantCount               = "tenantcount"
+	TenantCountDefault        = int64(1)
+	TenantPrefix              = "tenantprefix"
+	TenantPrefixDefault       = "tenant"
+	TenantDistribution        = "tenantdistribution"
+	TenantDistributionDefault = "uniform"
+	// IndexField is a field whose values are one of index.cardinality values, which
+	// the DBs implementing ycsb.QueryDB index, and the query operations filter on,
+	// returning up to index.querylength records.
+	IndexField              = "index.field"
+	IndexCardinality        = "index.cardinality"
+	IndexCardinalityDefault = int64(1000)
+	IndexQueryLength        = "index.querylength"
+	IndexQueryLengthDefault = int(100)
+	QueryProportion         = "queryproportion"
+	QueryProportionDefault  = float64(0.0)
+	// TransactionSize is the number of operations the DBs implementing
+	// ycsb.TransactionDB run in every transaction, 1 to run them on their own.
+	TransactionSize        = "transactionsize"
+	TransactionSizeDefault = int64(1)
 	// "uniform", "zipfian", "latest"
 	RequestDistribution        = "requestdistribution"
 	RequestDistributionDefault = "uniform"
@@ -73,12 +223,16 @@ const (
 	ZeroPaddingDefault         = int64(1)
 	MaxScanLength              = "maxscanlength"
 	MaxScanLengthDefault       = int64(1000)
-	// "uniform", "zipfian"
+	// "uniform", "zipfian", "constant", "histogram"
 	ScanLengthDistribution        = "scanlengthdistribution"
 	ScanLengthDistributionDefault = "uniform"
+	// Used if scanlengthdistribution is "histogram"
+	ScanLengthHistogramFile = "scanlengthhistogramfile"
 	// "ordered", "hashed"
 	InsertOrder                   = "insertorder"
 	InsertOrderDefault            = "hashed"
+	KeyHash                       = "keyhash" // "fnv64", "fnv64-java", "xxhash", "sip"
+	KeyHashDefault                = "fnv64"
 	HotspotDataFraction           = "hotspotdatafraction"
 	HotspotDataFractionDefault    = float64(0.2)
 	HotspotOpnFraction            = "hotspotopnfraction"
@@ -93,8 +247,36 @@ const (
 	ExponentialFrac              = "exponential.frac"
 	ExponentialFracDefault       = float64(0.8571428571)
 
+	// ParetoShape and ParetoScale, a fraction of the keyspace, shape the pareto request
+	// distribution. Smaller shapes have heavier tails, and larger scales flatter heads.
+	// The defaults send 80% of the requests to 20% of the keys.
+	ParetoShape        = "pareto.shape"
+	ParetoShapeDefault = float64(1.16)
+	ParetoScale        = "pareto.scale"
+	ParetoScaleDefault = float64(0.08)
+
+	// HotsetSize is the fraction of the keys in the hot set of the shiftinghotset request
+	// distribution, which moves on to the next keys every HotsetShiftInterval.
+	HotsetSize                 = "hotset.size"
+	HotsetSizeDefault          = float64(0.2)
+	HotsetShiftInterval        = "hotset.shiftinterval"
+	HotsetShiftIntervalDefault = "30s"
+
+	// LatestWindow is the number of most recently inserted records the latest request
+	// distribution chooses from, 0 for all of them, and LatestSkew the zipfian constant
+	// in (0, 1) skewing it to the most recent ones.
+	LatestWindow        = "latest.window"
+	LatestWindowDefault = int64(0)
+	LatestSkew          = "latest.skew"
+	LatestSkewDefault   = float64(0.99)
+
+	// DebugPprof is the address to serve net/http/pprof on, empty to disable it.
 	DebugPprof        = "debug.pprof"
 	DebugPprofDefault = ":6060"
+	// SelfProfile prints the CPU usage, RSS, goroutine count and GC pauses of the
+	// go-ycsb process itself every measurement interval, and over the whole run.
+	SelfProfile        = "measurement.selfprofile"
+	SelfProfileDefault = false
 
 	Verbose         = "verbose"
 	VerboseDefault  = false
@@ -104,8 +286,234 @@ const (
 	Silence        = "silence"
 	SilenceDefault = true
 
+	// OutputMode is "normal", "quiet" to only print the summary at the end of the run,
+	// "progress" to keep a single line with the progress of the run up to date, or
+	// "dashboard" to redraw a live dashboard of the run in the terminal.
+	OutputMode        = "outputmode"
+	OutputModeDefault = "normal"
+
 	KeyPrefix        = "keyprefix"
 	KeyPrefixDefault = "user"
+	// KeyFormat is "ordered" for the record number after the keyprefix, zero padded to
+	// zeropadding digits, "hashed" for its hash, "hex" for its hash in hexadecimal, or
+	// "uuid" for a UUID derived from it. It's the insertorder by default.
+	KeyFormat = "keyformat"
+
+	// Compatibility set to "java" generates the key names, field names and values
+	// like Java YCSB, so datasets can be shared with it.
+	Compatibility = "compatibility"
+
+	// RandomSeed seeds the per-thread random sources of the workload, 0 means seeding from the clock.
+	RandomSeed        = "randomseed"
+	RandomSeedDefault = int64(0)
 
 	LogInterval = "measurement.interval"
+	// Percentiles lists the latency percentiles of the reports, e.g. "50,90,99,99.9,99.99".
+	Percentiles = "measurement.percentiles"
+	// Breakdown additionally measures every operation by "table", "thread" or "table,thread".
+	Breakdown = "measurement.breakdown"
+	// LatencyUnit is the unit of the latencies of the summaries and the CSV time series,
+	// "us" or "ms". DurationFormat prints the elapsed times in seconds ("seconds") or
+	// as Go durations ("go"), and TimeSeriesTimeFormat selects the CSV timestamps,
+	// "rfc3339" or "epoch-ms".
+	LatencyUnit                 = "measurement.latencyunit"
+	LatencyUnitDefault          = "us"
+	DurationFormat              = "measurement.durationformat"
+	DurationFormatDefault       = "seconds"
+	TimeSeriesTimeFormat        = "measurement.timeseries.timeformat"
+	TimeSeriesTimeFormatDefault = "rfc3339"
+	// Clock is the clock the operations are timed with, "monotonic" (time.Now) or "coarse",
+	// a clock advanced every ClockResolution, cheaper to read at millions of operations
+	// per second but only as precise as its resolution.
+	Clock                  = "measurement.clock"
+	ClockDefault           = "monotonic"
+	ClockResolution        = "measurement.clock.resolution"
+	ClockResolutionDefault = "1ms"
+	// SamplesFile is the file every measured operation is written to, as "csv" or "binary"
+	// as selected by SamplesFormat.
+	SamplesFile          = "measurement.samples.file"
+	SamplesFormat        = "measurement.samples.format"
+	SamplesFormatDefault = "csv"
+	// HistogramsFile is the file the raw histograms of every operation are written to
+	// at the end of the run, so that the runs of several instances can be merged.
+	HistogramsFile = "measurement.histograms.file"
+	// TimeSeriesFile is the CSV file the measurements of every interval are written to.
+	TimeSeriesFile = "measurement.timeseries.file"
+	// StabilityThreshold is the percentage of the average throughput below which a
+	// measurement interval counts as a stall in the throughput stability summary.
+	StabilityThreshold        = "measurement.stability.threshold"
+	StabilityThresholdDefault = float64(50)
+	// WriteStallFactor marks the intervals in which the median write latency is this many
+	// times its baseline while the median read latency stays within WriteStallReadFactor
+	// times its own as write stalls. 0 disables the detection.
+	WriteStallFactor            = "measurement.writestall.factor"
+	WriteStallFactorDefault     = float64(4)
+	WriteStallReadFactor        = "measurement.writestall.readfactor"
+	WriteStallReadFactorDefault = float64(1.5)
+	// SLAPrefix starts the properties of the thresholds checked at the end of the run,
+	// sla.<op>.<pNN|avg|max>=<duration>, sla.throughput.min=<ops> and sla.errors.max=<count>.
+	// The run exits with a non-zero status if any of them is violated.
+	SLAPrefix = "sla."
+
+	// ErrorBudget stops the run early if more than this fraction of the operations of the
+	// last ErrorBudgetWindow failed, once at least ErrorBudgetMinOps operations ran in it.
+	ErrorBudget              = "errorbudget"
+	ErrorBudgetWindow        = "errorbudget.window"
+	ErrorBudgetWindowDefault = "10s"
+	ErrorBudgetMinOps        = "errorbudget.minops"
+	ErrorBudgetMinOpsDefault = int64(100)
+
+	// InfluxDBURL is the write endpoint of an InfluxDB server, and InfluxDBFile a file, which
+	// the measurements of every interval and the run summary are written to in the InfluxDB
+	// line protocol. The points are tagged with InfluxDBRunID, the workload and the DB.
+	InfluxDBURL   = "influxdb.url"
+	InfluxDBToken = "influxdb.token"
+	InfluxDBFile  = "influxdb.file"
+	InfluxDBRunID = "influxdb.runid"
+	// PrometheusPort is the port to expose the live measurements as Prometheus metrics on, 0 disables it.
+	PrometheusPort        = "measurement.prometheus.port"
+	PrometheusPortDefault = 0
+	// StatusPort is the port to expose the live state of the run as JSON at /status on, 0 disables it.
+	StatusPort        = "status.port"
+	StatusPortDefault = 0
+
+	// LimiterAlgorithm enables the adaptive concurrency limiter with the "gradient" or "vegas"
+	// algorithm. The limit is bounded by LimiterMinLimit and LimiterMaxLimit, which defaults
+	// to the thread count.
+	LimiterAlgorithm           = "limiter.algorithm"
+	LimiterInitialLimit        = "limiter.initiallimit"
+	LimiterInitialLimitDefault = 20
+	LimiterMinLimit            = "limiter.minlimit"
+	LimiterMinLimitDefault     = 1
+	LimiterMaxLimit            = "limiter.maxlimit"
+
+	// OperationTimeout is the client-side deadline of every DB call, 0 for none.
+	OperationTimeout = "operation.timeout"
+
+	// AdaptiveTimeoutPercentile is the percentile of the latencies of the recent successful
+	// calls of an operation its adaptive deadline is derived from, 0 for none. The deadline
+	// is the percentile times AdaptiveTimeoutMultiplier, at least AdaptiveTimeoutMin, and
+	// at most OperationTimeout if set.
+	AdaptiveTimeoutPercentile        = "operation.adaptivetimeout.percentile"
+	AdaptiveTimeoutMultiplier        = "operation.adaptivetimeout.multiplier"
+	AdaptiveTimeoutMultiplierDefault = float64(2)
+	AdaptiveTimeoutMin               = "operation.adaptivetimeout.min"
+	AdaptiveTimeoutMinDefault        = "1ms"
+
+	// OperationRetries is how many times the client retries a failed operation whose
+	// error is of a class in OperationRetryOn, whatever the DB, 0 for none. The retries
+	// wait for OperationBackoff, doubled on every retry up to OperationBackoffMax.
+	OperationRetries           = "operation.retries"
+	OperationBackoff           = "operation.backoff"
+	OperationBackoffDefault    = "10ms"
+	OperationBackoffMax        = "operation.backoffmax"
+	OperationBackoffMaxDefault = "1s"
+	OperationRetryOn           = "operation.retryon"
+	OperationRetryOnDefault    = "timeout,conflict"
+
+	// HedgeDelay enables hedged reads: a read which hasn't completed after the delay is sent
+	// again, and the first response is used. HedgePercentile instead derives the delay from
+	// the given percentile of the recent read latencies, e.g. 95.
+	HedgeDelay      = "hedge.delay"
+	HedgePercentile = "hedge.percentile"
+
+	// ShadowDB is the DB every operation is mirrored to after it is executed on the
+	// measured one, with the results of the reads compared if ShadowCompare is set.
+	ShadowDB      = "shadow.db"
+	ShadowCompare = "shadow.compare"
+
+	// HistoryFile is the file the reads and writes of the run are recorded to, with the
+	// times they were called and returned at, as JSON lines a linearizability checker
+	// such as Porcupine can consume. If HistoryCheck is set, the history is checked
+	// against a key-value model at the end of the run, giving up on a record whose
+	// check takes longer than HistoryCheckTimeout. HistoryEDN is the file the history
+	// is also exported to at the end of the run, as a Jepsen history Elle can check.
+	HistoryFile                = "history.file"
+	HistoryCheck               = "history.check"
+	HistoryCheckTimeout        = "history.checktimeout"
+	HistoryCheckTimeoutDefault = "1m"
+	HistoryEDN                 = "history.edn"
+
+	// ChaosCorruptRate is the fraction of the rows read which are corrupted before the
+	// workload sees them, by flipping a bit of a value ("bitflip"), dropping a field
+	// ("truncate") or either ("all"), as selected by ChaosCorruptMode.
+	ChaosCorruptRate        = "chaos.corruptrate"
+	ChaosCorruptMode        = "chaos.corruptmode"
+	ChaosCorruptModeDefault = "all"
+
+	// TracingEndpoint is the OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces,
+	// which a span per sampled operation is exported to. TracingSampleRate is the
+	// fraction of the operations which are traced.
+	TracingEndpoint           = "tracing.endpoint"
+	TracingSampleRate         = "tracing.samplerate"
+	TracingSampleRateDefault  = 0.01
+	TracingServiceName        = "tracing.servicename"
+	TracingServiceNameDefault = "go-ycsb"
+
+	// OpenLoop generates operations at the target throughput independently of the workers,
+	// queueing them in a bounded backlog with one queue per priority class.
+	OpenLoop        = "openloop"
+	OpenLoopDefault = false
+	// OpenLoopClasses lists the priority classes with their share of the operations,
+	// from the highest to the lowest priority, e.g. "interactive:0.2,batch:0.8".
+	OpenLoopClasses        = "openloop.classes"
+	OpenLoopClassesDefault = "default:1"
+	OpenLoopBacklog        = "openloop.backlog"
+	OpenLoopBacklogDefault = 1000
+	// "drop-newest" or "drop-oldest", applied to the lowest priority class when the backlog is full.
+	OpenLoopShedPolicy        = "openloop.shedpolicy"
+	OpenLoopShedPolicyDefault = "drop-newest"
+	// OpenLoopMemoryLimit caps the heap of the client in bytes. Once it is reached, the
+	// generation of operations blocks until the workers drain the backlog.
+	OpenLoopMemoryLimit = "openloop.memorylimit"
+
+	// LBPolicy selects how multi-endpoint drivers spread requests over their endpoints:
+	// "round-robin", "least-outstanding", "latency-weighted" or "sticky". The default
+	// depends on the driver.
+	LBPolicy = "lbpolicy"
+
+	// CostPrefix is the prefix of the per-operation unit prices, in USD per
+	// million operations, e.g. "cost.read=0.25".
+	CostPrefix = "cost."
+
+	// The timeseries workload appends points to timeseries.seriescount series, one
+	// every timeseries.interval of a logical clock per series, and reads the points
+	// of time ranges of up to timeseries.maxrangelength points.
+	SeriesCount                    = "timeseries.seriescount"
+	SeriesCountDefault             = int64(100)
+	SeriesDistribution             = "timeseries.seriesdistribution"
+	SeriesDistributionDefault      = "uniform"
+	SeriesInterval                 = "timeseries.interval"
+	SeriesIntervalDefault          = "10s"
+	RangeScanProportion            = "timeseries.scanproportion"
+	RangeScanProportionDefault     = float64(0.5)
+	MaxRangeLength                 = "timeseries.maxrangelength"
+	MaxRangeLengthDefault          = int64(100)
+	RangeLengthDistribution        = "timeseries.rangelengthdistribution"
+	RangeLengthDistributionDefault = "uniform"
+	// RangeStart is "latest" to read the most recent points of the series, or "uniform"
+	// to read ranges starting anywhere in them.
+	RangeStart        = "timeseries.rangestart"
+	RangeStartDefault = "latest"
+
+	// The queue workload has queue.count queues, which the first threads produce to
+	// and the last queue.consumerthreads threads consume from, through the
+	// ycsb.QueueDB of the DB with queue.native, or else records and scans.
+	QueueCount           = "queue.count"
+	QueueCountDefault    = int64(10)
+	QueueConsumerThreads = "queue.consumerthreads"
+	QueueNative          = "queue.native"
+	QueueNativeDefault   = false
+
+	// The trace workload replays the operations of trace.file, as fast as possible,
+	// or trace.speedup times as fast as they were traced.
+	TraceFile           = "trace.file"
+	TraceSpeedup        = "trace.speedup"
+	TraceSpeedupDefault = float64(0)
+
+	// TraceRecord is the file every operation of the run is recorded to, as a trace
+	// in the trace.recordformat "csv" or "binary" format the trace workload replays.
+	TraceRecord              = "trace.record"
+	TraceRecordFormat        = "trace.recordformat"
+	TraceRecordFormatDefault = "csv"
 )
@@ -0,0 +1,207 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Load balancing policies, selected by the lbpolicy property.
+const (
+	// LBRoundRobin sends each request to the next endpoint in turn.
+	LBRoundRobin = "round-robin"
+	// LBLeastOutstanding sends each request to the endpoint with the fewest requests in flight.
+	LBLeastOutstanding = "least-outstanding"
+	// LBLatencyWeighted picks endpoints at random, weighted by the inverse of their average latency.
+	LBLatencyWeighted = "latency-weighted"
+	// LBSticky keeps each thread on one endpoint, and only moves it to the next one on errors.
+	LBSticky = "sticky"
+)
+
+// latencyDecay is the weight of a new sample in the moving average latency of an endpoint.
+const latencyDecay = 0.2
+
+// Balancer spreads the requests of a multi-endpoint driver over its endpoints.
+// Drivers call Pick before sending a request, and Done once it completes.
+// Balancer is safe for concurrent use.
+type Balancer struct {
+	policy    string
+	endpoints []string
+
+	next        uint64
+	outstanding []int64
+	requests    []int64
+	errors      []int64
+
+	latencyLock sync.Mutex
+	latency     []float64
+
+	// sticky maps a thread ID to its current endpoint.
+	sticky sync.Map
+}
+
+// NewBalancer creates a Balancer with the given policy over endpoints.
+func NewBalancer(policy string, endpoints []string) (*Balancer, error) {
+	policy = strings.ToLower(policy)
+	switch policy {
+	case LBRoundRobin, LBLeastOutstanding, LBLatencyWeighted, LBSticky:
+	default:
+		return nil, fmt.Errorf("unknown load balancing policy %q; expecting %s, %s, %s or %s",
+			policy, LBRoundRobin, LBLeastOutstanding, LBLatencyWeighted, LBSticky)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("load balancing needs at least one endpoint")
+	}
+
+	return &Balancer{
+		policy:      policy,
+		endpoints:   endpoints,
+		outstanding: make([]int64, len(endpoints)),
+		requests:    make([]int64, len(endpoints)),
+		errors:      make([]int64, len(endpoints)),
+		latency:     make([]float64, len(endpoints)),
+	}, nil
+}
+
+// Policy returns the name of the policy in use.
+func (b *Balancer) Policy() string {
+	return b.policy
+}
+
+// Endpoint returns the address of the endpoint at index i.
+func (b *Balancer) Endpoint(i int) string {
+	return b.endpoints[i]
+}
+
+type endpointKey struct{}
+
+// WithEndpoint returns a context whose requests go to the endpoint at index i,
+// regardless of the load balancing policy.
+func WithEndpoint(ctx context.Context, i int) context.Context {
+	return context.WithValue(ctx, endpointKey{}, i)
+}
+
+// PinnedEndpoint returns the endpoint set by WithEndpoint on the context.
+func PinnedEndpoint(ctx context.Context) (int, bool) {
+	i, ok := ctx.Value(endpointKey{}).(int)
+	return i, ok
+}
+
+// Pick returns the index of the endpoint the next request of the thread should go to,
+// or the endpoint the context is pinned to by WithEndpoint.
+func (b *Balancer) Pick(ctx context.Context, threadID int) int {
+	i, pinned := PinnedEndpoint(ctx)
+	switch {
+	case pinned:
+	case b.policy == LBRoundRobin:
+		i = int((atomic.AddUint64(&b.next, 1) - 1) % uint64(len(b.endpoints)))
+	case b.policy == LBLeastOutstanding:
+		i = b.pickLeastOutstanding()
+	case b.policy == LBLatencyWeighted:
+		i = b.pickLatencyWeighted()
+	case b.policy == LBSticky:
+		current, _ := b.sticky.LoadOrStore(threadID, threadID%len(b.endpoints))
+		i = current.(int)
+	}
+
+	atomic.AddInt64(&b.outstanding[i], 1)
+	atomic.AddInt64(&b.requests[i], 1)
+	return i
+}
+
+func (b *Balancer) pickLeastOutstanding() int {
+	// start the scan at a rotating offset, so that ties don't all go to the first endpoint
+	start := int(atomic.AddUint64(&b.next, 1) % uint64(len(b.endpoints)))
+	best := start
+	for n := 1; n < len(b.endpoints); n++ {
+		i := (start + n) % len(b.endpoints)
+		if atomic.LoadInt64(&b.outstanding[i]) < atomic.LoadInt64(&b.outstanding[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func (b *Balancer) pickLatencyWeighted() int {
+	b.latencyLock.Lock()
+	defer b.latencyLock.Unlock()
+
+	// endpoints without samples yet are always tried first
+	for i, latency := range b.latency {
+		if latency == 0 {
+			return i
+		}
+	}
+
+	total := float64(0)
+	for _, latency := range b.latency {
+		total += 1 / latency
+	}
+	x := rand.Float64() * total
+	for i, latency := range b.latency {
+		x -= 1 / latency
+		if x < 0 {
+			return i
+		}
+	}
+	return len(b.latency) - 1
+}
+
+// Done reports the completion of a request the thread sent to the endpoint returned by Pick.
+func (b *Balancer) Done(threadID int, endpoint int, latency time.Duration, err error) {
+	atomic.AddInt64(&b.outstanding[endpoint], -1)
+	if err != nil {
+		atomic.AddInt64(&b.errors[endpoint], 1)
+	}
+
+	switch b.policy {
+	case LBLatencyWeighted:
+		sample := float64(latency)
+		if sample <= 0 {
+			sample = 1
+		}
+		b.latencyLock.Lock()
+		if err != nil {
+			// penalize failing endpoints, so they get picked less often
+			sample = 2 * (b.latency[endpoint] + sample)
+		}
+		if b.latency[endpoint] == 0 {
+			b.latency[endpoint] = sample
+		} else {
+			b.latency[endpoint] += latencyDecay * (sample - b.latency[endpoint])
+		}
+		b.latencyLock.Unlock()
+	case LBSticky:
+		if err != nil {
+			b.sticky.Store(threadID, (endpoint+1)%len(b.endpoints))
+		}
+	}
+}
+
+// Stats returns the number of requests and errors per endpoint, keyed by policy and endpoint.
+func (b *Balancer) Stats() map[string]int64 {
+	stats := make(map[string]int64, 2*len(b.endpoints))
+	for i, endpoint := range b.endpoints {
+		prefix := fmt.Sprintf("lb.%s.%s.", b.policy, endpoint)
+		stats[prefix+"requests"] = atomic.LoadInt64(&b.requests[i])
+		stats[prefix+"errors"] = atomic.LoadInt64(&b.errors[i])
+	}
+	return stats
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBalancer(t *testing.T) {
+	endpoints := []string{"a:1", "b:1", "c:1"}
+	ctx := context.Background()
+
+	b, err := NewBalancer(LBRoundRobin, endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if got := b.Pick(ctx, 0); got != i%3 {
+			t.Fatalf("round-robin pick %d: got %d", i, got)
+		}
+		b.Done(0, i%3, time.Millisecond, nil)
+	}
+	if got := b.Stats()["lb.round-robin.b:1.requests"]; got != 2 {
+		t.Fatalf("round-robin requests to b:1: got %d", got)
+	}
+
+	b, _ = NewBalancer(LBLeastOutstanding, endpoints)
+	first, second := b.Pick(ctx, 0), b.Pick(ctx, 0)
+	if first == second {
+		t.Fatalf("least-outstanding picked %d twice", first)
+	}
+	b.Done(0, first, time.Millisecond, nil)
+	if got := b.Pick(ctx, 0); got == second {
+		t.Fatalf("least-outstanding picked busy endpoint %d", got)
+	}
+
+	b, _ = NewBalancer(LBSticky, endpoints)
+	if got := b.Pick(ctx, 1); got != 1 {
+		t.Fatalf("sticky thread 1: got %d", got)
+	}
+	b.Done(1, 1, time.Millisecond, nil)
+	if got := b.Pick(ctx, 1); got != 1 {
+		t.Fatalf("sticky thread 1 moved without error: got %d", got)
+	}
+	b.Done(1, 1, time.Millisecond, errors.New("not leader"))
+	if got := b.Pick(ctx, 1); got != 2 {
+		t.Fatalf("sticky thread 1 after error: got %d", got)
+	}
+
+	b, _ = NewBalancer(LBLatencyWeighted, endpoints)
+	for i := range endpoints {
+		b.Done(0, b.Pick(ctx, 0), time.Duration(i+1)*time.Millisecond, nil)
+	}
+	counts := make([]int, len(endpoints))
+	for i := 0; i < 3000; i++ {
+		counts[b.Pick(ctx, 0)]++
+	}
+	if counts[0] <= counts[2] {
+		t.Fatalf("latency-weighted favored the slow endpoint: %v", counts)
+	}
+
+	if got := b.Pick(WithEndpoint(ctx, 2), 0); got != 2 {
+		t.Fatalf("pinned to endpoint 2: got %d", got)
+	}
+
+	if _, err = NewBalancer("random", endpoints); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// ConnStats counts the connection events of the DBs which manage their own connections.
+type ConnStats struct {
+	// Established counts the connections established, including reconnects.
+	Established int64
+	// Reconnects counts the connections established to replace a broken one.
+	Reconnects int64
+	// DialFailures counts the connection attempts which failed.
+	DialFailures int64
+	// KeepAliveFailures counts the established connections found broken, either
+	// when reused or by a keep-alive probe.
+	KeepAliveFailures int64
+}
+
+var connStats ConnStats
+
+// RecordConnEstablished records a new connection, which replaces a broken one if reconnect is set.
+func RecordConnEstablished(reconnect bool) {
+	atomic.AddInt64(&connStats.Established, 1)
+	if reconnect {
+		atomic.AddInt64(&connStats.Reconnects, 1)
+	}
+}
+
+// RecordDialFailure records a failed connection attempt.
+func RecordDialFailure() {
+	atomic.AddInt64(&connStats.DialFailures, 1)
+}
+
+// RecordKeepAliveFailure records an established connection found broken.
+func RecordKeepAliveFailure() {
+	atomic.AddInt64(&connStats.KeepAliveFailures, 1)
+}
+
+// DialFunc dials a connection to the address, as net.Dialer.DialContext does.
+type DialFunc func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+// CountingDial returns dial recording the connections it establishes and fails to,
+// for the DBs whose driver dials through a custom dialer. A connection to an address
+// dialed before replaces a broken one, and is counted as a reconnect.
+func CountingDial(dial DialFunc) DialFunc {
+	var (
+		mu     sync.Mutex
+		dialed = make(map[string]bool)
+	)
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			RecordDialFailure()
+			return nil, err
+		}
+		mu.Lock()
+		reconnect := dialed[addr]
+		dialed[addr] = true
+		mu.Unlock()
+		RecordConnEstablished(reconnect)
+		return conn, nil
+	}
+}
+
+// LoadConnStats returns the connection events recorded so far.
+func LoadConnStats() ConnStats {
+	return ConnStats{
+		Established:       atomic.LoadInt64(&connStats.Established),
+		Reconnects:        atomic.LoadInt64(&connStats.Reconnects),
+		DialFailures:      atomic.LoadInt64(&connStats.DialFailures),
+		KeepAliveFailures: atomic.LoadInt64(&connStats.KeepAliveFailures),
+	}
+}
@@ -44,6 +44,29 @@ func allFields(p *properties.Properties) []string {
 	return fields
 }
 
+// TableNames returns the tables of the core workload, which spreads the keys over
+// tablecount tables named <table><i> if it's larger than 1.
+func TableNames(p *properties.Properties) []string {
+	table := p.GetString(prop.TableName, prop.TableNameDefault)
+	tableCount := p.GetInt(prop.TableCount, prop.TableCountDefault)
+	if tableCount <= 1 {
+		return []string{table}
+	}
+	tables := make([]string, tableCount)
+	for i := range tables {
+		tables[i] = fmt.Sprintf("%s%d", table, i)
+	}
+	return tables
+}
+
+// KeyTable returns which of the tables of the core workload the key is in.
+func KeyTable(tables []string, key string) string {
+	if len(tables) == 1 {
+		return tables[0]
+	}
+	return tables[uint64(StringHash64(key))%uint64(len(tables))]
+}
+
 // RowCodec is a helper struct to encode and decode TiDB format row
 type RowCodec struct {
 	fieldIndices map[string]int64
@@ -15,20 +15,135 @@ package util
 
 import (
 	"encoding/binary"
+	"fmt"
 	"hash/fnv"
+	"math/bits"
 )
 
-// Hash64 returns a fnv Hash of the integer.
+// keyHash is the hash used by Hash64, selected with SetKeyHash.
+var keyHash = fnv64Hash
+
+// keyHashes are the hashes which can be selected with SetKeyHash.
+var keyHashes = map[string]func(n int64) uint64{
+	"fnv64":      fnv64Hash,
+	"fnv64-java": fnv64JavaHash,
+	"xxhash":     xxHash,
+	"sip":        sipHash,
+}
+
+// SetKeyHash selects the hash used by Hash64: "fnv64", "fnv64-java", "xxhash" or "sip".
+func SetKeyHash(name string) error {
+	h, ok := keyHashes[name]
+	if !ok {
+		return fmt.Errorf("unknown key hash %q; expecting fnv64, fnv64-java, xxhash or sip", name)
+	}
+	keyHash = h
+	return nil
+}
+
+// Hash64 returns a non-negative hash of the integer, fnv unless another hash is
+// selected with SetKeyHash.
 func Hash64(n int64) int64 {
+	result := int64(keyHash(n))
+	if result < 0 {
+		return -result
+	}
+	return result
+}
+
+// fnv64Hash is the fnv-1a hash of the big endian bytes of the integer.
+func fnv64Hash(n int64) uint64 {
 	var b [8]byte
 	binary.BigEndian.PutUint64(b[0:8], uint64(n))
 	hash := fnv.New64a()
 	hash.Write(b[0:8])
-	result := int64(hash.Sum64())
-	if result < 0 {
-		return -result
+	return hash.Sum64()
+}
+
+// fnv64JavaHash is the fnv-1a hash of the little endian bytes of the integer, like
+// Java YCSB's Utils.fnvhash64, so the keys are the ones of its hashed inserts.
+func fnv64JavaHash(n int64) uint64 {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[0:8], uint64(n))
+	hash := fnv.New64a()
+	hash.Write(b[0:8])
+	return hash.Sum64()
+}
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// xxHash is the XXH64 hash, with a zero seed, of the big endian bytes of the integer.
+func xxHash(n int64) uint64 {
+	// XXH64 reads its input in little endian
+	lane := bits.ReverseBytes64(uint64(n))
+
+	h := xxPrime5 + 8
+	k := lane * xxPrime2
+	k = bits.RotateLeft64(k, 31) * xxPrime1
+	h ^= k
+	h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+// sipHash is the SipHash-2-4, with a zero key, of the big endian bytes of the integer.
+func sipHash(n int64) uint64 {
+	return sipHash24(0, 0, bits.ReverseBytes64(uint64(n)))
+}
+
+// sipHash24 is the SipHash-2-4 of a single 8 byte message m, read in little endian.
+func sipHash24(k0, k1, m uint64) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
 	}
-	return result
+
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	// the last block only holds the message length
+	b := uint64(8) << 56
+	v3 ^= b
+	round()
+	round()
+	v0 ^= b
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
 }
 
 // BytesHash64 returns the fnv hash of a bytes
@@ -0,0 +1,44 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestSipHash24(t *testing.T) {
+	// the 8 byte vector of the reference implementation, key 00..0f and message 00..07
+	if h := sipHash24(0x0706050403020100, 0x0f0e0d0c0b0a0908, 0x0706050403020100); h != 0x93f5f5799a932462 {
+		t.Fatalf("got %x", h)
+	}
+}
+
+func TestKeyHash(t *testing.T) {
+	defer SetKeyHash("fnv64")
+
+	if err := SetKeyHash("md5"); err == nil {
+		t.Fatal("expected an error for an unknown hash")
+	}
+	for _, name := range []string{"fnv64", "fnv64-java", "xxhash", "sip"} {
+		if err := SetKeyHash(name); err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[int64]bool)
+		for n := int64(0); n < 1000; n++ {
+			h := Hash64(n)
+			if h < 0 || seen[h] {
+				t.Fatalf("%s: bad hash %d of %d", name, h, n)
+			}
+			seen[h] = true
+		}
+	}
+}
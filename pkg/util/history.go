@@ -0,0 +1,348 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryUnknown is the return time of the writes which failed without a known outcome,
+// which may take effect at any time after their call.
+const HistoryUnknown = math.MaxInt64
+
+// HistoryOperation is a completed operation of a history, in the shape of a Porcupine
+// operation: the client which executed it, its input and output, and the times it was
+// called and returned at, in nanoseconds since the start of the run.
+type HistoryOperation struct {
+	ClientID int           `json:"client_id"`
+	Input    HistoryInput  `json:"input"`
+	Call     int64         `json:"call"`
+	Output   HistoryOutput `json:"output"`
+	Return   int64         `json:"return"`
+}
+
+// HistoryInput is a "read", "insert", "update" or "delete" of a record. The values are
+// the fingerprints of the fields written, and the fields those read, all if empty.
+type HistoryInput struct {
+	Op     string            `json:"op"`
+	Table  string            `json:"table"`
+	Key    string            `json:"key"`
+	Fields []string          `json:"fields,omitempty"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// HistoryOutput is whether a read found the record, and the fingerprints of the
+// fields it read, or the error of a failed operation.
+type HistoryOutput struct {
+	Found  bool              `json:"found,omitempty"`
+	Values map[string]string `json:"values,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// HistoryFingerprint returns the fingerprint a value is recorded as, which is never empty.
+func HistoryFingerprint(value []byte) string {
+	h := fnv.New64a()
+	h.Write(value)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ReadHistory reads the operations of a history written as JSON lines.
+func ReadHistory(r io.Reader) ([]HistoryOperation, error) {
+	var ops []HistoryOperation
+	d := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var op HistoryOperation
+		if err := d.Decode(&op); err == io.EOF {
+			return ops, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("decode operation %d failed %v", len(ops)+1, err)
+		}
+		ops = append(ops, op)
+	}
+}
+
+// LinearizabilityResult is the outcome of CheckLinearizability.
+type LinearizabilityResult struct {
+	Keys       int
+	Operations int
+	// Violations are the records whose operations aren't linearizable, as table/key
+	Violations []string
+	// Unknown are the records whose check ran out of time
+	Unknown []string
+}
+
+// CheckLinearizability checks that the operations of every record of the history are
+// linearizable, as those of a register of fields, like Porcupine does for a KV model.
+// The records the history doesn't write first are assumed to hold what they are first
+// read to hold. The failed reads are left out. The check gives up on the records left
+// once the timeout expires.
+func CheckLinearizability(ops []HistoryOperation, timeout time.Duration) LinearizabilityResult {
+	byKey := make(map[string][]*HistoryOperation)
+	var keys []string
+	for i := range ops {
+		op := &ops[i]
+		if op.Input.Op == "read" && op.Output.Error != "" {
+			continue
+		}
+		key := op.Input.Table + "/" + op.Input.Key
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], op)
+	}
+	sort.Strings(keys)
+
+	res := LinearizabilityResult{Keys: len(keys)}
+	deadline := time.Now().Add(timeout)
+	for _, key := range keys {
+		res.Operations += len(byKey[key])
+		ok, done := checkRegister(byKey[key], deadline)
+		if !done {
+			res.Unknown = append(res.Unknown, key)
+		} else if !ok {
+			res.Violations = append(res.Violations, key)
+		}
+	}
+	return res
+}
+
+// historyEntry is the call or the return of an operation, in the list of the entries
+// left to linearize.
+type historyEntry struct {
+	op    *HistoryOperation
+	id    int
+	call  bool
+	time  int64
+	match *historyEntry
+	prev  *historyEntry
+	next  *historyEntry
+}
+
+// checkRegister checks the operations of a record with the algorithm of Wing & Gong
+// improved by Lowe, as Porcupine does: the calls are linearized in turn while their
+// effect fits the state, backtracking at a return whose call can't be linearized, and
+// skipping the linearizations already seen. It returns false for done if the deadline
+// expires.
+func checkRegister(ops []*HistoryOperation, deadline time.Time) (ok bool, done bool) {
+	entries := make([]*historyEntry, 0, 2*len(ops))
+	for i, op := range ops {
+		call := &historyEntry{op: op, id: i, call: true, time: op.Call}
+		ret := &historyEntry{op: op, id: i, time: op.Return}
+		call.match = ret
+		entries = append(entries, call, ret)
+	}
+	// the operations which call and return at the same time overlap
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].time != entries[j].time {
+			return entries[i].time < entries[j].time
+		}
+		return entries[i].call && !entries[j].call
+	})
+	head := new(historyEntry)
+	prev := head
+	for _, e := range entries {
+		prev.next, e.prev = e, prev
+		prev = e
+	}
+
+	type frame struct {
+		entry *historyEntry
+		state registerState
+	}
+	var stack []frame
+	linearized := make(bitset, (len(ops)+63)/64)
+	seen := make(map[string]bool)
+	var state registerState
+	entry := head.next
+	for steps := 1; head.next != nil; steps++ {
+		if steps%1000 == 0 && time.Now().After(deadline) {
+			return false, false
+		}
+		if !entry.call {
+			// the operation returned before its call could be linearized
+			if len(stack) == 0 {
+				return false, true
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			state = top.state
+			linearized.clear(top.entry.id)
+			unliftEntry(top.entry)
+			entry = top.entry.next
+			continue
+		}
+
+		if next, ok := state.step(entry.op); ok {
+			linearized.set(entry.id)
+			key := linearized.String() + next.String()
+			if !seen[key] {
+				seen[key] = true
+				stack = append(stack, frame{entry: entry, state: state})
+				state = next
+				liftEntry(entry)
+				entry = head.next
+				continue
+			}
+			linearized.clear(entry.id)
+		}
+		entry = entry.next
+	}
+	return true, true
+}
+
+// liftEntry removes the call and the return of an operation from the list.
+func liftEntry(call *historyEntry) {
+	call.prev.next = call.next
+	call.next.prev = call.prev
+	ret := call.match
+	ret.prev.next = ret.next
+	if ret.next != nil {
+		ret.next.prev = ret.prev
+	}
+}
+
+// unliftEntry puts back the call and the return liftEntry removed.
+func unliftEntry(call *historyEntry) {
+	ret := call.match
+	ret.prev.next = ret
+	if ret.next != nil {
+		ret.next.prev = ret
+	}
+	call.prev.next = call
+	call.next.prev = call
+}
+
+type bitset []uint64
+
+func (b bitset) set(i int)   { b[i/64] |= 1 << uint(i%64) }
+func (b bitset) clear(i int) { b[i/64] &^= 1 << uint(i%64) }
+
+func (b bitset) String() string {
+	var s strings.Builder
+	for _, word := range b {
+		fmt.Fprintf(&s, "%x.", word)
+	}
+	return s.String()
+}
+
+// Whether a record is known to exist.
+const (
+	presenceUnknown = iota
+	presencePresent
+	presenceAbsent
+)
+
+// registerState is what is known of a record: whether it exists, and the fields known,
+// with "" for those known to be missing. Complete is set if all its fields are known.
+type registerState struct {
+	presence int
+	complete bool
+	fields   map[string]string
+}
+
+func (s registerState) field(name string) (string, bool) {
+	if value, ok := s.fields[name]; ok {
+		return value, true
+	}
+	return "", s.complete
+}
+
+func (s registerState) with(values map[string]string) registerState {
+	fields := make(map[string]string, len(s.fields)+len(values))
+	for name, value := range s.fields {
+		fields[name] = value
+	}
+	for name, value := range values {
+		fields[name] = value
+	}
+	return registerState{presence: presencePresent, complete: s.complete, fields: fields}
+}
+
+// step returns the state after the operation, and false if the operation can't
+// happen in the state.
+func (s registerState) step(op *HistoryOperation) (registerState, bool) {
+	switch op.Input.Op {
+	case "insert":
+		return registerState{presence: presencePresent, complete: true}.with(op.Input.Values), true
+	case "update":
+		if s.presence == presenceAbsent {
+			s.complete = true
+		}
+		return s.with(op.Input.Values), true
+	case "delete":
+		return registerState{presence: presenceAbsent, complete: true}, true
+	case "read":
+	default:
+		return s, false
+	}
+
+	if !op.Output.Found {
+		if s.presence == presencePresent {
+			return s, false
+		}
+		return registerState{presence: presenceAbsent, complete: true}, true
+	}
+	if s.presence == presenceAbsent {
+		return s, false
+	}
+
+	names := op.Input.Fields
+	if len(names) == 0 {
+		for name := range op.Output.Values {
+			names = append(names, name)
+		}
+		for name := range s.fields {
+			names = append(names, name)
+		}
+	}
+	learnt := make(map[string]string)
+	for _, name := range names {
+		value := op.Output.Values[name]
+		if known, ok := s.field(name); ok && known != value {
+			return s, false
+		} else if !ok {
+			learnt[name] = value
+		}
+	}
+	next := s.with(learnt)
+	if len(op.Input.Fields) == 0 {
+		next.complete = true
+	}
+	return next, true
+}
+
+// String returns the state as a canonical string.
+func (s registerState) String() string {
+	names := make([]string, 0, len(s.fields))
+	for name, value := range s.fields {
+		if value != "" || !s.complete {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d%t", s.presence, s.complete)
+	for _, name := range names {
+		fmt.Fprintf(&b, ",%s=%s", name, s.fields[name])
+	}
+	return b.String()
+}
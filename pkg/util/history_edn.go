@@ -0,0 +1,176 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ednEvent is an entry of a Jepsen history: the invocation or the completion of an operation.
+type ednEvent struct {
+	op   *HistoryOperation
+	kind string
+	time int64
+}
+
+// WriteHistoryEDN writes the operations of a history as a Jepsen history, one EDN map
+// per line, which Elle can check as an rw-register history. Every operation is a :txn
+// of micro-ops on the fields of its record, named table/key/field, reading or writing
+// their fingerprints. A read of all the fields reads every field of its table in the
+// history, and reads nil for those the record doesn't have, all of them if it wasn't
+// found. The failed reads complete as :fail, the failed writes as :info at the end of
+// the history, after which their process is replaced by a new one as Jepsen does for
+// crashed processes. Deletes can't be expressed as register writes, so they are left
+// out, and WriteHistoryEDN returns how many were.
+func WriteHistoryEDN(w io.Writer, ops []HistoryOperation) (int, error) {
+	threads := 0
+	for i := range ops {
+		if ops[i].ClientID >= threads {
+			threads = ops[i].ClientID + 1
+		}
+	}
+
+	// the fields of every table, which the reads of all the fields read
+	fieldSets := make(map[string]map[string]bool)
+	for i := range ops {
+		op := &ops[i]
+		set, ok := fieldSets[op.Input.Table]
+		if !ok {
+			set = make(map[string]bool)
+			fieldSets[op.Input.Table] = set
+		}
+		for _, field := range op.Input.Fields {
+			set[field] = true
+		}
+		for field := range op.Input.Values {
+			set[field] = true
+		}
+		for field := range op.Output.Values {
+			set[field] = true
+		}
+	}
+	tableFields := make(map[string][]string, len(fieldSets))
+	for table, set := range fieldSets {
+		for field := range set {
+			tableFields[table] = append(tableFields[table], field)
+		}
+		sort.Strings(tableFields[table])
+	}
+
+	var events, crashed []ednEvent
+	var end int64
+	deletes := 0
+	for i := range ops {
+		op := &ops[i]
+		if op.Input.Op == "delete" {
+			deletes++
+			continue
+		}
+		events = append(events, ednEvent{op: op, kind: ":invoke", time: op.Call})
+		switch {
+		case op.Output.Error == "":
+			events = append(events, ednEvent{op: op, kind: ":ok", time: op.Return})
+		case op.Input.Op == "read":
+			events = append(events, ednEvent{op: op, kind: ":fail", time: op.Return})
+		default:
+			crashed = append(crashed, ednEvent{op: op, kind: ":info"})
+			continue
+		}
+		if op.Return > end {
+			end = op.Return
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].time < events[j].time })
+	for _, e := range crashed {
+		e.time = end
+		events = append(events, e)
+	}
+
+	// every thread runs as a process thread+n*threads, moving on to the next one while
+	// its process is busy, e.g. with a pipeline, or once it crashed
+	processes := make(map[*HistoryOperation]int)
+	unavailable := make(map[int]bool)
+	bw := bufio.NewWriter(w)
+	for index, e := range events {
+		p, ok := processes[e.op]
+		if !ok {
+			p = e.op.ClientID
+			for unavailable[p] {
+				p += threads
+			}
+			processes[e.op] = p
+			unavailable[p] = true
+		} else if e.kind != ":info" {
+			delete(unavailable, p)
+		}
+		fmt.Fprintf(bw, "{:type %s, :f :txn, :value %s, :process %d, :time %d, :index %d", e.kind, ednTxn(e.op, tableFields[e.op.Input.Table], e.kind == ":ok"), p, e.time, index)
+		if e.kind != ":invoke" && e.kind != ":ok" {
+			fmt.Fprintf(bw, ", :error %s", ednString(e.op.Output.Error))
+		}
+		bw.WriteString("}\n")
+	}
+	return deletes, bw.Flush()
+}
+
+// ednTxn returns the micro-ops of an operation, with the values read if completed, a
+// read of all the fields reading those of the table.
+func ednTxn(op *HistoryOperation, tableFields []string, completed bool) string {
+	prefix := op.Input.Table + "/" + op.Input.Key + "/"
+	var b strings.Builder
+	b.WriteByte('[')
+	if op.Input.Op == "read" {
+		fields := op.Input.Fields
+		if len(fields) == 0 {
+			fields = tableFields
+		}
+		for i, field := range fields {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			value := "nil"
+			if v, ok := op.Output.Values[field]; ok && completed {
+				value = ednString(v)
+			}
+			fmt.Fprintf(&b, "[:r %s %s]", ednString(prefix+field), value)
+		}
+	} else {
+		for i, field := range sortedFields(op.Input.Values) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "[:w %s %s]", ednString(prefix+field), ednString(op.Input.Values[field]))
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func sortedFields(values map[string]string) []string {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+var ednEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func ednString(s string) string {
+	return `"` + ednEscaper.Replace(s) + `"`
+}
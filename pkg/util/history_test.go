@@ -0,0 +1,188 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func write(client int, op string, key string, value string, call int64, ret int64) HistoryOperation {
+	o := HistoryOperation{ClientID: client, Call: call, Return: ret}
+	o.Input = HistoryInput{Op: op, Table: "t", Key: key}
+	if value != "" {
+		o.Input.Values = map[string]string{"f": value}
+	}
+	return o
+}
+
+func read(client int, key string, value string, call int64, ret int64) HistoryOperation {
+	o := HistoryOperation{ClientID: client, Call: call, Return: ret}
+	o.Input = HistoryInput{Op: "read", Table: "t", Key: key}
+	if value != "" {
+		o.Output = HistoryOutput{Found: true, Values: map[string]string{"f": value}}
+	}
+	return o
+}
+
+func TestCheckLinearizability(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []HistoryOperation
+		ok   bool
+	}{
+		{"sequential", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			read(1, "a", "1", 20, 30),
+			write(0, "update", "a", "2", 40, 50),
+			read(1, "a", "2", 60, 70),
+		}, true},
+		{"stale read", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "update", "a", "2", 20, 30),
+			read(1, "a", "1", 40, 50),
+		}, false},
+		{"concurrent reads of either value", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "update", "a", "2", 20, 60),
+			read(1, "a", "2", 25, 30),
+			read(2, "a", "2", 40, 50),
+		}, true},
+		{"read of the new value then of the old one", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "update", "a", "2", 20, 60),
+			read(1, "a", "2", 25, 30),
+			read(2, "a", "1", 40, 50),
+		}, false},
+		{"unknown initial value", []HistoryOperation{
+			read(1, "a", "0", 0, 10),
+			read(2, "a", "0", 20, 30),
+			write(0, "update", "a", "1", 40, 50),
+			read(1, "a", "1", 60, 70),
+		}, true},
+		{"unknown initial value changing", []HistoryOperation{
+			read(1, "a", "0", 0, 10),
+			read(2, "a", "5", 20, 30),
+		}, false},
+		{"deleted", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "delete", "a", "", 20, 30),
+			read(1, "a", "", 40, 50),
+			read(1, "a", "1", 60, 70),
+		}, false},
+		{"failed write of unknown outcome", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "update", "a", "2", 20, HistoryUnknown),
+			read(1, "a", "1", 40, 50),
+			read(1, "a", "2", 60, 70),
+		}, true},
+		{"records checked apart", []HistoryOperation{
+			write(0, "insert", "a", "1", 0, 10),
+			write(0, "insert", "b", "2", 0, 10),
+			read(1, "a", "1", 20, 30),
+			read(1, "b", "2", 20, 30),
+		}, true},
+	}
+
+	for _, test := range tests {
+		res := CheckLinearizability(test.ops, time.Minute)
+		if ok := len(res.Violations) == 0 && len(res.Unknown) == 0; ok != test.ok {
+			t.Fatalf("%s: got %+v, want linearizable %t", test.name, res, test.ok)
+		}
+		if res.Operations != len(test.ops) {
+			t.Fatalf("%s: checked %d operations, want %d", test.name, res.Operations, len(test.ops))
+		}
+	}
+}
+
+func TestReadHistory(t *testing.T) {
+	ops := []HistoryOperation{
+		write(0, "insert", "a", HistoryFingerprint([]byte("value")), 0, 10),
+		read(1, "a", HistoryFingerprint([]byte("value")), 20, 30),
+	}
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	for _, op := range ops {
+		if err := e.Encode(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ReadHistory(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ops) || got[1].Output.Values["f"] != ops[0].Input.Values["f"] {
+		t.Fatalf("got %+v, want %+v", got, ops)
+	}
+}
+
+func TestWriteHistoryEDN(t *testing.T) {
+	failed := write(0, "update", "a", "2", 20, HistoryUnknown)
+	failed.Output.Error = "timeout"
+	ops := []HistoryOperation{
+		write(0, "insert", "a", "1", 0, 10),
+		read(1, "a", "1", 5, 15),
+		failed,
+		write(0, "delete", "a", "", 30, 40),
+		read(1, "b", "", 30, 40),
+	}
+	ops[4].Input.Fields = []string{"f"}
+
+	var buf bytes.Buffer
+	deletes, err := WriteHistoryEDN(&buf, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deletes != 1 {
+		t.Fatalf("left out %d deletes, want 1", deletes)
+	}
+	want := `{:type :invoke, :f :txn, :value [[:w "t/a/f" "1"]], :process 0, :time 0, :index 0}
+{:type :invoke, :f :txn, :value [[:r "t/a/f" nil]], :process 1, :time 5, :index 1}
+{:type :ok, :f :txn, :value [[:w "t/a/f" "1"]], :process 0, :time 10, :index 2}
+{:type :ok, :f :txn, :value [[:r "t/a/f" "1"]], :process 1, :time 15, :index 3}
+{:type :invoke, :f :txn, :value [[:w "t/a/f" "2"]], :process 0, :time 20, :index 4}
+{:type :invoke, :f :txn, :value [[:r "t/b/f" nil]], :process 1, :time 30, :index 5}
+{:type :ok, :f :txn, :value [[:r "t/b/f" nil]], :process 1, :time 40, :index 6}
+{:type :info, :f :txn, :value [[:w "t/a/f" "2"]], :process 0, :time 40, :index 7, :error "timeout"}
+`
+	if buf.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteHistoryEDNReadAllFields(t *testing.T) {
+	insert := write(0, "insert", "a", "1", 0, 10)
+	insert.Input.Values["g"] = "2"
+	ops := []HistoryOperation{
+		insert,
+		read(1, "b", "", 20, 30),
+	}
+
+	var buf bytes.Buffer
+	if _, err := WriteHistoryEDN(&buf, ops); err != nil {
+		t.Fatal(err)
+	}
+	// the read of all the fields which didn't find the record reads nil for every field
+	want := `{:type :invoke, :f :txn, :value [[:w "t/a/f" "1"] [:w "t/a/g" "2"]], :process 0, :time 0, :index 0}
+{:type :ok, :f :txn, :value [[:w "t/a/f" "1"] [:w "t/a/g" "2"]], :process 0, :time 10, :index 1}
+{:type :invoke, :f :txn, :value [[:r "t/b/f" nil] [:r "t/b/g" nil]], :process 1, :time 20, :index 2}
+{:type :ok, :f :txn, :value [[:r "t/b/f" nil] [:r "t/b/g" nil]], :process 1, :time 30, :index 3}
+`
+	if buf.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
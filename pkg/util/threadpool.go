@@ -0,0 +1,91 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ThreadPool is a group of threads dedicated to some operation types.
+type ThreadPool struct {
+	Name       string
+	Threads    int
+	Operations []string
+	// Proportions are the proportions given to some operations as op=proportion,
+	// replacing the global ones in the pool.
+	Proportions map[string]float64
+	// Target is the throughput of the whole pool, 0 if unthrottled.
+	Target int64
+}
+
+// ParseThreadPools parses "name:threads:op[=proportion]|op...[:target],..." into
+// thread pools. The threads are given to the pools in order, starting from thread 0.
+func ParseThreadPools(s string) ([]ThreadPool, error) {
+	var pools []ThreadPool
+	for _, spec := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, fmt.Errorf("invalid thread pool %q; expecting name:threads:op[=proportion]|op...[:target]", spec)
+		}
+		pool := ThreadPool{Name: parts[0]}
+		var err error
+		if pool.Threads, err = strconv.Atoi(parts[1]); err != nil || pool.Threads < 1 {
+			return nil, fmt.Errorf("invalid thread count of thread pool %q", pool.Name)
+		}
+		for _, op := range strings.Split(parts[2], "|") {
+			pair := strings.SplitN(op, "=", 2)
+			name := strings.ToLower(strings.TrimSpace(pair[0]))
+			pool.Operations = append(pool.Operations, name)
+			if len(pair) == 2 {
+				proportion, err := strconv.ParseFloat(strings.TrimSpace(pair[1]), 64)
+				if err != nil || proportion <= 0 {
+					return nil, fmt.Errorf("invalid proportion of %s in thread pool %q", name, pool.Name)
+				}
+				if pool.Proportions == nil {
+					pool.Proportions = make(map[string]float64)
+				}
+				pool.Proportions[name] = proportion
+			}
+		}
+		if len(parts) == 4 {
+			if pool.Target, err = strconv.ParseInt(parts[3], 10, 64); err != nil || pool.Target < 0 {
+				return nil, fmt.Errorf("invalid target of thread pool %q", pool.Name)
+			}
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// ThreadPoolsSize returns the total number of threads of the pools.
+func ThreadPoolsSize(pools []ThreadPool) int {
+	n := 0
+	for _, pool := range pools {
+		n += pool.Threads
+	}
+	return n
+}
+
+// FindThreadPool returns the pool of the thread, or nil if it is in none.
+func FindThreadPool(pools []ThreadPool, threadID int) *ThreadPool {
+	for i := range pools {
+		if threadID < pools[i].Threads {
+			return &pools[i]
+		}
+		threadID -= pools[i].Threads
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreadPools(t *testing.T) {
+	pools, err := ParseThreadPools("scans:4:scan:100, point:28:read|update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ThreadPool{
+		{Name: "scans", Threads: 4, Operations: []string{"scan"}, Target: 100},
+		{Name: "point", Threads: 28, Operations: []string{"read", "update"}},
+	}
+	if !reflect.DeepEqual(pools, want) {
+		t.Fatalf("got %+v, want %+v", pools, want)
+	}
+	if n := ThreadPoolsSize(pools); n != 32 {
+		t.Fatalf("got %d threads, want 32", n)
+	}
+	for threadID, name := range map[int]string{0: "scans", 3: "scans", 4: "point", 31: "point"} {
+		if pool := FindThreadPool(pools, threadID); pool == nil || pool.Name != name {
+			t.Fatalf("thread %d: got %+v, want pool %s", threadID, pool, name)
+		}
+	}
+	if pool := FindThreadPool(pools, 32); pool != nil {
+		t.Fatalf("thread 32: got %+v, want no pool", pool)
+	}
+
+	pools, err = ParseThreadPools("writers:4:update=0.8|insert=0.2:1000,readers:28:read|scan=0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []ThreadPool{
+		{Name: "writers", Threads: 4, Operations: []string{"update", "insert"}, Proportions: map[string]float64{"update": 0.8, "insert": 0.2}, Target: 1000},
+		{Name: "readers", Threads: 28, Operations: []string{"read", "scan"}, Proportions: map[string]float64{"scan": 0.1}},
+	}
+	if !reflect.DeepEqual(pools, want) {
+		t.Fatalf("got %+v, want %+v", pools, want)
+	}
+
+	for _, s := range []string{"scans", "scans:0:scan", "scans:4:scan:-1", "scans:x:scan", "scans:4:scan=0", "scans:4:scan=x"} {
+		if _, err := ParseThreadPools(s); err == nil {
+			t.Fatalf("%q: want an error", s)
+		}
+	}
+}
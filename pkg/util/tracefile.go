@@ -0,0 +1,266 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// traceMagic starts the binary traces. After it, every record is the code of the
+// operation in TraceOps as a byte, the table and the key as uvarint lengths followed
+// by the bytes, the value size as a uvarint, and the microseconds since the previous
+// record as a uvarint.
+const traceMagic = "YCSBTRACE\x01"
+
+// TraceOps are the operations of the traces, in the order of their binary codes.
+var TraceOps = []string{"read", "update", "insert", "delete", "scan"}
+
+// TraceRecord is an operation of a trace. The size is the size of the value of the
+// writes, and the number of records of the scans.
+type TraceRecord struct {
+	Op    string
+	Table string
+	Key   string
+	Size  int64
+	// Timestamp is the time of the operation in microseconds, -1 if it isn't known
+	Timestamp int64
+}
+
+// TraceReader reads the records of a trace.
+type TraceReader interface {
+	// Next returns the next record, or io.EOF after the last one.
+	Next() (TraceRecord, error)
+}
+
+// NewTraceReader returns a reader of the CSV or binary trace, told apart by
+// traceMagic.
+func NewTraceReader(r io.Reader) TraceReader {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(traceMagic)); err == nil && string(magic) == traceMagic {
+		br.Discard(len(traceMagic))
+		return &binaryTraceReader{r: br}
+	}
+	cr := csv.NewReader(br)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	return &csvTraceReader{r: cr}
+}
+
+// csvTraceReader reads the "op,table,key,size,timestamp" lines of a CSV trace. The
+// size and the timestamp may be left out, an empty table is the default one, and the
+// lines starting with # and a header line are skipped.
+type csvTraceReader struct {
+	r       *csv.Reader
+	records int64
+}
+
+func (t *csvTraceReader) Next() (TraceRecord, error) {
+	for {
+		fields, err := t.r.Read()
+		if err != nil {
+			return TraceRecord{}, err
+		}
+		t.records++
+		op := strings.ToLower(strings.TrimSpace(fields[0]))
+		if op == "op" && t.records == 1 {
+			continue
+		}
+
+		record, err := parseTraceRecord(op, fields[1:])
+		if err != nil {
+			return TraceRecord{}, fmt.Errorf("trace record %d: %v", t.records, err)
+		}
+		return record, nil
+	}
+}
+
+func parseTraceRecord(op string, fields []string) (TraceRecord, error) {
+	record := TraceRecord{Op: op, Timestamp: -1}
+	if traceOpCode(op) < 0 {
+		return record, fmt.Errorf("unknown operation %q", op)
+	}
+	if len(fields) < 2 {
+		return record, fmt.Errorf("no key")
+	}
+	record.Table, record.Key = strings.TrimSpace(fields[0]), fields[1]
+
+	var err error
+	if len(fields) > 2 && strings.TrimSpace(fields[2]) != "" {
+		if record.Size, err = strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64); err != nil || record.Size < 0 {
+			return record, fmt.Errorf("invalid size %q", fields[2])
+		}
+	}
+	if len(fields) > 3 && strings.TrimSpace(fields[3]) != "" {
+		if record.Timestamp, err = strconv.ParseInt(strings.TrimSpace(fields[3]), 10, 64); err != nil || record.Timestamp < 0 {
+			return record, fmt.Errorf("invalid timestamp %q", fields[3])
+		}
+	}
+	return record, nil
+}
+
+// traceOpCode returns the binary code of the operation, -1 if it's unknown.
+func traceOpCode(op string) int {
+	for i, o := range TraceOps {
+		if o == op {
+			return i
+		}
+	}
+	return -1
+}
+
+// binaryTraceReader reads the records of a binary trace, after traceMagic.
+type binaryTraceReader struct {
+	r         *bufio.Reader
+	records   int64
+	timestamp int64
+}
+
+func (t *binaryTraceReader) Next() (TraceRecord, error) {
+	code, err := t.r.ReadByte()
+	if err != nil {
+		return TraceRecord{}, err
+	}
+	t.records++
+	record, err := t.readRecord(code)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return TraceRecord{}, fmt.Errorf("trace record %d: %v", t.records, err)
+	}
+	return record, nil
+}
+
+func (t *binaryTraceReader) readRecord(code byte) (TraceRecord, error) {
+	var record TraceRecord
+	if int(code) >= len(TraceOps) {
+		return record, fmt.Errorf("unknown operation %d", code)
+	}
+	record.Op = TraceOps[code]
+
+	var err error
+	if record.Table, err = t.readString(); err != nil {
+		return record, err
+	}
+	if record.Key, err = t.readString(); err != nil {
+		return record, err
+	}
+	size, err := binary.ReadUvarint(t.r)
+	if err != nil {
+		return record, err
+	}
+	record.Size = int64(size)
+	delta, err := binary.ReadUvarint(t.r)
+	if err != nil {
+		return record, err
+	}
+	t.timestamp += int64(delta)
+	record.Timestamp = t.timestamp
+	return record, nil
+}
+
+func (t *binaryTraceReader) readString() (string, error) {
+	n, err := binary.ReadUvarint(t.r)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(t.r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// TraceWriter writes the records of a CSV or binary trace. It isn't safe for
+// concurrent use.
+type TraceWriter struct {
+	w      *bufio.Writer
+	binary bool
+	// timestamp is the timestamp of the last record of a binary trace
+	timestamp int64
+	buf       []byte
+}
+
+// NewTraceWriter returns a writer of a binary trace, or else of a CSV trace, and
+// writes its magic or header line.
+func NewTraceWriter(w io.Writer, binaryFormat bool) (*TraceWriter, error) {
+	t := &TraceWriter{w: bufio.NewWriter(w), binary: binaryFormat}
+	var err error
+	if binaryFormat {
+		_, err = t.w.WriteString(traceMagic)
+	} else {
+		_, err = t.w.WriteString("op,table,key,size,timestamp\n")
+	}
+	return t, err
+}
+
+// Write writes the record. The binary traces can only go forward in time, so a
+// record older than the previous one is written with the time of the previous one,
+// and a record without a timestamp too.
+func (t *TraceWriter) Write(record TraceRecord) error {
+	code := traceOpCode(record.Op)
+	if code < 0 {
+		return fmt.Errorf("unknown operation %q", record.Op)
+	}
+	if !t.binary {
+		timestamp := ""
+		if record.Timestamp >= 0 {
+			timestamp = strconv.FormatInt(record.Timestamp, 10)
+		}
+		_, err := fmt.Fprintf(t.w, "%s,%s,%s,%d,%s\n", record.Op, csvField(record.Table), csvField(record.Key), record.Size, timestamp)
+		return err
+	}
+
+	var delta int64
+	if record.Timestamp > t.timestamp {
+		delta = record.Timestamp - t.timestamp
+		t.timestamp = record.Timestamp
+	}
+	b := append(t.buf[:0], byte(code))
+	b = appendUvarint(b, uint64(len(record.Table)))
+	b = append(b, record.Table...)
+	b = appendUvarint(b, uint64(len(record.Key)))
+	b = append(b, record.Key...)
+	b = appendUvarint(b, uint64(record.Size))
+	b = appendUvarint(b, uint64(delta))
+	t.buf = b
+	_, err := t.w.Write(b)
+	return err
+}
+
+func appendUvarint(b []byte, n uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], n)]...)
+}
+
+// csvField quotes the field if it has to be.
+func csvField(s string) string {
+	if s == "" || !strings.ContainsAny(s, ",\"\r\n") && s[0] != ' ' && s[0] != '\t' {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+// Flush writes the buffered records.
+func (t *TraceWriter) Flush() error {
+	return t.w.Flush()
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTraceRoundTrip(t *testing.T) {
+	records := []TraceRecord{
+		{Op: "insert", Table: "usertable", Key: "user1", Size: 100, Timestamp: 0},
+		{Op: "read", Table: "", Key: "a,\"quoted\" key", Timestamp: 250},
+		{Op: "scan", Table: "usertable", Key: " user2", Size: 10, Timestamp: 1000},
+		{Op: "delete", Table: "usertable", Key: "user3", Timestamp: 1000},
+	}
+
+	for _, binaryFormat := range []bool{false, true} {
+		var buf bytes.Buffer
+		w, err := NewTraceWriter(&buf, binaryFormat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range records {
+			if err := w.Write(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewTraceReader(&buf)
+		for i, want := range records {
+			got, err := r.Next()
+			if err != nil {
+				t.Fatalf("binary %t record %d: %v", binaryFormat, i, err)
+			}
+			if got != want {
+				t.Fatalf("binary %t record %d: got %+v, want %+v", binaryFormat, i, got, want)
+			}
+		}
+		if _, err := r.Next(); err != io.EOF {
+			t.Fatalf("binary %t: got %v after the last record, want EOF", binaryFormat, err)
+		}
+	}
+
+	if err := (&TraceWriter{}).Write(TraceRecord{Op: "cas"}); err == nil {
+		t.Fatal("an unknown operation was written")
+	}
+}
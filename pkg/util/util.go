@@ -18,7 +18,10 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 	"sync"
+
+	"github.com/magiconair/properties"
 )
 
 // Fatalf prints the message and exits the program.
@@ -44,6 +47,70 @@ func RandBytes(r *rand.Rand, b []byte) {
 	}
 }
 
+// CompressibleRandBytes fills the bytes with random bytes which compress about ratio
+// times, by repeating a random prefix of 1/ratio of them, as db_bench does.
+func CompressibleRandBytes(r *rand.Rand, b []byte, ratio float64) {
+	n := int(float64(len(b))/ratio + 0.5)
+	if n < 1 {
+		n = 1
+	}
+	if n > len(b) {
+		n = len(b)
+	}
+	r.Read(b[:n])
+	for i := n; i < len(b); i += n {
+		copy(b[i:], b[:n])
+	}
+}
+
+// JavaRandBytes fills the bytes with printable characters randomly, with the
+// character distribution of Java YCSB's RandomByteIterator.
+func JavaRandBytes(r *rand.Rand, b []byte) {
+	for base := 0; base < len(b); base += 6 {
+		bytes := int32(r.Uint32())
+		switch len(b) - base {
+		default:
+			b[base+5] = byte((bytes>>25)&95) + ' '
+			fallthrough
+		case 5:
+			b[base+4] = byte((bytes>>20)&63) + ' '
+			fallthrough
+		case 4:
+			b[base+3] = byte((bytes>>15)&31) + ' '
+			fallthrough
+		case 3:
+			b[base+2] = byte((bytes>>10)&95) + ' '
+			fallthrough
+		case 2:
+			b[base+1] = byte((bytes>>5)&63) + ' '
+			fallthrough
+		case 1:
+			b[base] = byte(bytes&31) + ' '
+		}
+	}
+}
+
+// secretWords are the words in the names of the properties whose values are secrets,
+// e.g. mysql.password.
+var secretWords = []string{"password", "secret", "token"}
+
+// RedactedProperties returns the properties by name, with the values of the secret
+// ones, whose names contain password, secret or token, replaced, so that they can be
+// printed or exported.
+func RedactedProperties(p *properties.Properties) map[string]string {
+	m := p.Map()
+	for key := range m {
+		lower := strings.ToLower(key)
+		for _, word := range secretWords {
+			if strings.Contains(lower, word) {
+				m[key] = "<redacted>"
+				break
+			}
+		}
+	}
+	return m
+}
+
 // BufPool is a bytes.Buffer pool
 type BufPool struct {
 	p *sync.Pool
@@ -0,0 +1,45 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+)
+
+func TestRedactedProperties(t *testing.T) {
+	p := properties.LoadMap(map[string]string{
+		"mysql.password": "hunter2",
+		"s3.SecretKey":   "abc",
+		"influxdb.token": "xyz",
+		"mysql.host":     "db1",
+		"operationcount": "100",
+	})
+	m := RedactedProperties(p)
+	for key, want := range map[string]string{
+		"mysql.password": "<redacted>",
+		"s3.SecretKey":   "<redacted>",
+		"influxdb.token": "<redacted>",
+		"mysql.host":     "db1",
+		"operationcount": "100",
+	} {
+		if m[key] != want {
+			t.Errorf("%s: got %q, want %q", key, m[key], want)
+		}
+	}
+	if v, _ := p.Get("mysql.password"); v != "hunter2" {
+		t.Fatalf("the properties were modified: %q", v)
+	}
+}
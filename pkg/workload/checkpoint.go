@@ -0,0 +1,278 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// checkpointState is what the load checkpoint file holds: the records of the whole
+// load, and the first one which may not be inserted yet.
+type checkpointState struct {
+	InsertStart int64 `json:"insertstart"`
+	InsertCount int64 `json:"insertcount"`
+	Next        int64 `json:"next"`
+	// Inserted holds the ranges of records from Next on which are inserted, as
+	// [first, last] pairs, and Attempted is the record after the last one the load
+	// started to insert. The other records in between may be inserted or not.
+	Inserted  [][2]int64 `json:"inserted,omitempty"`
+	Attempted int64      `json:"attempted,omitempty"`
+}
+
+func readCheckpoint(path string) (*checkpointState, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(checkpointState)
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decode %s failed %v", path, err)
+	}
+	return s, nil
+}
+
+// ResumeLoad moves insertstart and insertcount past the records the load checkpoint
+// holds as inserted, if load.resume is set, so that an interrupted load resumes where
+// it left off. It must be called before the workload is created.
+func ResumeLoad(p *properties.Properties) error {
+	if !p.GetBool(prop.LoadResume, false) {
+		return nil
+	}
+	path := p.GetString(prop.LoadCheckpoint, "")
+	if path == "" {
+		return fmt.Errorf("%s needs a %s file", prop.LoadResume, prop.LoadCheckpoint)
+	}
+	s, err := readCheckpoint(path)
+	if err != nil {
+		return err
+	}
+
+	start := p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	count := p.GetInt64(prop.InsertCount, p.GetInt64(prop.RecordCount, prop.RecordCountDefault)-start)
+	if s.InsertStart != start || s.InsertCount != count {
+		return fmt.Errorf("the checkpoint is of the load of %d records from %d, not %d from %d", s.InsertCount, s.InsertStart, count, start)
+	}
+	if s.Next >= start+count {
+		return fmt.Errorf("the checkpointed load of %d records from %d is complete", count, start)
+	}
+	inserted := s.Next - start
+	for _, r := range s.Inserted {
+		inserted += r[1] - r[0] + 1
+	}
+	fmt.Printf("Resuming the load at record %d, %d of %d records are inserted\n", s.Next, inserted, count)
+	p.Set(prop.InsertStart, strconv.FormatInt(s.Next, 10))
+	p.Set(prop.InsertCount, strconv.FormatInt(start+count-s.Next, 10))
+	return nil
+}
+
+// loadCheckpoint periodically saves the first record which may not be inserted yet,
+// below which the inserts all succeeded, and the records above it which are inserted,
+// for load.resume.
+type loadCheckpoint struct {
+	path string
+	// state holds the range of the whole load, which a resumed load is part of
+	state checkpointState
+	// inserted counts the records whose inserts are done, failed is the first
+	// record which failed to insert, and attempted the one after the last record
+	// the load started to insert
+	inserted  *generator.AcknowledgedCounter
+	failed    int64
+	attempted int64
+	// succeeded holds the inserted records from the first one which may not be, as
+	// ranges
+	succeeded keyRanges
+	// resumed is the checkpoint the load resumed from, nil if it didn't
+	resumed *checkpointState
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newLoadCheckpoint returns nil unless load.checkpoint is set. The load inserts from
+// insertStart, and must take its keys from the inserted counter.
+func newLoadCheckpoint(p *properties.Properties, insertStart int64, insertCount int64) (*loadCheckpoint, error) {
+	path := p.GetString(prop.LoadCheckpoint, "")
+	if path == "" {
+		return nil, nil
+	}
+	interval, err := time.ParseDuration(p.GetString(prop.LoadCheckpointInterval, prop.LoadCheckpointIntervalDefault))
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid %s", prop.LoadCheckpointInterval)
+	}
+
+	c := &loadCheckpoint{
+		path:      path,
+		state:     checkpointState{InsertStart: insertStart, InsertCount: insertCount},
+		inserted:  generator.NewAcknowledgedCounter(insertStart),
+		failed:    math.MaxInt64,
+		attempted: insertStart,
+		succeeded: newKeyRanges(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if p.GetBool(prop.LoadResume, false) {
+		// ResumeLoad moved insertstart, the checkpoint keeps the whole range
+		s, err := readCheckpoint(path)
+		if err != nil {
+			return nil, err
+		}
+		c.state.InsertStart, c.state.InsertCount = s.InsertStart, s.InsertCount
+		c.resumed = s
+	}
+
+	go func() {
+		defer close(c.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if err := c.save(); err != nil {
+					fmt.Printf("Save load checkpoint failed: %v\n", err)
+				}
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// insertStarted records that the load started to insert the record. It returns
+// whether the checkpoint the load resumed from holds the record as inserted, and
+// whether it may be inserted, if the load started to insert it without the
+// checkpoint recording the result.
+func (c *loadCheckpoint) insertStarted(keyNum int64) (inserted bool, inDoubt bool) {
+	for {
+		attempted := atomic.LoadInt64(&c.attempted)
+		if keyNum < attempted || atomic.CompareAndSwapInt64(&c.attempted, attempted, keyNum+1) {
+			break
+		}
+	}
+	if c.resumed == nil || keyNum < c.resumed.Next || keyNum >= c.resumed.Attempted {
+		return false, false
+	}
+	ranges := c.resumed.Inserted
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i][1] >= keyNum })
+	if i < len(ranges) && ranges[i][0] <= keyNum {
+		return true, false
+	}
+	return false, true
+}
+
+// insertDone marks the insert of the record as done, failed if err isn't nil.
+func (c *loadCheckpoint) insertDone(keyNum int64, err error) {
+	if err != nil {
+		for {
+			failed := atomic.LoadInt64(&c.failed)
+			if keyNum >= failed || atomic.CompareAndSwapInt64(&c.failed, failed, keyNum) {
+				break
+			}
+		}
+	} else {
+		c.succeeded.add(keyNum)
+	}
+	c.inserted.Acknowledge(keyNum)
+}
+
+// save writes the checkpoint to a temporary file first, so that it is never partially written.
+func (c *loadCheckpoint) save() error {
+	s := c.state
+	c.inserted.Flush()
+	s.Next = c.inserted.Last() + 1
+	if failed := atomic.LoadInt64(&c.failed); failed < s.Next {
+		s.Next = failed
+	}
+	s.Inserted = c.succeeded.from(s.Next)
+	s.Attempted = atomic.LoadInt64(&c.attempted)
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// keyRanges is a set of records held as the ranges of consecutive records, which the
+// records inserted in about the order of their keys keep few.
+type keyRanges struct {
+	sync.Mutex
+	// byFirst and byLast map the first record of every range to its last one, and back
+	byFirst map[int64]int64
+	byLast  map[int64]int64
+}
+
+func newKeyRanges() keyRanges {
+	return keyRanges{byFirst: make(map[int64]int64), byLast: make(map[int64]int64)}
+}
+
+func (r *keyRanges) add(keyNum int64) {
+	r.Lock()
+	defer r.Unlock()
+	first, last := keyNum, keyNum
+	if f, ok := r.byLast[keyNum-1]; ok {
+		first = f
+		delete(r.byLast, keyNum-1)
+	}
+	if l, ok := r.byFirst[keyNum+1]; ok {
+		last = l
+		delete(r.byFirst, keyNum+1)
+	}
+	r.byFirst[first] = last
+	r.byLast[last] = first
+}
+
+// from returns the sorted ranges of the records from start on, and forgets the
+// records below it.
+func (r *keyRanges) from(start int64) [][2]int64 {
+	r.Lock()
+	defer r.Unlock()
+	var ranges [][2]int64
+	for first, last := range r.byFirst {
+		switch {
+		case last < start:
+			delete(r.byFirst, first)
+			delete(r.byLast, last)
+		case first < start:
+			ranges = append(ranges, [2]int64{start, last})
+		default:
+			ranges = append(ranges, [2]int64{first, last})
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	return ranges
+}
+
+// close saves the checkpoint a last time.
+func (c *loadCheckpoint) close() error {
+	close(c.stop)
+	<-c.done
+	return c.save()
+}
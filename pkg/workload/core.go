@@ -16,6 +16,8 @@ package workload
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -40,6 +42,33 @@ type coreState struct {
 	r *rand.Rand
 	// fieldNames is a copy of core.fieldNames to be goroutine-local
 	fieldNames []string
+	// operationChooser is the chooser of the thread pool of the thread
+	operationChooser *generator.Discrete
+	// tenant is the tenant of the current transaction
+	tenant int64
+	// txEffects holds the effects of the operations of the current transaction of
+	// transactionsize operations on the workload, which apply once it ends, nil
+	// outside of one
+	txEffects []func(committed bool)
+}
+
+// afterCommit applies the effect of an operation on the workload, given whether the
+// operation succeeded and, in a transaction, whether the transaction committed:
+// outside of a transaction at once, and in one once the transaction ends.
+func (state *coreState) afterCommit(succeeded bool, effect func(committed bool)) {
+	if !succeeded || state.txEffects == nil {
+		effect(succeeded)
+		return
+	}
+	state.txEffects = append(state.txEffects, effect)
+}
+
+// endTx applies the effects of the operations of the current transaction.
+func (state *coreState) endTx(committed bool) {
+	for _, effect := range state.txEffects {
+		effect(committed)
+	}
+	state.txEffects = state.txEffects[:0]
 }
 
 type operationType int64
@@ -50,32 +79,88 @@ const (
 	insert
 	scan
 	readModifyWrite
+	// remove deletes a record, named so as not to shadow the builtin
+	remove
+	batchRead
+	batchUpdate
+	batchInsert
+	batchDelete
+	compareAndSwap
+	multiGet
+	query
 )
 
 // Core is the core benchmark scenario. Represents a set of clients doing simple CRUD operations.
 type core struct {
 	p *properties.Properties
 
-	table      string
+	// tables are the tables the keys are spread over
+	tables     []string
 	fieldCount int64
 	fieldNames []string
 
 	fieldLengthGenerator ycsb.Generator
 	readAllFields        bool
-	writeAllFields       bool
-	dataIntegrity        bool
+	// readFieldCount draws the number of fields of every read, and readFieldChooser
+	// them, nil to leave it to readAllFields
+	readFieldCount   ycsb.Generator
+	readFieldChooser ycsb.Generator
+	writeAllFields   bool
+	dataIntegrity    bool
+	// javaCompatible generates the keys and values like Java YCSB
+	javaCompatible bool
+	// verifiableValues builds values with a checksum, which are verified on their own
+	verifiableValues bool
+	// generation counts the verifiable values built
+	generation int64
+	// fieldCompressibility is the ratio the random values compress by, 0 for letters
+	fieldCompressibility float64
 
 	keySequence                  ycsb.Generator
 	operationChooser             *generator.Discrete
+	threadPools                  []util.ThreadPool
+	poolOperationChoosers        map[string]*generator.Discrete
 	keyChooser                   ycsb.Generator
 	fieldChooser                 ycsb.Generator
 	transactionInsertKeySequence *generator.AcknowledgedCounter
 	scanLength                   ycsb.Generator
-	orderedInserts               bool
-	recordCount                  int64
-	zeroPadding                  int64
-	insertionRetryLimit          int64
-	insertionRetryInterval       int64
+	// batchOperationSize is the number of records of the batch operation types
+	batchOperationSize int
+	// multiGetSize is the number of records of the multi-get operations
+	multiGetSize int
+	// recordTTL is the time after which the written records expire, 0 if they don't
+	recordTTL time.Duration
+	// tenantCount is the number of tenants the keys are split between, whose keys
+	// are prefixed by tenantPrefix and the tenant, and tenantChooser chooses the
+	// tenant of every transaction
+	tenantCount   int64
+	tenantPrefix  string
+	tenantChooser ycsb.Generator
+	// indexField is the field with index.cardinality values the queries filter on,
+	// "" if there isn't one, and indexWidth is the number of digits of the values
+	indexField       string
+	indexCardinality int64
+	indexWidth       int
+	indexQueryLength int
+	// transactionSize is the number of operations run in every transaction
+	transactionSize int64
+	// deletedKeys are the keys the transactions deleted, nil if they don't delete
+	deletedKeys *deletedKeys
+	// deletedReadProportion is the proportion of the reads which read a deleted key
+	deletedReadProportion float64
+	// failedInserts are the records whose inserts failed, or were in a transaction
+	// which didn't commit, which AckedRecords leaves out
+	failedInserts keySet
+	// checkpoint saves how far the load got, nil unless load.checkpoint is set
+	checkpoint             *loadCheckpoint
+	keyFormat              string
+	recordCount            int64
+	insertStart            int64
+	insertCount            int64
+	zeroPadding            int64
+	insertionRetryLimit    int64
+	insertionRetryInterval int64
+	seed                   int64
 
 	valuePool sync.Pool
 }
@@ -102,35 +187,98 @@ func getFieldLengthGenerator(p *properties.Properties) ycsb.Generator {
 	return fieldLengthGenerator
 }
 
-func createOperationGenerator(p *properties.Properties) *generator.Discrete {
-	readProportion := p.GetFloat64(prop.ReadProportion, prop.ReadProportionDefault)
-	updateProportion := p.GetFloat64(prop.UpdateProportion, prop.UpdateProportionDefault)
-	insertProportion := p.GetFloat64(prop.InsertProportion, prop.InsertProportionDefault)
-	scanProportion := p.GetFloat64(prop.ScanProportion, prop.ScanProportionDefault)
-	readModifyWriteProportion := p.GetFloat64(prop.ReadModifyWriteProportion, prop.ReadModifyWriteProportionDefault)
+// operationNames are the names of the operation types in the threadpools property.
+var operationNames = map[string]operationType{
+	"read":            read,
+	"update":          update,
+	"insert":          insert,
+	"scan":            scan,
+	"readmodifywrite": readModifyWrite,
+	"delete":          remove,
+	"batchread":       batchRead,
+	"batchupdate":     batchUpdate,
+	"batchinsert":     batchInsert,
+	"batchdelete":     batchDelete,
+	"cas":             compareAndSwap,
+	"multiget":        multiGet,
+	"query":           query,
+}
 
-	operationChooser := generator.NewDiscrete()
-	if readProportion > 0 {
-		operationChooser.Add(readProportion, int64(read))
-	}
+// operationProportions are the properties of the proportions of the operation types,
+// in the order they are added to the choosers.
+var operationProportions = []struct {
+	op           operationType
+	name         string
+	defaultValue float64
+}{
+	{read, prop.ReadProportion, prop.ReadProportionDefault},
+	{update, prop.UpdateProportion, prop.UpdateProportionDefault},
+	{insert, prop.InsertProportion, prop.InsertProportionDefault},
+	{scan, prop.ScanProportion, prop.ScanProportionDefault},
+	{readModifyWrite, prop.ReadModifyWriteProportion, prop.ReadModifyWriteProportionDefault},
+	{remove, prop.DeleteProportion, prop.DeleteProportionDefault},
+	{batchRead, prop.BatchReadProportion, prop.BatchReadProportionDefault},
+	{batchUpdate, prop.BatchUpdateProportion, prop.BatchUpdateProportionDefault},
+	{batchInsert, prop.BatchInsertProportion, prop.BatchInsertProportionDefault},
+	{batchDelete, prop.BatchDeleteProportion, prop.BatchDeleteProportionDefault},
+	{compareAndSwap, prop.CASProportion, prop.CASProportionDefault},
+	{multiGet, prop.MultiGetProportion, prop.MultiGetProportionDefault},
+	{query, prop.QueryProportion, prop.QueryProportionDefault},
+}
 
-	if updateProportion > 0 {
-		operationChooser.Add(updateProportion, int64(update))
+// createOperationGenerator creates the chooser of the operation types. If ops isn't nil,
+// only the given operation types are chosen, keeping their relative proportions
+// unless proportions, keyed by operation name, replaces them.
+func createOperationGenerator(p *properties.Properties, ops []string, proportions map[string]float64) *generator.Discrete {
+	operationChooser := generator.NewDiscrete()
+	for _, o := range operationProportions {
+		if proportion := operationProportion(p, o.op, o.name, o.defaultValue, ops, proportions); proportion > 0 {
+			operationChooser.Add(proportion, int64(o.op))
+		}
 	}
+	return operationChooser
+}
 
-	if insertProportion > 0 {
-		operationChooser.Add(insertProportion, int64(insert))
+// operationProportion returns the proportion of the operation type op in the chooser
+// createOperationGenerator creates, 0 if it isn't chosen.
+func operationProportion(p *properties.Properties, op operationType, name string, defaultValue float64, ops []string, proportions map[string]float64) float64 {
+	if ops == nil {
+		return p.GetFloat64(name, defaultValue)
 	}
-
-	if scanProportion > 0 {
-		operationChooser.Add(scanProportion, int64(scan))
+	for _, opName := range ops {
+		if operationNames[opName] != op {
+			continue
+		}
+		if proportion, ok := proportions[opName]; ok {
+			return proportion
+		}
+		return p.GetFloat64(name, defaultValue)
 	}
+	return 0
+}
 
-	if readModifyWriteProportion > 0 {
-		operationChooser.Add(readModifyWriteProportion, int64(readModifyWrite))
+// createPoolOperationGenerators creates the choosers of the operation types of the thread pools.
+func createPoolOperationGenerators(p *properties.Properties, pools []util.ThreadPool) map[string]*generator.Discrete {
+	choosers := make(map[string]*generator.Discrete, len(pools))
+	for _, pool := range pools {
+		if _, ok := choosers[pool.Name]; ok {
+			util.Fatalf("duplicate thread pool %s", pool.Name)
+		}
+		for _, name := range pool.Operations {
+			if _, ok := operationNames[name]; !ok {
+				util.Fatalf("unknown operation %s of thread pool %s", name, pool.Name)
+			}
+		}
+		total := float64(0)
+		for _, o := range operationProportions {
+			total += operationProportion(p, o.op, o.name, o.defaultValue, pool.Operations, pool.Proportions)
+		}
+		if total <= 0 {
+			util.Fatalf("the operations of thread pool %s all have a zero proportion", pool.Name)
+		}
+		choosers[pool.Name] = createOperationGenerator(p, pool.Operations, pool.Proportions)
 	}
-
-	return operationChooser
+	return choosers
 }
 
 // Init --create all schemas for ycsb workload
@@ -138,30 +286,48 @@ func (c *core) Init(db ycsb.DB) error {
 	// need to redesign the relation btw workload and db interface later.
 	sqlDB := db.ToSqlDB()
 	if sqlDB != nil {
-		tableName := c.p.GetString(prop.TableName, prop.TableNameDefault)
-		if c.p.GetBool(prop.DropData, prop.DropDataDefault) && !c.p.GetBool(prop.DoTransactions, true) {
-			if _, err := sqlDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)); err != nil {
+		for _, tableName := range c.tables {
+			if err := c.createTable(sqlDB, tableName); err != nil {
 				return err
 			}
 		}
+	}
+	if c.indexField != "" {
+		queryDB, ok := db.(ycsb.QueryDB)
+		if !ok {
+			return fmt.Errorf("the %T does't implement the QueryDB interface", db)
+		}
+		for _, tableName := range c.tables {
+			if err := queryDB.CreateIndex(context.Background(), tableName, c.indexField); err != nil {
+				return fmt.Errorf("create the index of %s failed: %v", c.indexField, err)
+			}
+		}
+	}
+	return nil
+}
 
-		fieldCount := c.p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
-		fieldLength := c.p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
-
-		buf := new(bytes.Buffer)
-		s := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (YCSB_KEY VARCHAR(64) PRIMARY KEY", tableName)
-		buf.WriteString(s)
-
-		for i := int64(0); i < fieldCount; i++ {
-			buf.WriteString(fmt.Sprintf(", FIELD%d VARCHAR(%d)", i, fieldLength))
+func (c *core) createTable(sqlDB *sql.DB, tableName string) error {
+	if c.p.GetBool(prop.DropData, prop.DropDataDefault) && !c.p.GetBool(prop.DoTransactions, true) {
+		if _, err := sqlDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)); err != nil {
+			return err
 		}
+	}
 
-		buf.WriteString(");")
+	fieldCount := c.p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	fieldLength := c.p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
 
-		_, err := sqlDB.Exec(buf.String())
-		return err
+	buf := new(bytes.Buffer)
+	s := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (YCSB_KEY VARCHAR(64) PRIMARY KEY", tableName)
+	buf.WriteString(s)
+
+	for i := int64(0); i < fieldCount; i++ {
+		buf.WriteString(fmt.Sprintf(", FIELD%d VARCHAR(%d)", i, fieldLength))
 	}
-	return nil
+
+	buf.WriteString(");")
+
+	_, err := sqlDB.Exec(buf.String())
+	return err
 }
 
 // Load implements the Workload Load interface.
@@ -170,13 +336,21 @@ func (c *core) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
 }
 
 // InitThread implements the Workload InitThread interface.
-func (c *core) InitThread(ctx context.Context, _ int, _ int) context.Context {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func (c *core) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	seed := time.Now().UnixNano()
+	if c.seed != 0 {
+		seed = c.seed + int64(threadID)
+	}
+	r := rand.New(rand.NewSource(seed))
 	fieldNames := make([]string, len(c.fieldNames))
 	copy(fieldNames, c.fieldNames)
 	state := &coreState{
-		r:          r,
-		fieldNames: fieldNames,
+		r:                r,
+		fieldNames:       fieldNames,
+		operationChooser: c.operationChooser,
+	}
+	if pool := util.FindThreadPool(c.threadPools, threadID); pool != nil {
+		state.operationChooser = c.poolOperationChoosers[pool.Name]
 	}
 	return context.WithValue(ctx, stateKey, state)
 }
@@ -188,16 +362,75 @@ func (c *core) CleanupThread(_ context.Context) {
 
 // Close implements the Workload Close interface.
 func (c *core) Close() error {
+	if c.checkpoint != nil {
+		return c.checkpoint.close()
+	}
 	return nil
 }
 
+// KeyName implements the KeyNameWorkload KeyName interface.
+func (c *core) KeyName(keyNum int64) string {
+	return c.buildKeyName(keyNum)
+}
+
 func (c *core) buildKeyName(keyNum int64) string {
-	if !c.orderedInserts {
-		keyNum = util.Hash64(keyNum)
+	prefix := c.p.GetString(prop.KeyPrefix, prop.KeyPrefixDefault)
+	if c.tenantCount > 1 {
+		prefix = c.tenantPrefix + strconv.FormatInt(keyNum%c.tenantCount, 10) + prefix
 	}
 
-	prefix := c.p.GetString(prop.KeyPrefix, prop.KeyPrefixDefault)
-	return fmt.Sprintf("%s%0[3]*[2]d", prefix, keyNum, c.zeroPadding)
+	switch c.keyFormat {
+	case "ordered":
+		return fmt.Sprintf("%s%0[3]*[2]d", prefix, keyNum, c.zeroPadding)
+	case "hex":
+		return fmt.Sprintf("%s%0[3]*[2]x", prefix, util.Hash64(keyNum), c.zeroPadding)
+	case "uuid":
+		return prefix + buildUUID(keyNum)
+	}
+	return fmt.Sprintf("%s%0[3]*[2]d", prefix, util.Hash64(keyNum), c.zeroPadding)
+}
+
+// buildUUID returns a version 4 UUID derived from the hashes of the record number, so
+// that every record keeps its key.
+func buildUUID(keyNum int64) string {
+	a, b := uint64(util.Hash64(keyNum)), uint64(util.Hash64(^keyNum))
+	// Hash64 is non-negative, so the top bit of a is always 0
+	hi := a<<1 | b&1
+	hi = hi&^0xf000 | 0x4000
+	lo := b&^(3<<62) | 2<<62
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", hi>>32, hi>>16&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff)
+}
+
+// readFields returns the fields a read projects: readfieldcount of them if set,
+// otherwise all or one as selected by readallfields.
+func (c *core) readFields(state *coreState) []string {
+	if c.readFieldCount != nil {
+		return c.chooseFields(state, c.readFieldCount.Next(state.r))
+	}
+	if !c.readAllFields {
+		return []string{state.fieldNames[c.fieldChooser.Next(state.r)]}
+	}
+	return state.fieldNames
+}
+
+// chooseFields returns n distinct fields drawn by readFieldChooser, in field order. A
+// field drawn again is replaced by the next one not chosen yet.
+func (c *core) chooseFields(state *coreState, n int64) []string {
+	chosen := make([]bool, len(state.fieldNames))
+	for i := int64(0); i < n; i++ {
+		j := c.readFieldChooser.Next(state.r)
+		for chosen[j] {
+			j = (j + 1) % int64(len(chosen))
+		}
+		chosen[j] = true
+	}
+	fields := make([]string, 0, n)
+	for i, ok := range chosen {
+		if ok {
+			fields = append(fields, state.fieldNames[i])
+		}
+	}
+	return fields
 }
 
 func (c *core) buildSingleValue(state *coreState, key string) map[string][]byte {
@@ -206,14 +439,7 @@ func (c *core) buildSingleValue(state *coreState, key string) map[string][]byte
 	r := state.r
 	fieldKey := state.fieldNames[c.fieldChooser.Next(r)]
 
-	var buf []byte
-	if c.dataIntegrity {
-		buf = c.buildDeterministicValue(state, key, fieldKey)
-	} else {
-		buf = c.buildRandomValue(state)
-	}
-
-	values[fieldKey] = buf
+	values[fieldKey] = c.buildValue(state, key, fieldKey)
 
 	return values
 }
@@ -222,18 +448,30 @@ func (c *core) buildValues(state *coreState, key string) map[string][]byte {
 	values := make(map[string][]byte, c.fieldCount)
 
 	for _, fieldKey := range state.fieldNames {
-		var buf []byte
-		if c.dataIntegrity {
-			buf = c.buildDeterministicValue(state, key, fieldKey)
-		} else {
-			buf = c.buildRandomValue(state)
-		}
-
-		values[fieldKey] = buf
+		values[fieldKey] = c.buildValue(state, key, fieldKey)
 	}
 	return values
 }
 
+// buildValue builds the value of a field, which can be verified on read with dataintegrity.
+func (c *core) buildValue(state *coreState, key string, fieldKey string) []byte {
+	switch {
+	case fieldKey == c.indexField:
+		return c.buildIndexValue(state)
+	case c.verifiableValues:
+		return c.buildVerifiableValue(state, key, fieldKey)
+	case c.dataIntegrity:
+		return c.buildDeterministicValue(state, key, fieldKey)
+	default:
+		return c.buildRandomValue(state)
+	}
+}
+
+// buildIndexValue returns one of the index.cardinality values of the indexed field.
+func (c *core) buildIndexValue(state *coreState) []byte {
+	return []byte(fmt.Sprintf("v%0*d", c.indexWidth, state.r.Int63n(c.indexCardinality)))
+}
+
 func (c *core) getValueBuffer(size int) []byte {
 	buf := c.valuePool.Get().([]byte)
 	if cap(buf) >= size {
@@ -253,7 +491,13 @@ func (c *core) buildRandomValue(state *coreState) []byte {
 	// TODO: use pool for the buffer
 	r := state.r
 	buf := c.getValueBuffer(int(c.fieldLengthGenerator.Next(r)))
-	util.RandBytes(r, buf)
+	if c.fieldCompressibility > 0 {
+		util.CompressibleRandBytes(r, buf, c.fieldCompressibility)
+	} else if c.javaCompatible {
+		util.JavaRandBytes(r, buf)
+	} else {
+		util.RandBytes(r, buf)
+	}
 	return buf
 }
 
@@ -265,6 +509,16 @@ func (c *core) buildDeterministicValue(state *coreState, key string, fieldKey st
 	b := bytes.NewBuffer(buf[0:0])
 	b.WriteString(key)
 	b.WriteByte(':')
+	if c.javaCompatible {
+		// Java YCSB appends the signed String.hashCode of the value so far
+		b.WriteString(fieldKey)
+		for int64(b.Len()) < size {
+			b.WriteByte(':')
+			b.WriteString(strconv.FormatInt(int64(javaHashCode(b.Bytes())), 10))
+		}
+		b.Truncate(int(size))
+		return b.Bytes()
+	}
 	b.WriteString(strings.ToLower(fieldKey))
 	for int64(b.Len()) < size {
 		b.WriteByte(':')
@@ -275,18 +529,69 @@ func (c *core) buildDeterministicValue(state *coreState, key string, fieldKey st
 	return b.Bytes()
 }
 
-func (c *core) verifyRow(state *coreState, key string, values map[string][]byte) {
+// javaHashCode returns Java's String.hashCode of an ASCII string.
+func javaHashCode(b []byte) int32 {
+	h := int32(0)
+	for _, c := range b {
+		h = 31*h + int32(c)
+	}
+	return h
+}
+
+// verifyRow checks the values read from the record of the key, and measures the check
+// as VERIFY, or VERIFY_ERROR if a value is unexpected.
+func (c *core) verifyRow(state *coreState, key string, values map[string][]byte) error {
 	if len(values) == 0 {
 		// null data here, need panic?
-		return
+		return nil
 	}
 
+	start := measurement.Now()
+	err := c.verifyValues(state, key, values)
+	if err != nil {
+		measurement.Measure("VERIFY_ERROR", measurement.Now().Sub(start))
+		return err
+	}
+	measurement.Measure("VERIFY", measurement.Now().Sub(start))
+	return nil
+}
+
+func (c *core) verifyValues(state *coreState, key string, values map[string][]byte) error {
 	for fieldKey, value := range values {
+		if c.verifiableValues {
+			if err := c.checkVerifiableValue(key, fieldKey, value); err != nil {
+				return fmt.Errorf("unexpected value of %s of %s: %v, got %q", fieldKey, key, err, value)
+			}
+			continue
+		}
 		expected := c.buildDeterministicValue(state, key, fieldKey)
 		if !bytes.Equal(expected, value) {
-			util.Fatalf("unexpected deterministic value, expect %q, but got %q", expected, value)
+			return fmt.Errorf("unexpected deterministic value of %s of %s, expect %q, but got %q", fieldKey, key, expected, value)
+		}
+	}
+	return nil
+}
+
+// verifyRows checks the rows of a scan, whose keys are only known from the values,
+// which start with them.
+func (c *core) verifyRows(state *coreState, table string, rows []map[string][]byte) error {
+	var firstErr error
+	for _, row := range rows {
+		var key string
+		for _, value := range row {
+			if c.verifiableValues {
+				value = bytes.TrimPrefix(value, []byte(table+":"))
+			}
+			if i := bytes.IndexByte(value, ':'); i >= 0 {
+				key = string(value[:i])
+			}
+			break
+		}
+		if err := c.verifyRow(state, key, row); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
+	return firstErr
 }
 
 // DoInsert implements the Workload DoInsert interface.
@@ -295,14 +600,27 @@ func (c *core) DoInsert(ctx context.Context, db ycsb.DB) error {
 	r := state.r
 	keyNum := c.keySequence.Next(r)
 	dbKey := c.buildKeyName(keyNum)
+	if c.checkpoint != nil && c.resumedInsert(ctx, db, keyNum, dbKey) {
+		c.checkpoint.insertDone(keyNum, nil)
+		return nil
+	}
 	values := c.buildValues(state, dbKey)
 	defer c.putValues(values)
 
 	numOfRetries := int64(0)
 
 	var err error
+	defer func() {
+		if err != nil {
+			c.failedInserts.add(keyNum)
+		}
+		// an insert stopped by the end of the run isn't done
+		if c.checkpoint != nil && ctx.Err() != context.Canceled {
+			c.checkpoint.insertDone(keyNum, err)
+		}
+	}()
 	for {
-		err = db.Insert(ctx, c.table, dbKey, values)
+		err = c.insert(ctx, db, dbKey, values)
 		if err == nil {
 			break
 		}
@@ -332,6 +650,18 @@ func (c *core) DoInsert(ctx context.Context, db ycsb.DB) error {
 	return err
 }
 
+// resumedInsert returns whether the record is already inserted by the load this one
+// resumes, either as the checkpoint holds, or as a read finds if the checkpoint
+// doesn't hold the result of its insert, so that it isn't inserted twice.
+func (c *core) resumedInsert(ctx context.Context, db ycsb.DB, keyNum int64, dbKey string) bool {
+	inserted, inDoubt := c.checkpoint.insertStarted(keyNum)
+	if inDoubt {
+		_, err := db.Read(ctx, c.tableOf(dbKey), dbKey, nil)
+		inserted = err == nil
+	}
+	return inserted
+}
+
 // DoBatchInsert implements the Workload DoBatchInsert interface.
 func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
 	batchDB, ok := db.(ycsb.BatchDB)
@@ -342,12 +672,21 @@ func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) err
 	r := state.r
 	var keys []string
 	var values []map[string][]byte
+	var keyNums []int64
 	for i := 0; i < batchSize; i++ {
 		keyNum := c.keySequence.Next(r)
 		dbKey := c.buildKeyName(keyNum)
+		if c.checkpoint != nil && c.resumedInsert(ctx, db, keyNum, dbKey) {
+			c.checkpoint.insertDone(keyNum, nil)
+			continue
+		}
 		keys = append(keys, dbKey)
+		keyNums = append(keyNums, keyNum)
 		values = append(values, c.buildValues(state, dbKey))
 	}
+	if len(keys) == 0 {
+		return nil
+	}
 	defer func() {
 		for _, value := range values {
 			c.putValues(value)
@@ -356,8 +695,20 @@ func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) err
 
 	numOfRetries := int64(0)
 	var err error
+	defer func() {
+		for _, keyNum := range keyNums {
+			if err != nil {
+				c.failedInserts.add(keyNum)
+			}
+			if c.checkpoint != nil && ctx.Err() != context.Canceled {
+				c.checkpoint.insertDone(keyNum, err)
+			}
+		}
+	}()
 	for {
-		err = batchDB.BatchInsert(ctx, c.table, keys, values)
+		err = c.forEachTable(keys, values, func(table string, keys []string, values []map[string][]byte) error {
+			return batchDB.BatchInsert(ctx, table, keys, values)
+		})
 		if err == nil {
 			break
 		}
@@ -386,12 +737,40 @@ func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) err
 	return err
 }
 
-// DoTransaction implements the Workload DoTransaction interface.
+// DoTransaction implements the Workload DoTransaction interface. With transactionsize
+// above 1, it runs that many operations of the same tenant in a transaction.
 func (c *core) DoTransaction(ctx context.Context, db ycsb.DB) error {
 	state := ctx.Value(stateKey).(*coreState)
-	r := state.r
+	if c.transactionSize == 1 {
+		operation := operationType(state.operationChooser.Next(state.r))
+		ctx = c.chooseTenant(ctx, state)
+		return c.doOperation(ctx, db, state, operation)
+	}
 
-	operation := operationType(c.operationChooser.Next(r))
+	txDB, ok := db.(ycsb.TransactionDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the TransactionDB interface", db)
+	}
+	ctx = c.chooseTenant(ctx, state)
+	state.txEffects = make([]func(bool), 0, c.transactionSize)
+	err := txDB.RunTx(ctx, func(ctx context.Context) error {
+		// the operations of an aborted transaction which is run again are done again
+		state.endTx(false)
+		for i := int64(0); i < c.transactionSize; i++ {
+			operation := operationType(state.operationChooser.Next(state.r))
+			if err := c.doOperation(ctx, db, state, operation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	state.endTx(err == nil)
+	state.txEffects = nil
+	return err
+}
+
+// doOperation performs a single operation of the operation type.
+func (c *core) doOperation(ctx context.Context, db ycsb.DB, state *coreState, operation operationType) error {
 	switch operation {
 	case read:
 		return c.doTransactionRead(ctx, db, state)
@@ -401,6 +780,20 @@ func (c *core) DoTransaction(ctx context.Context, db ycsb.DB) error {
 		return c.doTransactionInsert(ctx, db, state)
 	case scan:
 		return c.doTransactionScan(ctx, db, state)
+	case remove:
+		return c.doTransactionDelete(ctx, db, state)
+	case compareAndSwap:
+		return c.doTransactionCAS(ctx, db, state)
+	case multiGet:
+		return c.doTransactionMultiGet(ctx, db, state)
+	case query:
+		return c.doTransactionQuery(ctx, db, state)
+	case batchRead, batchUpdate, batchInsert, batchDelete:
+		batchDB, ok := db.(ycsb.BatchDB)
+		if !ok {
+			return fmt.Errorf("the %T does't implement the batchDB interface", db)
+		}
+		return c.doBatchOperation(ctx, operation, c.batchOperationSize, batchDB, state)
 	default:
 		return c.doTransactionReadModifyWrite(ctx, db, state)
 	}
@@ -414,15 +807,23 @@ func (c *core) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB
 	}
 	state := ctx.Value(stateKey).(*coreState)
 	r := state.r
+	operation := operationType(state.operationChooser.Next(r))
+	ctx = c.chooseTenant(ctx, state)
 
-	operation := operationType(c.operationChooser.Next(r))
+	return c.doBatchOperation(ctx, operation, batchSize, batchDB, state)
+}
+
+// doBatchOperation performs the batch counterpart of the operation type.
+func (c *core) doBatchOperation(ctx context.Context, operation operationType, batchSize int, db ycsb.BatchDB, state *coreState) error {
 	switch operation {
-	case read:
-		return c.doBatchTransactionRead(ctx, batchSize, batchDB, state)
-	case insert:
-		return c.doBatchTransactionInsert(ctx, batchSize, batchDB, state)
-	case update:
-		return c.doBatchTransactionUpdate(ctx, batchSize, batchDB, state)
+	case read, batchRead, multiGet:
+		return c.doBatchTransactionRead(ctx, batchSize, db, state)
+	case insert, batchInsert:
+		return c.doBatchTransactionInsert(ctx, batchSize, db, state)
+	case update, batchUpdate:
+		return c.doBatchTransactionUpdate(ctx, batchSize, db, state)
+	case remove, batchDelete:
+		return c.doBatchTransactionDelete(ctx, batchSize, db, state)
 	case scan:
 		panic("The batch mode don't support the scan operation")
 	default:
@@ -430,7 +831,44 @@ func (c *core) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB
 	}
 }
 
+// nextKeyNum chooses the key of an operation on an existing record, other than the
+// deleted ones if deletedkeys is "avoid".
 func (c *core) nextKeyNum(state *coreState) int64 {
+	keyNum := c.chooseKeyNum(state)
+	if c.deletedKeys == nil || !c.deletedKeys.avoid {
+		return keyNum
+	}
+	for i := 0; i < maxDeletedKeyRetries && c.deletedKeys.contains(keyNum); i++ {
+		keyNum = c.chooseKeyNum(state)
+	}
+	return keyNum
+}
+
+// chooseTenant chooses the tenant of the next transaction, if there are tenants, and
+// returns the context to measure its operations under the tenant with.
+func (c *core) chooseTenant(ctx context.Context, state *coreState) context.Context {
+	if c.tenantCount <= 1 {
+		return ctx
+	}
+	state.tenant = c.tenantChooser.Next(state.r)
+	return measurement.WithTenant(ctx, int(state.tenant))
+}
+
+// tenantKeyNum moves the key chosen among all the keys to a close key of the tenant,
+// keeping the distribution of the keys within every tenant.
+func (c *core) tenantKeyNum(keyNum int64, tenant int64) int64 {
+	n := keyNum - keyNum%c.tenantCount + tenant
+	if n > c.transactionInsertKeySequence.Last() {
+		n -= c.tenantCount
+	}
+	if n < 0 {
+		// the tenant has no keys yet
+		return keyNum
+	}
+	return n
+}
+
+func (c *core) chooseKeyNum(state *coreState) int64 {
 	r := state.r
 	keyNum := int64(0)
 	if _, ok := c.keyChooser.(*generator.Exponential); ok {
@@ -444,51 +882,57 @@ func (c *core) nextKeyNum(state *coreState) int64 {
 			keyNum = c.keyChooser.Next(r)
 		}
 	}
+	if c.tenantCount > 1 {
+		keyNum = c.tenantKeyNum(keyNum, state.tenant)
+	}
 	return keyNum
 }
 
 func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreState) error {
-	r := state.r
+	if c.deletedReadProportion > 0 && state.r.Float64() < c.deletedReadProportion {
+		if keyNum, ok := c.deletedKeys.choose(state.r); ok {
+			return c.doTransactionReadDeleted(ctx, db, keyNum, state)
+		}
+	}
 	keyNum := c.nextKeyNum(state)
 	keyName := c.buildKeyName(keyNum)
 
-	var fields []string
-	if !c.readAllFields {
-		fieldName := state.fieldNames[c.fieldChooser.Next(r)]
-		fields = append(fields, fieldName)
-	} else {
-		fields = state.fieldNames
-	}
+	fields := c.readFields(state)
 
-	values, err := db.Read(ctx, c.table, keyName, fields)
+	values, err := db.Read(ctx, c.tableOf(keyName), keyName, fields)
 	if err != nil {
 		return err
 	}
 
 	if c.dataIntegrity {
-		c.verifyRow(state, keyName, values)
+		return c.verifyRow(state, keyName, values)
 	}
 
 	return nil
 }
 
+// doTransactionReadDeleted reads a deleted key, measured as READ_DELETED, to measure
+// the latency of the reads which don't find the record.
+func (c *core) doTransactionReadDeleted(ctx context.Context, db ycsb.DB, keyNum int64, state *coreState) error {
+	keyName := c.buildKeyName(keyNum)
+	ctx = measurement.WithOperation(ctx, "READ_DELETED")
+	_, err := db.Read(ctx, c.tableOf(keyName), keyName, c.readFields(state))
+	if errors.Is(err, ycsb.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
 func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, state *coreState) error {
-	start := time.Now()
+	start := measurement.Now()
 	defer func() {
-		measurement.Measure("READ_MODIFY_WRITE", time.Now().Sub(start))
+		measurement.Measure("READ_MODIFY_WRITE", measurement.Now().Sub(start))
 	}()
 
-	r := state.r
 	keyNum := c.nextKeyNum(state)
 	keyName := c.buildKeyName(keyNum)
 
-	var fields []string
-	if !c.readAllFields {
-		fieldName := state.fieldNames[c.fieldChooser.Next(r)]
-		fields = append(fields, fieldName)
-	} else {
-		fields = state.fieldNames
-	}
+	fields := c.readFields(state)
 
 	var values map[string][]byte
 	if c.writeAllFields {
@@ -498,31 +942,245 @@ func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, sta
 	}
 	defer c.putValues(values)
 
-	readValues, err := db.Read(ctx, c.table, keyName, fields)
+	readValues, err := db.Read(ctx, c.tableOf(keyName), keyName, fields)
 	if err != nil {
 		return err
 	}
 
-	if err := db.Update(ctx, c.table, keyName, values); err != nil {
+	if err := c.update(ctx, db, keyName, values); err != nil {
 		return err
 	}
 
 	if c.dataIntegrity {
-		c.verifyRow(state, keyName, readValues)
+		return c.verifyRow(state, keyName, readValues)
+	}
+
+	return nil
+}
+
+// doTransactionCAS reads a record, and updates it on the condition that it still has
+// the values read. Conflicts with concurrent updates are counted by the DB wrapper,
+// so they aren't errors here.
+func (c *core) doTransactionCAS(ctx context.Context, db ycsb.DB, state *coreState) error {
+	casDB, ok := db.(ycsb.CASDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the CASDB interface", db)
+	}
+	keyNum := c.nextKeyNum(state)
+	keyName := c.buildKeyName(keyNum)
+
+	fields := c.readFields(state)
+
+	expected, err := db.Read(ctx, c.tableOf(keyName), keyName, fields)
+	if err != nil {
+		return err
+	}
+
+	var values map[string][]byte
+	if c.writeAllFields {
+		values = c.buildValues(state, keyName)
+	} else {
+		values = c.buildSingleValue(state, keyName)
 	}
+	defer c.putValues(values)
 
+	if err := casDB.CAS(ctx, c.tableOf(keyName), keyName, expected, values); err != nil && !errors.Is(err, ycsb.ErrConflict) {
+		return err
+	}
 	return nil
 }
 
+// doTransactionQuery reads the records whose indexed field has a random value.
+func (c *core) doTransactionQuery(ctx context.Context, db ycsb.DB, state *coreState) error {
+	queryDB, ok := db.(ycsb.QueryDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the QueryDB interface", db)
+	}
+	r := state.r
+	fields := c.readFields(state)
+
+	table := c.tables[0]
+	if len(c.tables) > 1 {
+		table = c.tables[r.Intn(len(c.tables))]
+	}
+	_, err := queryDB.Query(ctx, table, c.indexField, c.buildIndexValue(state), c.indexQueryLength, fields)
+	return err
+}
+
+// doTransactionMultiGet reads random records at once, in a single batch if the DB
+// supports it, or one after the other otherwise.
+func (c *core) doTransactionMultiGet(ctx context.Context, db ycsb.DB, state *coreState) error {
+	batchDB, ok := db.(ycsb.BatchDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the batchDB interface", db)
+	}
+	fields := c.readFields(state)
+
+	keys := make([]string, c.multiGetSize)
+	for i := range keys {
+		keys[i] = c.buildKeyName(c.nextKeyNum(state))
+	}
+
+	// the DB wrapper measures the reads as MULTI_GET, in a single batch if the DB
+	// supports them or one after the other otherwise
+	ctx = measurement.WithOperation(ctx, "MULTI_GET")
+	return c.forEachTable(keys, nil, func(table string, keys []string, _ []map[string][]byte) error {
+		rows, err := batchDB.BatchRead(ctx, table, keys, fields)
+		if err != nil {
+			return err
+		}
+		return c.verifyBatch(state, keys, rows)
+	})
+}
+
 func (c *core) doTransactionInsert(ctx context.Context, db ycsb.DB, state *coreState) error {
 	r := state.r
 	keyNum := c.transactionInsertKeySequence.Next(r)
-	defer c.transactionInsertKeySequence.Acknowledge(keyNum)
+	if c.tenantCount > 1 {
+		// the new keys are spread over the tenants in turn
+		ctx = measurement.WithTenant(ctx, int(keyNum%c.tenantCount))
+	}
 	dbKey := c.buildKeyName(keyNum)
 	values := c.buildValues(state, dbKey)
 	defer c.putValues(values)
 
-	return db.Insert(ctx, c.table, dbKey, values)
+	err := c.insert(ctx, db, dbKey, values)
+	state.afterCommit(err == nil, func(committed bool) {
+		c.insertDone(keyNum, committed)
+	})
+	return err
+}
+
+// insertDone acknowledges a record the transactions inserted, so that the following
+// operations can choose it, unless its insert failed or wasn't committed.
+func (c *core) insertDone(keyNum int64, committed bool) {
+	if !committed {
+		c.failedInserts.add(keyNum)
+	}
+	c.transactionInsertKeySequence.Acknowledge(keyNum)
+}
+
+// insert inserts a record, which expires after recordttl if it's set.
+func (c *core) insert(ctx context.Context, db ycsb.DB, key string, values map[string][]byte) error {
+	if c.recordTTL == 0 {
+		return db.Insert(ctx, c.tableOf(key), key, values)
+	}
+	ttlDB, ok := db.(ycsb.TTLDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the TTLDB interface", db)
+	}
+	return ttlDB.InsertWithTTL(ctx, c.tableOf(key), key, values, c.recordTTL)
+}
+
+// update updates a record, which expires after recordttl if it's set.
+func (c *core) update(ctx context.Context, db ycsb.DB, key string, values map[string][]byte) error {
+	if c.recordTTL == 0 {
+		return db.Update(ctx, c.tableOf(key), key, values)
+	}
+	ttlDB, ok := db.(ycsb.TTLDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the TTLDB interface", db)
+	}
+	return ttlDB.UpdateWithTTL(ctx, c.tableOf(key), key, values, c.recordTTL)
+}
+
+// tableOf returns the table of the key, out of the tablecount tables.
+func (c *core) tableOf(key string) string {
+	return util.KeyTable(c.tables, key)
+}
+
+// forEachTable calls f with the keys of a batch in each table, and their values if
+// there are any, so that every batch operation is in a single table.
+func (c *core) forEachTable(keys []string, values []map[string][]byte, f func(table string, keys []string, values []map[string][]byte) error) error {
+	if len(c.tables) == 1 {
+		return f(c.tables[0], keys, values)
+	}
+	tableKeys := make(map[string][]string, len(c.tables))
+	tableValues := make(map[string][]map[string][]byte, len(c.tables))
+	for i, key := range keys {
+		table := c.tableOf(key)
+		tableKeys[table] = append(tableKeys[table], key)
+		if values != nil {
+			tableValues[table] = append(tableValues[table], values[i])
+		}
+	}
+	for _, table := range c.tables {
+		if len(tableKeys[table]) == 0 {
+			continue
+		}
+		if err := f(table, tableKeys[table], tableValues[table]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KeyspaceSize implements the KeyspaceWorkload KeyspaceSize interface.
+func (c *core) KeyspaceSize() int64 {
+	size := c.transactionInsertKeySequence.Last() + 1
+	if c.deletedKeys != nil {
+		size -= c.deletedKeys.count()
+	}
+	return size
+}
+
+// AckedRecords implements the RecordsWorkload AckedRecords interface. The records are
+// the loaded ones, up to the last one inserted if the load ran in this process, and
+// the ones the transactions of this process inserted, less the ones they deleted and
+// the ones whose inserts failed or weren't committed.
+func (c *core) AckedRecords(f func(keyNum int64) bool) {
+	loadEnd := c.insertStart + c.insertCount
+	if last := c.keySequence.Last(); last >= c.insertStart {
+		loadEnd = last + 1
+	}
+	visit := func(start int64, end int64) bool {
+		for keyNum := start; keyNum < end; keyNum++ {
+			if c.deletedKeys != nil && c.deletedKeys.contains(keyNum) || c.failedInserts.contains(keyNum) {
+				continue
+			}
+			if !f(keyNum) {
+				return false
+			}
+		}
+		return true
+	}
+	if visit(c.insertStart, loadEnd) {
+		visit(c.recordCount, c.transactionInsertKeySequence.Last()+1)
+	}
+}
+
+// VerifyRecord implements the RecordsWorkload VerifyRecord interface. The values are
+// only checked with dataintegrity, and otherwise only that the record has its fields.
+// Only the existence of the records is checked on a DB reading them back without their
+// values.
+func (c *core) VerifyRecord(ctx context.Context, db ycsb.DB, keyNum int64) error {
+	state := ctx.Value(stateKey).(*coreState)
+	keyName := c.buildKeyName(keyNum)
+	values, err := db.Read(ctx, c.tableOf(keyName), keyName, nil)
+	valueless := false
+	if valuelessDB, ok := db.(ycsb.ValuelessDB); ok {
+		valueless = valuelessDB.Valueless()
+	}
+	if errors.Is(err, ycsb.ErrNotFound) || (err == nil && len(values) == 0 && !valueless) {
+		return fmt.Errorf("%s: %w", keyName, ycsb.ErrNotFound)
+	} else if err != nil || valueless {
+		return err
+	}
+	if int64(len(values)) < c.fieldCount {
+		return fmt.Errorf("%s has %d fields, expecting %d: %w", keyName, len(values), c.fieldCount, ycsb.ErrUnexpectedValue)
+	}
+	if c.dataIntegrity {
+		if err = c.verifyValues(state, keyName, values); err != nil {
+			return fmt.Errorf("%v: %w", err, ycsb.ErrUnexpectedValue)
+		}
+	}
+	return nil
+}
+
+// GrowKeyspace implements the KeyspaceWorkload GrowKeyspace interface.
+func (c *core) GrowKeyspace(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(stateKey).(*coreState)
+	return c.doTransactionInsert(ctx, db, state)
 }
 
 func (c *core) doTransactionScan(ctx context.Context, db ycsb.DB, state *coreState) error {
@@ -531,18 +1189,23 @@ func (c *core) doTransactionScan(ctx context.Context, db ycsb.DB, state *coreSta
 	startKeyName := c.buildKeyName(keyNum)
 
 	scanLen := c.scanLength.Next(r)
-
-	var fields []string
-	if !c.readAllFields {
-		fieldName := state.fieldNames[c.fieldChooser.Next(r)]
-		fields = append(fields, fieldName)
-	} else {
-		fields = state.fieldNames
+	if scanLen < 1 {
+		// the first bucket of a histogram is 0
+		scanLen = 1
 	}
 
-	_, err := db.Scan(ctx, c.table, startKeyName, int(scanLen), fields)
+	fields := c.readFields(state)
 
-	return err
+	table := c.tableOf(startKeyName)
+	rows, err := db.Scan(ctx, table, startKeyName, int(scanLen), fields)
+	if err != nil {
+		return err
+	}
+
+	if c.dataIntegrity {
+		return c.verifyRows(state, table, rows)
+	}
+	return nil
 }
 
 func (c *core) doTransactionUpdate(ctx context.Context, db ycsb.DB, state *coreState) error {
@@ -558,48 +1221,71 @@ func (c *core) doTransactionUpdate(ctx context.Context, db ycsb.DB, state *coreS
 
 	defer c.putValues(values)
 
-	return db.Update(ctx, c.table, keyName, values)
+	return c.update(ctx, db, keyName, values)
 }
 
-func (c *core) doBatchTransactionRead(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
-	r := state.r
-	var fields []string
-
-	if !c.readAllFields {
-		fieldName := state.fieldNames[c.fieldChooser.Next(r)]
-		fields = append(fields, fieldName)
-	} else {
-		fields = state.fieldNames
+func (c *core) doTransactionDelete(ctx context.Context, db ycsb.DB, state *coreState) error {
+	keyNum := c.nextKeyNum(state)
+	keyName := c.buildKeyName(keyNum)
+	if err := db.Delete(ctx, c.tableOf(keyName), keyName); err != nil {
+		return err
+	}
+	if c.deletedKeys != nil {
+		state.afterCommit(true, func(committed bool) {
+			if committed {
+				c.deletedKeys.add(keyNum)
+			}
+		})
 	}
+	return nil
+}
+
+func (c *core) doBatchTransactionRead(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
+	fields := c.readFields(state)
 
 	keys := make([]string, batchSize)
 	for i := 0; i < batchSize; i++ {
 		keys[i] = c.buildKeyName(c.nextKeyNum(state))
 	}
 
-	_, err := db.BatchRead(ctx, c.table, keys, fields)
-	if err != nil {
-		return err
-	}
+	return c.forEachTable(keys, nil, func(table string, keys []string, _ []map[string][]byte) error {
+		rows, err := db.BatchRead(ctx, table, keys, fields)
+		if err != nil {
+			return err
+		}
+		return c.verifyBatch(state, keys, rows)
+	})
+}
 
-	// TODO should we verify the result?
-	return nil
+// verifyBatch checks the rows of a batch read, if the DB returned them.
+func (c *core) verifyBatch(state *coreState, keys []string, rows []map[string][]byte) error {
+	if !c.dataIntegrity || len(rows) != len(keys) {
+		return nil
+	}
+	var firstErr error
+	for i, row := range rows {
+		if err := c.verifyRow(state, keys[i], row); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
 func (c *core) doBatchTransactionInsert(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
 	r := state.r
+	keyNums := make([]int64, batchSize)
 	keys := make([]string, batchSize)
 	values := make([]map[string][]byte, batchSize)
 	for i := 0; i < batchSize; i++ {
 		keyNum := c.transactionInsertKeySequence.Next(r)
 		keyName := c.buildKeyName(keyNum)
 		keys[i] = keyName
+		keyNums[i] = keyNum
 		if c.writeAllFields {
 			values[i] = c.buildValues(state, keyName)
 		} else {
 			values[i] = c.buildSingleValue(state, keyName)
 		}
-		c.transactionInsertKeySequence.Acknowledge(keyNum)
 	}
 
 	defer func() {
@@ -608,7 +1294,16 @@ func (c *core) doBatchTransactionInsert(ctx context.Context, batchSize int, db y
 		}
 	}()
 
-	return db.BatchInsert(ctx, c.table, keys, values)
+	err := c.forEachTable(keys, values, func(table string, keys []string, values []map[string][]byte) error {
+		return db.BatchInsert(ctx, table, keys, values)
+	})
+	// some tables of a failed batch may be inserted, but none is acknowledged
+	state.afterCommit(err == nil, func(committed bool) {
+		for _, keyNum := range keyNums {
+			c.insertDone(keyNum, committed)
+		}
+	})
+	return err
 }
 
 func (c *core) doBatchTransactionUpdate(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
@@ -631,7 +1326,36 @@ func (c *core) doBatchTransactionUpdate(ctx context.Context, batchSize int, db y
 		}
 	}()
 
-	return db.BatchUpdate(ctx, c.table, keys, values)
+	return c.forEachTable(keys, values, func(table string, keys []string, values []map[string][]byte) error {
+		return db.BatchUpdate(ctx, table, keys, values)
+	})
+}
+
+func (c *core) doBatchTransactionDelete(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
+	keyNums := make([]int64, batchSize)
+	keys := make([]string, batchSize)
+	for i := 0; i < batchSize; i++ {
+		keyNums[i] = c.nextKeyNum(state)
+		keys[i] = c.buildKeyName(keyNums[i])
+	}
+
+	err := c.forEachTable(keys, nil, func(table string, keys []string, _ []map[string][]byte) error {
+		return db.BatchDelete(ctx, table, keys)
+	})
+	if err != nil {
+		return err
+	}
+	if c.deletedKeys != nil {
+		state.afterCommit(true, func(committed bool) {
+			if !committed {
+				return
+			}
+			for _, keyNum := range keyNums {
+				c.deletedKeys.add(keyNum)
+			}
+		})
+	}
+	return nil
 }
 
 // CoreCreator creates the Core workload.
@@ -642,7 +1366,10 @@ type coreCreator struct {
 func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	c := new(core)
 	c.p = p
-	c.table = p.GetString(prop.TableName, prop.TableNameDefault)
+	if p.GetInt(prop.TableCount, prop.TableCountDefault) < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.TableCount)
+	}
+	c.tables = util.TableNames(p)
 	c.fieldCount = p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
 	c.fieldNames = make([]string, c.fieldCount)
 	for i := int64(0); i < c.fieldCount; i++ {
@@ -660,6 +1387,7 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 
 	insertStart := p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
 	insertCount := p.GetInt64(prop.InsertCount, c.recordCount-insertStart)
+	c.insertStart, c.insertCount = insertStart, insertCount
 	if c.recordCount < insertStart+insertCount {
 		util.Fatalf("record count %d must be bigger than insert start %d + count %d",
 			c.recordCount, insertStart, insertCount)
@@ -668,24 +1396,140 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	c.readAllFields = p.GetBool(prop.ReadAllFields, prop.ReadALlFieldsDefault)
 	c.writeAllFields = p.GetBool(prop.WriteAllFields, prop.WriteAllFieldsDefault)
 	c.dataIntegrity = p.GetBool(prop.DataIntegrity, prop.DataIntegrityDefault)
+	c.verifiableValues = c.dataIntegrity && p.GetBool(prop.DataIntegrityChecksum, prop.DataIntegrityChecksumDefault)
 	fieldLengthDistribution := p.GetString(prop.FieldLengthDistribution, prop.FieldLengthDistributionDefault)
-	if c.dataIntegrity && fieldLengthDistribution != "constant" {
+	if c.dataIntegrity && !c.verifiableValues && fieldLengthDistribution != "constant" {
 		util.Fatal("must have constant field size to check data integrity")
 	}
 
+	keyFormat := "ordered"
 	if p.GetString(prop.InsertOrder, prop.InsertOrderDefault) == "hashed" {
-		c.orderedInserts = false
-	} else {
-		c.orderedInserts = true
+		keyFormat = "hashed"
+	}
+	switch c.keyFormat = p.GetString(prop.KeyFormat, keyFormat); c.keyFormat {
+	case "ordered", "hashed", "uuid", "hex":
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting ordered, hashed, uuid or hex", prop.KeyFormat, c.keyFormat)
+	}
+	keyHash := prop.KeyHashDefault
+	switch compat := p.GetString(prop.Compatibility, ""); compat {
+	case "":
+	case "java":
+		c.javaCompatible = true
+		keyHash = "fnv64-java"
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting java", prop.Compatibility, compat)
+	}
+	if err := util.SetKeyHash(p.GetString(prop.KeyHash, keyHash)); err != nil {
+		return nil, err
+	}
+
+	if c.batchOperationSize = p.GetInt(prop.BatchOperationSize, prop.BatchOperationSizeDefault); c.batchOperationSize < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.BatchOperationSize)
+	}
+	if c.multiGetSize = p.GetInt(prop.MultiGetSize, prop.MultiGetSizeDefault); c.multiGetSize < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.MultiGetSize)
+	}
+	ttl, err := time.ParseDuration(p.GetString(prop.RecordTTL, prop.RecordTTLDefault))
+	if err != nil || ttl < 0 {
+		return nil, fmt.Errorf("invalid %s", prop.RecordTTL)
 	}
+	c.recordTTL = ttl
 
+	if c.fieldCompressibility = p.GetFloat64(prop.FieldCompressibility, prop.FieldCompressibilityDefault); c.fieldCompressibility != 0 && c.fieldCompressibility < 1 {
+		return nil, fmt.Errorf("%s must be at least 1", prop.FieldCompressibility)
+	}
+	if c.fieldCompressibility > 0 && c.javaCompatible {
+		return nil, fmt.Errorf("%s can't be used with %s=java", prop.FieldCompressibility, prop.Compatibility)
+	}
+	if c.verifiableValues && c.javaCompatible {
+		return nil, fmt.Errorf("%s can't be used with %s=java", prop.DataIntegrityChecksum, prop.Compatibility)
+	}
+
+	if c.tenantCount = p.GetInt64(prop.TenantCount, prop.TenantCountDefault); c.tenantCount < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.TenantCount)
+	}
+	if c.tenantCount > 1 {
+		if c.javaCompatible {
+			return nil, fmt.Errorf("%s can't be used with %s=java", prop.TenantCount, prop.Compatibility)
+		}
+		c.tenantPrefix = p.GetString(prop.TenantPrefix, prop.TenantPrefixDefault)
+		switch tenantDistrib := p.GetString(prop.TenantDistribution, prop.TenantDistributionDefault); tenantDistrib {
+		case "uniform":
+			c.tenantChooser = generator.NewUniform(0, c.tenantCount-1)
+		case "zipfian":
+			c.tenantChooser = generator.NewZipfianWithItems(c.tenantCount, generator.ZipfianConstant)
+		default:
+			return nil, fmt.Errorf("unknown %s %q; expecting uniform or zipfian", prop.TenantDistribution, tenantDistrib)
+		}
+	}
+
+	if c.indexField = p.GetString(prop.IndexField, ""); c.indexField != "" {
+		if c.indexCardinality = p.GetInt64(prop.IndexCardinality, prop.IndexCardinalityDefault); c.indexCardinality < 1 {
+			return nil, fmt.Errorf("%s must be positive", prop.IndexCardinality)
+		}
+		c.indexWidth = len(fmt.Sprint(c.indexCardinality - 1))
+		if c.indexQueryLength = p.GetInt(prop.IndexQueryLength, prop.IndexQueryLengthDefault); c.indexQueryLength < 1 {
+			return nil, fmt.Errorf("%s must be positive", prop.IndexQueryLength)
+		}
+		if c.dataIntegrity {
+			return nil, fmt.Errorf("%s can't be used with %s", prop.IndexField, prop.DataIntegrity)
+		}
+	} else if p.GetFloat64(prop.QueryProportion, prop.QueryProportionDefault) > 0 {
+		return nil, fmt.Errorf("%s needs %s", prop.QueryProportion, prop.IndexField)
+	}
+
+	if c.transactionSize = p.GetInt64(prop.TransactionSize, prop.TransactionSizeDefault); c.transactionSize < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.TransactionSize)
+	}
+
+	if p.GetFloat64(prop.DeleteProportion, prop.DeleteProportionDefault) > 0 ||
+		p.GetFloat64(prop.BatchDeleteProportion, prop.BatchDeleteProportionDefault) > 0 {
+		switch deleted := p.GetString(prop.DeletedKeys, prop.DeletedKeysDefault); deleted {
+		case "avoid":
+			c.deletedKeys = newDeletedKeys(true)
+		case "read":
+			c.deletedKeys = newDeletedKeys(false)
+		default:
+			return nil, fmt.Errorf("unknown %s %q; expecting avoid or read", prop.DeletedKeys, deleted)
+		}
+	}
+	c.deletedReadProportion = p.GetFloat64(prop.DeletedReadProportion, prop.DeletedReadProportionDefault)
+	if c.deletedReadProportion < 0 || c.deletedReadProportion > 1 {
+		return nil, fmt.Errorf("%s must be between 0 and 1", prop.DeletedReadProportion)
+	}
+	if c.deletedReadProportion > 0 && (c.deletedKeys == nil || c.deletedKeys.avoid) {
+		return nil, fmt.Errorf("%s requires deletes and %s=read", prop.DeletedReadProportion, prop.DeletedKeys)
+	}
+
+	c.failedInserts = newKeySet()
 	c.keySequence = generator.NewCounter(insertStart)
-	c.operationChooser = createOperationGenerator(p)
+	if !p.GetBool(prop.DoTransactions, true) {
+		if c.checkpoint, err = newLoadCheckpoint(p, insertStart, insertCount); err != nil {
+			return nil, err
+		}
+		if c.checkpoint != nil {
+			c.keySequence = c.checkpoint.inserted
+		}
+	}
+	c.operationChooser = createOperationGenerator(p, nil, nil)
+	if s := p.GetString(prop.ThreadPools, ""); s != "" {
+		var err error
+		if c.threadPools, err = util.ParseThreadPools(s); err != nil {
+			return nil, err
+		}
+		c.poolOperationChoosers = createPoolOperationGenerators(p, c.threadPools)
+	}
 
 	c.transactionInsertKeySequence = generator.NewAcknowledgedCounter(c.recordCount)
 	switch requestDistrib {
 	case "uniform":
-		c.keyChooser = generator.NewUniform(insertStart, insertStart+insertCount-1)
+		if p.GetFloat64(prop.KeyspaceGrowth, 0) > 0 {
+			// also choose the keys inserted as the keyspace grows
+			c.keyChooser = generator.NewGrowingUniform(insertStart, c.transactionInsertKeySequence)
+		} else {
+			c.keyChooser = generator.NewUniform(insertStart, insertStart+insertCount-1)
+		}
 	case "sequential":
 		c.keyChooser = generator.NewSequential(insertStart, insertStart+insertCount-1)
 	case "zipfian":
@@ -694,11 +1538,36 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 		expectedNewKeys := int64(float64(opCount) * insertProportion * 2.0)
 		c.keyChooser = generator.NewScrambledZipfian(insertStart, insertStart+insertCount+expectedNewKeys, generator.ZipfianConstant)
 	case "latest":
-		c.keyChooser = generator.NewSkewedLatest(c.transactionInsertKeySequence)
+		window := p.GetInt64(prop.LatestWindow, prop.LatestWindowDefault)
+		if window < 0 {
+			return nil, fmt.Errorf("%s can't be negative", prop.LatestWindow)
+		}
+		skew := p.GetFloat64(prop.LatestSkew, prop.LatestSkewDefault)
+		if skew <= 0 || skew >= 1 {
+			return nil, fmt.Errorf("%s must be in (0, 1)", prop.LatestSkew)
+		}
+		c.keyChooser = generator.NewSkewedLatestWithWindow(c.transactionInsertKeySequence, window, skew)
 	case "hotspot":
 		hotsetFraction := p.GetFloat64(prop.HotspotDataFraction, prop.HotspotDataFractionDefault)
 		hotopnFraction := p.GetFloat64(prop.HotspotOpnFraction, prop.HotspotOpnFractionDefault)
 		c.keyChooser = generator.NewHotspot(insertStart, insertStart+insertCount-1, hotsetFraction, hotopnFraction)
+	case "pareto":
+		shape := p.GetFloat64(prop.ParetoShape, prop.ParetoShapeDefault)
+		scale := p.GetFloat64(prop.ParetoScale, prop.ParetoScaleDefault)
+		if shape <= 0 || scale <= 0 {
+			return nil, fmt.Errorf("%s and %s must be positive", prop.ParetoShape, prop.ParetoScale)
+		}
+		c.keyChooser = generator.NewPareto(insertStart, insertStart+insertCount-1, shape, scale)
+	case "shiftinghotset":
+		size := p.GetFloat64(prop.HotsetSize, prop.HotsetSizeDefault)
+		interval, err := time.ParseDuration(p.GetString(prop.HotsetShiftInterval, prop.HotsetShiftIntervalDefault))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid %s", prop.HotsetShiftInterval)
+		}
+		if size <= 0 || size > 1 {
+			return nil, fmt.Errorf("%s must be in (0, 1]", prop.HotsetSize)
+		}
+		c.keyChooser = generator.NewShiftingHotset(insertStart, insertStart+insertCount-1, size, interval)
 	case "exponential":
 		percentile := p.GetFloat64(prop.ExponentialPercentile, prop.ExponentialPercentileDefault)
 		frac := p.GetFloat64(prop.ExponentialFrac, prop.ExponentialFracDefault)
@@ -708,17 +1577,49 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	}
 
 	c.fieldChooser = generator.NewUniform(0, c.fieldCount-1)
+	if readFieldCount := p.GetInt64(prop.ReadFieldCount, 0); readFieldCount > 0 {
+		if readFieldCount > c.fieldCount {
+			return nil, fmt.Errorf("%s %d is more than the %d fields", prop.ReadFieldCount, readFieldCount, c.fieldCount)
+		}
+		switch distrib := p.GetString(prop.ReadFieldCountDistribution, prop.ReadFieldCountDistributionDefault); distrib {
+		case "constant":
+			c.readFieldCount = generator.NewConstant(readFieldCount)
+		case "uniform":
+			c.readFieldCount = generator.NewUniform(1, readFieldCount)
+		case "zipfian":
+			c.readFieldCount = generator.NewZipfianWithRange(1, readFieldCount, generator.ZipfianConstant)
+		default:
+			return nil, fmt.Errorf("unknown %s %q; expecting constant, uniform or zipfian", prop.ReadFieldCountDistribution, distrib)
+		}
+		switch distrib := p.GetString(prop.ReadFieldDistribution, prop.ReadFieldDistributionDefault); distrib {
+		case "uniform":
+			c.readFieldChooser = c.fieldChooser
+		case "zipfian":
+			c.readFieldChooser = generator.NewZipfianWithRange(0, c.fieldCount-1, generator.ZipfianConstant)
+		default:
+			return nil, fmt.Errorf("unknown %s %q; expecting uniform or zipfian", prop.ReadFieldDistribution, distrib)
+		}
+	}
 	switch scanLengthDistrib {
 	case "uniform":
 		c.scanLength = generator.NewUniform(1, maxScanLength)
 	case "zipfian":
 		c.scanLength = generator.NewZipfianWithRange(1, maxScanLength, generator.ZipfianConstant)
+	case "constant":
+		c.scanLength = generator.NewConstant(maxScanLength)
+	case "histogram":
+		scanLengthHistogram := p.GetString(prop.ScanLengthHistogramFile, "")
+		if scanLengthHistogram == "" {
+			return nil, fmt.Errorf("the histogram scan length distribution needs %s", prop.ScanLengthHistogramFile)
+		}
+		c.scanLength = generator.NewHistogramFromFile(scanLengthHistogram)
 	default:
 		util.Fatalf("distribution %s not allowed for scan length", scanLengthDistrib)
 	}
 
 	c.insertionRetryLimit = p.GetInt64(prop.InsertionRetryLimit, prop.InsertionRetryLimitDefault)
 	c.insertionRetryInterval = p.GetInt64(prop.InsertionRetryInterval, prop.InsertionRetryIntervalDefault)
+	c.seed = p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
 
 	fieldLength := p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
 	c.valuePool = sync.Pool{
@@ -731,5 +1632,5 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 }
 
 func init() {
-	ycsb.RegisterWorkloadCreator("core", coreCreator{})
+	Register("core", coreCreator{})
 }
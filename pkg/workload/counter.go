@@ -0,0 +1,231 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const (
+	counterStateKey = contextKey("counter")
+	// counterField is the field the counters are stored in
+	counterField = "field0"
+)
+
+type counterState struct {
+	r *rand.Rand
+}
+
+// counter increments a small hot set of counters, and checks after the run that
+// every counter holds at least the increments the DB acknowledged, to find lost
+// updates. The failed increments may or may not have been applied, so a counter
+// may also hold up to that many more.
+type counter struct {
+	table       string
+	seed        int64
+	doVerify    bool
+	keySequence ycsb.Generator
+	keyChooser  ycsb.Generator
+
+	// initial are the values of the counters before the run, and acked and failed
+	// count the acknowledged and the failed increments of every counter
+	initial []int64
+	acked   []int64
+	failed  []int64
+}
+
+// InitThread implements the Workload InitThread interface.
+func (c *counter) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	seed := time.Now().UnixNano()
+	if c.seed != 0 {
+		seed = c.seed + int64(threadID)
+	}
+	state := &counterState{r: rand.New(rand.NewSource(seed))}
+	return context.WithValue(ctx, counterStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (c *counter) CleanupThread(_ context.Context) {
+}
+
+// Init implements the Workload Init interface. Before a run, it checks that the DB
+// can increment the counters, and reads the values they start from, so that they can
+// be verified after it.
+func (c *counter) Init(db ycsb.DB) error {
+	if !c.doVerify {
+		return nil
+	}
+	// db isn't wrapped here, unlike in DoTransaction, whose wrapper implements Increment
+	// whatever the DB
+	if _, ok := db.(ycsb.IncrementDB); !ok {
+		return fmt.Errorf("the %T doesn't implement the IncrementDB interface", db)
+	}
+	ctx := db.InitThread(context.Background(), 0, 1)
+	defer db.CleanupThread(ctx)
+	for i := range c.initial {
+		n, err := c.readCounter(ctx, db, int64(i))
+		if err != nil && !errors.Is(err, ycsb.ErrNotFound) {
+			return err
+		}
+		c.initial[i] = n
+	}
+	return nil
+}
+
+// Close implements the Workload Close interface.
+func (c *counter) Close() error {
+	return nil
+}
+
+// Load implements the Workload Load interface.
+func (c *counter) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+func (c *counter) buildKeyName(keyNum int64) string {
+	return fmt.Sprintf("counter%d", keyNum)
+}
+
+// readCounter returns the value of the counter, 0 if it doesn't exist.
+func (c *counter) readCounter(ctx context.Context, db ycsb.DB, keyNum int64) (int64, error) {
+	values, err := db.Read(ctx, c.table, c.buildKeyName(keyNum), []string{counterField})
+	if err != nil {
+		return 0, err
+	}
+	value, ok := values[counterField]
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+// DoInsert implements the Workload DoInsert interface. It sets a counter to 0.
+func (c *counter) DoInsert(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(counterStateKey).(*counterState)
+	keyNum := c.keySequence.Next(state.r)
+	return db.Insert(ctx, c.table, c.buildKeyName(keyNum), map[string][]byte{counterField: []byte("0")})
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (c *counter) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := c.DoInsert(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (c *counter) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	incrementDB, ok := db.(ycsb.IncrementDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IncrementDB interface", db)
+	}
+	state := ctx.Value(counterStateKey).(*counterState)
+	keyNum := c.keyChooser.Next(state.r)
+	if _, err := incrementDB.Increment(ctx, c.table, c.buildKeyName(keyNum), counterField, 1); err != nil {
+		atomic.AddInt64(&c.failed[keyNum], 1)
+		return err
+	}
+	atomic.AddInt64(&c.acked[keyNum], 1)
+	return nil
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (c *counter) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := c.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify implements the VerifyWorkload Verify interface. Every counter is measured
+// as VERIFY if it holds the increments, or VERIFY_ERROR if it lost some or holds more
+// than were sent.
+func (c *counter) Verify(ctx context.Context, db ycsb.DB) error {
+	var lost, wrong int64
+	for i := range c.acked {
+		keyNum := int64(i)
+		start := time.Now()
+		n, err := c.readCounter(ctx, db, keyNum)
+		if err != nil && !errors.Is(err, ycsb.ErrNotFound) {
+			return err
+		}
+		acked := atomic.LoadInt64(&c.acked[i])
+		failed := atomic.LoadInt64(&c.failed[i])
+		increments := n - c.initial[i]
+		if increments >= acked && increments <= acked+failed {
+			measurement.Measure("VERIFY", time.Now().Sub(start))
+			continue
+		}
+		measurement.Measure("VERIFY_ERROR", time.Now().Sub(start))
+		fmt.Printf("%s holds %d increments, but %d were acknowledged and %d failed\n", c.buildKeyName(keyNum), increments, acked, failed)
+		wrong++
+		if increments < acked {
+			lost += acked - increments
+		}
+	}
+	if wrong > 0 {
+		return fmt.Errorf("%d of %d counters are wrong, %d acknowledged increments were lost", wrong, len(c.acked), lost)
+	}
+	return nil
+}
+
+type counterCreator struct {
+}
+
+// Create implements the WorkloadCreator Create interface.
+func (counterCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	c := new(counter)
+	c.table = p.GetString(prop.TableName, prop.TableNameDefault)
+	c.seed = p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
+	c.doVerify = p.GetBool(prop.DoTransactions, true)
+
+	recordCount := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	if recordCount < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.RecordCount)
+	}
+	switch requestDistrib := p.GetString(prop.RequestDistribution, prop.RequestDistributionDefault); requestDistrib {
+	case "uniform":
+		c.keyChooser = generator.NewUniform(0, recordCount-1)
+	case "zipfian":
+		c.keyChooser = generator.NewZipfianWithItems(recordCount, generator.ZipfianConstant)
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting uniform or zipfian", prop.RequestDistribution, requestDistrib)
+	}
+	c.keySequence = generator.NewCounter(p.GetInt64(prop.InsertStart, prop.InsertStartDefault))
+	c.initial = make([]int64, recordCount)
+	c.acked = make([]int64, recordCount)
+	c.failed = make([]int64, recordCount)
+	return c, nil
+}
+
+func init() {
+	Register("counter", counterCreator{})
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// maxDeletedKeyRetries is how many times a key is chosen again when it was deleted,
+// before reading it anyway, so that a mostly deleted keyspace doesn't spin.
+const maxDeletedKeyRetries = 100
+
+// recentDeletedKeys is the number of recently deleted keys the reads of deleted keys
+// choose from.
+const recentDeletedKeys = 10000
+
+// keySet is a set of key numbers safe for concurrent use.
+type keySet struct {
+	sync.RWMutex
+	keys map[int64]struct{}
+}
+
+func newKeySet() keySet {
+	return keySet{keys: make(map[int64]struct{})}
+}
+
+func (s *keySet) add(keyNum int64) {
+	s.Lock()
+	s.keys[keyNum] = struct{}{}
+	s.Unlock()
+}
+
+func (s *keySet) contains(keyNum int64) bool {
+	s.RLock()
+	_, ok := s.keys[keyNum]
+	s.RUnlock()
+	return ok
+}
+
+func (s *keySet) count() int64 {
+	s.RLock()
+	defer s.RUnlock()
+	return int64(len(s.keys))
+}
+
+// deletedKeys tracks the keys the transactions deleted, so that the following
+// operations can avoid them, or read them to measure the not found latency. The set
+// holds at most the keyspace, and only the recent keys are kept in order to be read.
+type deletedKeys struct {
+	keySet
+	// avoid is whether the operations choose other keys than the deleted ones
+	avoid bool
+
+	recentMu sync.Mutex
+	// recent is a ring of the last recentDeletedKeys deleted keys
+	recent []int64
+	next   int
+}
+
+func newDeletedKeys(avoid bool) *deletedKeys {
+	return &deletedKeys{keySet: newKeySet(), avoid: avoid}
+}
+
+func (d *deletedKeys) add(keyNum int64) {
+	d.keySet.add(keyNum)
+
+	d.recentMu.Lock()
+	defer d.recentMu.Unlock()
+	if len(d.recent) < recentDeletedKeys {
+		d.recent = append(d.recent, keyNum)
+		return
+	}
+	d.recent[d.next] = keyNum
+	d.next = (d.next + 1) % len(d.recent)
+}
+
+// choose returns one of the recently deleted keys, false if none was deleted yet.
+func (d *deletedKeys) choose(r *rand.Rand) (int64, bool) {
+	d.recentMu.Lock()
+	defer d.recentMu.Unlock()
+	if len(d.recent) == 0 {
+		return 0, false
+	}
+	return d.recent[r.Intn(len(d.recent))], true
+}
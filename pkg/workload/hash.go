@@ -0,0 +1,186 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// hashVersion is part of the workload hash. It must be bumped whenever the generators
+// or the core workload change the operations they generate for the same properties.
+const hashVersion = 2
+
+// hashProperties are the properties which define the operations of the workload, with
+// their defaults. The properties set to their defaults aren't part of the hash, so that
+// neither setting them explicitly nor adding new ones changes it. The DB, measurement
+// and reporting properties aren't part of it. The workloads registered out of tree add
+// theirs with Register.
+var hashProperties = []struct {
+	name         string
+	defaultValue interface{}
+}{
+	{prop.Workload, "core"},
+	{prop.DoTransactions, true},
+	{prop.RandomSeed, prop.RandomSeedDefault},
+	{prop.ThreadCount, prop.ThreadCountDefault},
+	{prop.ThreadPools, ""},
+	{prop.BatchSize, prop.DefaultBatchSize},
+	{prop.RecordCount, prop.RecordCountDefault},
+	{prop.OperationCount, 0},
+	{prop.InsertStart, prop.InsertStartDefault},
+	{prop.InsertCount, ""},
+	{prop.InsertOrder, prop.InsertOrderDefault},
+	{prop.KeyHash, ""},
+	{prop.KeyPrefix, prop.KeyPrefixDefault},
+	{prop.KeyFormat, ""},
+	{prop.ZeroPadding, prop.ZeroPaddingDefault},
+	{prop.Compatibility, ""},
+	{prop.KeyspaceGrowth, 0},
+	{prop.KeyspaceGrowthInterval, prop.KeyspaceGrowthIntervalDefault},
+	{prop.TableName, prop.TableNameDefault},
+	{prop.TableCount, prop.TableCountDefault},
+	{prop.TenantCount, prop.TenantCountDefault},
+	{prop.TenantPrefix, prop.TenantPrefixDefault},
+	{prop.TenantDistribution, prop.TenantDistributionDefault},
+	{prop.IndexField, ""},
+	{prop.IndexCardinality, prop.IndexCardinalityDefault},
+	{prop.IndexQueryLength, prop.IndexQueryLengthDefault},
+	{prop.TransactionSize, prop.TransactionSizeDefault},
+	{prop.FieldCount, prop.FieldCountDefault},
+	{prop.FieldLength, prop.FieldLengthDefault},
+	{prop.FieldLengthDistribution, prop.FieldLengthDistributionDefault},
+	{prop.FieldLengthHistogramFile, prop.FieldLengthHistogramFileDefault},
+	{prop.FieldCompressibility, prop.FieldCompressibilityDefault},
+	{prop.ReadAllFields, prop.ReadALlFieldsDefault},
+	{prop.ReadFieldCount, 0},
+	{prop.ReadFieldCountDistribution, prop.ReadFieldCountDistributionDefault},
+	{prop.ReadFieldDistribution, prop.ReadFieldDistributionDefault},
+	{prop.WriteAllFields, prop.WriteAllFieldsDefault},
+	{prop.DataIntegrity, prop.DataIntegrityDefault},
+	{prop.DataIntegrityChecksum, prop.DataIntegrityChecksumDefault},
+	{prop.BatchOperationSize, prop.BatchOperationSizeDefault},
+	{prop.MultiGetSize, prop.MultiGetSizeDefault},
+	{prop.DeletedKeys, prop.DeletedKeysDefault},
+	{prop.RecordTTL, prop.RecordTTLDefault},
+	{prop.RequestDistribution, prop.RequestDistributionDefault},
+	{prop.MaxScanLength, prop.MaxScanLengthDefault},
+	{prop.ScanLengthDistribution, prop.ScanLengthDistributionDefault},
+	{prop.ScanLengthHistogramFile, ""},
+	{prop.HotspotDataFraction, prop.HotspotDataFractionDefault},
+	{prop.HotspotOpnFraction, prop.HotspotOpnFractionDefault},
+	{prop.ExponentialPercentile, prop.ExponentialPercentileDefault},
+	{prop.ExponentialFrac, prop.ExponentialFracDefault},
+	{prop.ParetoShape, prop.ParetoShapeDefault},
+	{prop.ParetoScale, prop.ParetoScaleDefault},
+	{prop.HotsetSize, prop.HotsetSizeDefault},
+	{prop.HotsetShiftInterval, prop.HotsetShiftIntervalDefault},
+	{prop.LatestWindow, prop.LatestWindowDefault},
+	{prop.LatestSkew, prop.LatestSkewDefault},
+	{prop.SeriesCount, prop.SeriesCountDefault},
+	{prop.SeriesDistribution, prop.SeriesDistributionDefault},
+	{prop.SeriesInterval, prop.SeriesIntervalDefault},
+	{prop.RangeScanProportion, prop.RangeScanProportionDefault},
+	{prop.MaxRangeLength, prop.MaxRangeLengthDefault},
+	{prop.RangeLengthDistribution, prop.RangeLengthDistributionDefault},
+	{prop.RangeStart, prop.RangeStartDefault},
+	{prop.QueueCount, prop.QueueCountDefault},
+	{prop.QueueConsumerThreads, ""},
+	{prop.QueueNative, prop.QueueNativeDefault},
+	{prop.TraceFile, ""},
+	{prop.TraceSpeedup, prop.TraceSpeedupDefault},
+}
+
+// normalizeHashValue formats the numbers the same however they were written.
+func normalizeHashValue(s string) string {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return s
+}
+
+// Hash returns a hash of the properties which define the operations the workload
+// generates, the contents of the field length and scan length histograms and of the
+// trace if they're used, and the version of the generators, to check that runs on
+// different machines generate the same operations. They only do if randomseed is set.
+func Hash(p *properties.Properties) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version=%d\n", hashVersion)
+
+	values := make(map[string]string)
+	add := func(name string, defaultValue interface{}) {
+		def := normalizeHashValue(fmt.Sprint(defaultValue))
+		values[name] = normalizeHashValue(p.GetString(name, def))
+		if values[name] != def {
+			fmt.Fprintf(h, "%s=%s\n", name, values[name])
+		}
+	}
+	for _, hp := range hashProperties {
+		add(hp.name, hp.defaultValue)
+	}
+	for _, o := range operationProportions {
+		add(o.name, o.defaultValue)
+	}
+	for _, name := range workloadProperties[values[prop.Workload]] {
+		add(name, "")
+	}
+
+	if strings.ToLower(values[prop.FieldLengthDistribution]) == "histogram" {
+		data, err := ioutil.ReadFile(values[prop.FieldLengthHistogramFile])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "histogram=%x\n", sha256.Sum256(data))
+	}
+	if values[prop.ScanLengthDistribution] == "histogram" && values[prop.ScanLengthHistogramFile] != "" {
+		sum, err := hashFile(values[prop.ScanLengthHistogramFile])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "scanlengthhistogram=%x\n", sum)
+	}
+	if values[prop.Workload] == "trace" && values[prop.TraceFile] != "" {
+		sum, err := hashFile(values[prop.TraceFile])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "trace=%x\n", sum)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// hashFile returns the SHA-256 of the file, without reading it all in memory.
+func hashFile(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,288 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const (
+	queueStateKey = contextKey("queue")
+	// queueField is the field of the messages of the queues stored as records
+	queueField = "field0"
+)
+
+type queueState struct {
+	r        *rand.Rand
+	producer bool
+	// queues are the queues the thread produces to or consumes from in turn
+	queues []int64
+	next   int
+}
+
+// queue is a produce-consume scenario. Every queue has a single producer thread, which
+// appends numbered messages to it, and a single consumer thread, which pops them in
+// order. The consumers measure the delivery latency from the production of every
+// message as DELIVERY, or as DELIVERY_ERROR if it isn't the next one of its queue,
+// e.g. because an earlier message was lost, or the message was delivered twice.
+type queue struct {
+	table     string
+	fieldSize int64
+	seed      int64
+	native    bool
+
+	queueCount int64
+	// queueWidth is the number of digits of the queue numbers, so that the queue
+	// keys have the same length
+	queueWidth int
+	producers  int
+	consumers  int
+	// runID tells the messages of this run apart from the ones earlier runs left
+	runID int64
+
+	// produced are the numbers of the next message of every queue, and consumed
+	// the numbers of the message every queue should deliver next. Every queue is
+	// only used by its producer and its consumer thread.
+	produced []int64
+	consumed []int64
+}
+
+// InitThread implements the Workload InitThread interface. The first threads are the
+// producers, and the others the consumers.
+func (c *queue) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	seed := time.Now().UnixNano()
+	if c.seed != 0 {
+		seed = c.seed + int64(threadID)
+	}
+	state := &queueState{r: rand.New(rand.NewSource(seed))}
+	index, count := threadID-c.producers, c.consumers
+	if threadID < c.producers {
+		state.producer = true
+		index, count = threadID, c.producers
+	}
+	for q := int64(index); q < c.queueCount; q += int64(count) {
+		state.queues = append(state.queues, q)
+	}
+	return context.WithValue(ctx, queueStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (c *queue) CleanupThread(_ context.Context) {
+}
+
+// Init implements the Workload Init interface.
+func (c *queue) Init(db ycsb.DB) error {
+	return nil
+}
+
+// Close implements the Workload Close interface.
+func (c *queue) Close() error {
+	return nil
+}
+
+// Load implements the Workload Load interface.
+func (c *queue) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+func (c *queue) queueName(q int64) string {
+	return fmt.Sprintf("queue%0*d", c.queueWidth, q)
+}
+
+// buildKeyName returns the key of a message of a queue stored as a record, ordered
+// by the run and the number of the message.
+func (c *queue) buildKeyName(q int64, seq int64) string {
+	return fmt.Sprintf("%s:%019d:%019d", c.queueName(q), c.runID, seq)
+}
+
+// buildMessage builds a message, "<run>:<queue>:<number>:<production time>:" padded
+// with random letters to fieldlength.
+func (c *queue) buildMessage(r *rand.Rand, q int64, seq int64) []byte {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "%d:%d:%d:%d:", c.runID, q, seq, time.Now().UnixNano())
+	if pad := int(c.fieldSize) - b.Len(); pad > 0 {
+		padding := make([]byte, pad)
+		util.RandBytes(r, padding)
+		b.Write(padding)
+	}
+	return b.Bytes()
+}
+
+// parseMessage returns the queue, the number and the production time of a message of
+// this run, or false if it's from another run or isn't a message.
+func (c *queue) parseMessage(message []byte) (int64, int64, int64, bool) {
+	parts := strings.SplitN(string(message), ":", 5)
+	if len(parts) < 5 {
+		return 0, 0, 0, false
+	}
+	var numbers [4]int64
+	for i := range numbers {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		numbers[i] = n
+	}
+	if numbers[0] != c.runID {
+		return 0, 0, 0, false
+	}
+	return numbers[1], numbers[2], numbers[3], true
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (c *queue) DoInsert(ctx context.Context, db ycsb.DB) error {
+	return errors.New("the queue workload has nothing to load, run it with recordcount=0")
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (c *queue) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	return c.DoInsert(ctx, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface. It produces to or
+// consumes from the next queue of the thread.
+func (c *queue) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(queueStateKey).(*queueState)
+	q := state.queues[state.next%len(state.queues)]
+	state.next++
+	if state.producer {
+		return c.produce(ctx, db, state, q)
+	}
+	return c.consume(ctx, db, q)
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (c *queue) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := c.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// produce appends the next message to the queue. A message which failed is sent
+// again, since the queue should deliver it next.
+func (c *queue) produce(ctx context.Context, db ycsb.DB, state *queueState, q int64) error {
+	seq := c.produced[q]
+	message := c.buildMessage(state.r, q, seq)
+	if c.native {
+		queueDB, ok := db.(ycsb.QueueDB)
+		if !ok {
+			return fmt.Errorf("the %T does't implement the QueueDB interface", db)
+		}
+		if err := queueDB.Push(ctx, c.table, c.queueName(q), message); err != nil {
+			return err
+		}
+	} else if err := db.Insert(ctx, c.table, c.buildKeyName(q, seq), map[string][]byte{queueField: message}); err != nil {
+		return err
+	}
+	c.produced[q]++
+	return nil
+}
+
+// consume pops the message at the head of the queue, if there is one. Without
+// queue.native, the head is the first record of the queue, which is deleted.
+func (c *queue) consume(ctx context.Context, db ycsb.DB, q int64) error {
+	var message []byte
+	if c.native {
+		queueDB, ok := db.(ycsb.QueueDB)
+		if !ok {
+			return fmt.Errorf("the %T does't implement the QueueDB interface", db)
+		}
+		var err error
+		if message, err = queueDB.Pop(ctx, c.table, c.queueName(q)); err != nil {
+			return err
+		}
+	} else {
+		rows, err := db.Scan(ctx, c.table, c.buildKeyName(q, c.consumed[q]), 1, []string{queueField})
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			message = rows[0][queueField]
+		}
+	}
+
+	messageQueue, seq, producedAt, ok := c.parseMessage(message)
+	if !ok || messageQueue != q {
+		// the queue is empty, or the scan reached the next queue
+		return nil
+	}
+	if !c.native {
+		if err := db.Delete(ctx, c.table, c.buildKeyName(q, seq)); err != nil {
+			return err
+		}
+	}
+
+	latency := time.Duration(time.Now().UnixNano() - producedAt)
+	if seq == c.consumed[q] {
+		measurement.Measure("DELIVERY", latency)
+	} else {
+		measurement.Measure("DELIVERY_ERROR", latency)
+	}
+	if seq >= c.consumed[q] {
+		c.consumed[q] = seq + 1
+	}
+	return nil
+}
+
+type queueCreator struct {
+}
+
+// Create implements the WorkloadCreator Create interface.
+func (queueCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	c := new(queue)
+	c.table = p.GetString(prop.TableName, prop.TableNameDefault)
+	c.fieldSize = p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
+	c.seed = p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
+	c.native = p.GetBool(prop.QueueNative, prop.QueueNativeDefault)
+	c.runID = time.Now().UnixNano()
+
+	if c.queueCount = p.GetInt64(prop.QueueCount, prop.QueueCountDefault); c.queueCount < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.QueueCount)
+	}
+	c.queueWidth = len(fmt.Sprint(c.queueCount - 1))
+
+	threadCount := p.GetInt(prop.ThreadCount, 1)
+	c.consumers = p.GetInt(prop.QueueConsumerThreads, threadCount/2)
+	c.producers = threadCount - c.consumers
+	if c.consumers < 1 || c.producers < 1 {
+		return nil, fmt.Errorf("the queue workload needs both producer and consumer threads, set %s and %s", prop.ThreadCount, prop.QueueConsumerThreads)
+	}
+	if c.queueCount < int64(c.producers) || c.queueCount < int64(c.consumers) {
+		return nil, fmt.Errorf("%s must be at least the number of producer and consumer threads", prop.QueueCount)
+	}
+
+	c.produced = make([]int64, c.queueCount)
+	c.consumed = make([]int64, c.queueCount)
+	return c, nil
+}
+
+func init() {
+	Register("queue", queueCreator{})
+}
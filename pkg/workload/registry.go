@@ -0,0 +1,45 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"fmt"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// workloadProperties are the properties which define the operations of the registered
+// workloads, besides hashProperties, keyed by workload name.
+var workloadProperties = map[string][]string{}
+
+// Register registers a creator for the workload selected with workload=name, so that
+// workloads can live in their own packages like the DB bindings, registered from
+// their init functions. The properties are the ones defining the operations of the
+// workload which aren't in hashProperties, so that they are part of the workload hash.
+func Register(name string, creator ycsb.WorkloadCreator, properties ...string) {
+	ycsb.RegisterWorkloadCreator(name, creator)
+	workloadProperties[name] = properties
+}
+
+// Create creates the workload the workload property selects, the core one by default.
+func Create(p *properties.Properties) (ycsb.Workload, error) {
+	name := p.GetString(prop.Workload, "core")
+	creator := ycsb.GetWorkloadCreator(name)
+	if creator == nil {
+		return nil, fmt.Errorf("workload %s is not registered", name)
+	}
+	return creator.Create(p)
+}
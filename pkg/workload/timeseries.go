@@ -0,0 +1,269 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const timeSeriesStateKey = contextKey("timeseries")
+
+type timeSeriesState struct {
+	r *rand.Rand
+}
+
+// timeSeries is a metrics storage scenario. Every series is a run of points whose keys
+// are the series and the timestamp of the point, so that they are ordered by time. The
+// transactions append points to the end of the series, or read the points of a time
+// range with a scan.
+type timeSeries struct {
+	table      string
+	fieldNames []string
+	fieldSize  int64
+	seed       int64
+
+	seriesCount int64
+	// seriesWidth is the number of digits of the series numbers, so that the series
+	// keys have the same length
+	seriesWidth    int
+	interval       time.Duration
+	scanProportion float64
+	latestRanges   bool
+
+	keySequence   ycsb.Generator
+	seriesChooser ycsb.Generator
+	rangeLength   ycsb.Generator
+	// points are the number of points of every series, loaded or appended
+	points []int64
+}
+
+// InitThread implements the Workload InitThread interface.
+func (t *timeSeries) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	seed := time.Now().UnixNano()
+	if t.seed != 0 {
+		seed = t.seed + int64(threadID)
+	}
+	state := &timeSeriesState{r: rand.New(rand.NewSource(seed))}
+	return context.WithValue(ctx, timeSeriesStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (t *timeSeries) CleanupThread(_ context.Context) {
+}
+
+// Init implements the Workload Init interface.
+func (t *timeSeries) Init(db ycsb.DB) error {
+	return nil
+}
+
+// Close implements the Workload Close interface.
+func (t *timeSeries) Close() error {
+	return nil
+}
+
+// Load implements the Workload Load interface.
+func (t *timeSeries) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+// buildKeyName returns the key of a point, the series followed by the timestamp of
+// the point on the logical clock of the series.
+func (t *timeSeries) buildKeyName(series int64, point int64) string {
+	return fmt.Sprintf("series%0*d:%019d", t.seriesWidth, series, point*int64(t.interval))
+}
+
+func (t *timeSeries) buildValues(r *rand.Rand) map[string][]byte {
+	values := make(map[string][]byte, len(t.fieldNames))
+	for _, field := range t.fieldNames {
+		value := make([]byte, t.fieldSize)
+		util.RandBytes(r, value)
+		values[field] = value
+	}
+	return values
+}
+
+// loadedPoint returns the series and the point of the nth loaded record. The series
+// are loaded in turn, so that they all have the same number of points.
+func (t *timeSeries) loadedPoint(n int64) (int64, int64) {
+	return n % t.seriesCount, n / t.seriesCount
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (t *timeSeries) DoInsert(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(timeSeriesStateKey).(*timeSeriesState)
+	series, point := t.loadedPoint(t.keySequence.Next(state.r))
+	return db.Insert(ctx, t.table, t.buildKeyName(series, point), t.buildValues(state.r))
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (t *timeSeries) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	batchDB, ok := db.(ycsb.BatchDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the batchDB interface", db)
+	}
+	state := ctx.Value(timeSeriesStateKey).(*timeSeriesState)
+	keys := make([]string, batchSize)
+	values := make([]map[string][]byte, batchSize)
+	for i := range keys {
+		series, point := t.loadedPoint(t.keySequence.Next(state.r))
+		keys[i] = t.buildKeyName(series, point)
+		values[i] = t.buildValues(state.r)
+	}
+	return batchDB.BatchInsert(ctx, t.table, keys, values)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (t *timeSeries) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(timeSeriesStateKey).(*timeSeriesState)
+	r := state.r
+	series := t.seriesChooser.Next(r)
+	if r.Float64() < t.scanProportion {
+		return t.doRangeScan(ctx, db, r, series)
+	}
+	return t.doAppend(ctx, db, r, series)
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface. The appends
+// append batchSize points to the series at once, the scans aren't batched.
+func (t *timeSeries) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	batchDB, ok := db.(ycsb.BatchDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the batchDB interface", db)
+	}
+	state := ctx.Value(timeSeriesStateKey).(*timeSeriesState)
+	r := state.r
+	series := t.seriesChooser.Next(r)
+	if r.Float64() < t.scanProportion {
+		return t.doRangeScan(ctx, db, r, series)
+	}
+
+	point := atomic.AddInt64(&t.points[series], int64(batchSize)) - int64(batchSize)
+	keys := make([]string, batchSize)
+	values := make([]map[string][]byte, batchSize)
+	for i := range keys {
+		keys[i] = t.buildKeyName(series, point+int64(i))
+		values[i] = t.buildValues(r)
+	}
+	return batchDB.BatchInsert(ctx, t.table, keys, values)
+}
+
+// doAppend appends a point to the end of the series.
+func (t *timeSeries) doAppend(ctx context.Context, db ycsb.DB, r *rand.Rand, series int64) error {
+	point := atomic.AddInt64(&t.points[series], 1) - 1
+	return db.Insert(ctx, t.table, t.buildKeyName(series, point), t.buildValues(r))
+}
+
+// doRangeScan reads the points of a time range of the series, the most recent ones
+// with timeseries.rangestart=latest.
+func (t *timeSeries) doRangeScan(ctx context.Context, db ycsb.DB, r *rand.Rand, series int64) error {
+	points := atomic.LoadInt64(&t.points[series])
+	if points == 0 {
+		return nil
+	}
+	length := t.rangeLength.Next(r)
+	if length > points {
+		length = points
+	}
+	start := points - length
+	if !t.latestRanges {
+		start = r.Int63n(points - length + 1)
+	}
+	_, err := db.Scan(ctx, t.table, t.buildKeyName(series, start), int(length), t.fieldNames)
+	return err
+}
+
+type timeSeriesCreator struct {
+}
+
+// Create implements the WorkloadCreator Create interface.
+func (timeSeriesCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	t := new(timeSeries)
+	t.table = p.GetString(prop.TableName, prop.TableNameDefault)
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	t.fieldNames = make([]string, fieldCount)
+	for i := range t.fieldNames {
+		t.fieldNames[i] = fmt.Sprintf("field%d", i)
+	}
+	t.fieldSize = p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
+	t.seed = p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
+
+	if t.seriesCount = p.GetInt64(prop.SeriesCount, prop.SeriesCountDefault); t.seriesCount < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.SeriesCount)
+	}
+	t.seriesWidth = len(fmt.Sprint(t.seriesCount - 1))
+	interval, err := time.ParseDuration(p.GetString(prop.SeriesInterval, prop.SeriesIntervalDefault))
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid %s", prop.SeriesInterval)
+	}
+	t.interval = interval
+	t.scanProportion = p.GetFloat64(prop.RangeScanProportion, prop.RangeScanProportionDefault)
+
+	switch seriesDistrib := p.GetString(prop.SeriesDistribution, prop.SeriesDistributionDefault); seriesDistrib {
+	case "uniform":
+		t.seriesChooser = generator.NewUniform(0, t.seriesCount-1)
+	case "zipfian":
+		t.seriesChooser = generator.NewScrambledZipfian(0, t.seriesCount-1, generator.ZipfianConstant)
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting uniform or zipfian", prop.SeriesDistribution, seriesDistrib)
+	}
+
+	maxRangeLength := p.GetInt64(prop.MaxRangeLength, prop.MaxRangeLengthDefault)
+	if maxRangeLength < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.MaxRangeLength)
+	}
+	switch rangeDistrib := p.GetString(prop.RangeLengthDistribution, prop.RangeLengthDistributionDefault); rangeDistrib {
+	case "constant":
+		t.rangeLength = generator.NewConstant(maxRangeLength)
+	case "uniform":
+		t.rangeLength = generator.NewUniform(1, maxRangeLength)
+	case "zipfian":
+		t.rangeLength = generator.NewZipfianWithRange(1, maxRangeLength, generator.ZipfianConstant)
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting constant, uniform or zipfian", prop.RangeLengthDistribution, rangeDistrib)
+	}
+
+	switch rangeStart := p.GetString(prop.RangeStart, prop.RangeStartDefault); rangeStart {
+	case "latest":
+		t.latestRanges = true
+	case "uniform":
+	default:
+		return nil, fmt.Errorf("unknown %s %q; expecting latest or uniform", prop.RangeStart, rangeStart)
+	}
+
+	// the transactions append to the points loaded in every series
+	recordCount := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	t.points = make([]int64, t.seriesCount)
+	for series := range t.points {
+		t.points[series] = recordCount / t.seriesCount
+		if int64(series) < recordCount%t.seriesCount {
+			t.points[series]++
+		}
+	}
+	t.keySequence = generator.NewCounter(p.GetInt64(prop.InsertStart, prop.InsertStartDefault))
+	return t, nil
+}
+
+func init() {
+	Register("timeseries", timeSeriesCreator{})
+}
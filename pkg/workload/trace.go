@@ -0,0 +1,217 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const traceStateKey = contextKey("trace")
+
+type traceState struct {
+	r *rand.Rand
+}
+
+// trace replays the operations of a trace file, e.g. recorded in production. The
+// threads take the records in turn, and with trace.speedup, every operation waits
+// until the time it was traced at, relative to the first one and sped up.
+type trace struct {
+	table      string
+	fieldNames []string
+	seed       int64
+	speedup    float64
+
+	mu     sync.Mutex
+	file   *os.File
+	reader util.TraceReader
+	// first is the timestamp of the first timed record, and start the time it was
+	// replayed at
+	first int64
+	start time.Time
+}
+
+// InitThread implements the Workload InitThread interface.
+func (t *trace) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	seed := time.Now().UnixNano()
+	if t.seed != 0 {
+		seed = t.seed + int64(threadID)
+	}
+	state := &traceState{r: rand.New(rand.NewSource(seed))}
+	return context.WithValue(ctx, traceStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (t *trace) CleanupThread(_ context.Context) {
+}
+
+// Init implements the Workload Init interface.
+func (t *trace) Init(db ycsb.DB) error {
+	return nil
+}
+
+// Close implements the Workload Close interface.
+func (t *trace) Close() error {
+	return t.file.Close()
+}
+
+// Load implements the Workload Load interface.
+func (t *trace) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+// next returns the next record of the trace, and the time to replay it at, which is
+// zero if it can be replayed at once.
+func (t *trace) next() (util.TraceRecord, time.Time, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, err := t.reader.Next()
+	if err != nil || t.speedup <= 0 || record.Timestamp < 0 {
+		return record, time.Time{}, err
+	}
+	if t.start.IsZero() {
+		t.first, t.start = record.Timestamp, time.Now()
+	}
+	offset := float64(record.Timestamp-t.first) * float64(time.Microsecond) / t.speedup
+	return record, t.start.Add(time.Duration(offset)), nil
+}
+
+// buildValues builds the values of a write of size bytes, split over the fields.
+func (t *trace) buildValues(r *rand.Rand, size int64) map[string][]byte {
+	values := make(map[string][]byte, len(t.fieldNames))
+	n := int64(len(t.fieldNames))
+	for i, field := range t.fieldNames {
+		fieldSize := size / n
+		if int64(i) < size%n {
+			fieldSize++
+		}
+		value := make([]byte, fieldSize)
+		util.RandBytes(r, value)
+		values[field] = value
+	}
+	return values
+}
+
+// DoInsert implements the Workload DoInsert interface. The load replays the trace
+// too, e.g. a trace of the inserts of the records the run trace accesses.
+func (t *trace) DoInsert(ctx context.Context, db ycsb.DB) error {
+	return t.DoTransaction(ctx, db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (t *trace) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	return t.DoBatchTransaction(ctx, batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface. It replays the next
+// record of the trace, and returns ycsb.ErrWorkloadDone after the last one.
+func (t *trace) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	record, at, err := t.next()
+	if err == io.EOF {
+		return ycsb.ErrWorkloadDone
+	} else if err != nil {
+		// the rest of a malformed trace can't be trusted
+		util.Fatalf("read trace %s failed: %v", t.file.Name(), err)
+	}
+	if d := time.Until(at); !at.IsZero() && d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+
+	state := ctx.Value(traceStateKey).(*traceState)
+	table := record.Table
+	if table == "" {
+		table = t.table
+	}
+	switch record.Op {
+	case "read":
+		_, err = db.Read(ctx, table, record.Key, nil)
+	case "update":
+		err = db.Update(ctx, table, record.Key, t.buildValues(state.r, record.Size))
+	case "insert":
+		err = db.Insert(ctx, table, record.Key, t.buildValues(state.r, record.Size))
+	case "delete":
+		err = db.Delete(ctx, table, record.Key)
+	case "scan":
+		count := int(record.Size)
+		if count < 1 {
+			count = 1
+		}
+		_, err = db.Scan(ctx, table, record.Key, count, nil)
+	}
+	return err
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface. The
+// records are replayed one by one.
+func (t *trace) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := t.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type traceCreator struct {
+}
+
+// Create implements the WorkloadCreator Create interface.
+func (traceCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	t := new(trace)
+	t.table = p.GetString(prop.TableName, prop.TableNameDefault)
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	if fieldCount < 1 {
+		return nil, fmt.Errorf("%s must be positive", prop.FieldCount)
+	}
+	t.fieldNames = make([]string, fieldCount)
+	for i := range t.fieldNames {
+		t.fieldNames[i] = fmt.Sprintf("field%d", i)
+	}
+	t.seed = p.GetInt64(prop.RandomSeed, prop.RandomSeedDefault)
+	if t.speedup = p.GetFloat64(prop.TraceSpeedup, prop.TraceSpeedupDefault); t.speedup < 0 {
+		return nil, fmt.Errorf("%s can't be negative", prop.TraceSpeedup)
+	}
+
+	name := p.GetString(prop.TraceFile, "")
+	if name == "" {
+		return nil, fmt.Errorf("the trace workload needs %s", prop.TraceFile)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	t.file = f
+
+	t.reader = util.NewTraceReader(f)
+	return t, nil
+}
+
+func init() {
+	Register("trace", traceCreator{})
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// checksumLength is the length of the ":<checksum>" trailer of the verifiable values.
+const checksumLength = 17
+
+// buildVerifiableValue builds a value which can be verified without knowing what was
+// written: "<table>:<key>:<field>:<generation>:", a filler derived from it, and the
+// checksum of both. The generation makes every write of a field different.
+func (c *core) buildVerifiableValue(state *coreState, key string, fieldKey string) []byte {
+	size := int(c.fieldLengthGenerator.Next(state.r))
+	generation := atomic.AddInt64(&c.generation, 1)
+	b := bytes.NewBuffer(c.getValueBuffer(size + checksumLength)[0:0])
+	fmt.Fprintf(b, "%s:%s:%s:%d:", c.tableOf(key), key, fieldKey, generation)
+	fillVerifiableValue(b, size-checksumLength)
+	fmt.Fprintf(b, ":%016x", uint64(util.BytesHash64(b.Bytes())))
+	return b.Bytes()
+}
+
+// fillVerifiableValue appends the hashes of the value so far until it has size bytes,
+// or leaves it as it is if it's already longer.
+func fillVerifiableValue(b *bytes.Buffer, size int) {
+	if b.Len() >= size {
+		return
+	}
+	for b.Len() < size {
+		n := util.BytesHash64(b.Bytes())
+		b.WriteString(strconv.FormatUint(uint64(n), 10))
+	}
+	b.Truncate(size)
+}
+
+// checkVerifiableValue checks that the value was built by buildVerifiableValue for
+// the field of the key.
+func (c *core) checkVerifiableValue(key string, fieldKey string, value []byte) error {
+	if len(value) < checksumLength || value[len(value)-checksumLength] != ':' {
+		return errors.New("no checksum")
+	}
+	body := value[:len(value)-checksumLength]
+	checksum, err := strconv.ParseUint(string(value[len(value)-checksumLength+1:]), 16, 64)
+	if err != nil {
+		return errors.New("no checksum")
+	}
+	if uint64(util.BytesHash64(body)) != checksum {
+		return fmt.Errorf("checksum %016x doesn't match", checksum)
+	}
+
+	prefix := []byte(fmt.Sprintf("%s:%s:%s:", c.tableOf(key), key, fieldKey))
+	if !bytes.HasPrefix(body, prefix) {
+		return errors.New("written for another field")
+	}
+	end := bytes.IndexByte(body[len(prefix):], ':')
+	if end < 0 {
+		return errors.New("no generation")
+	}
+	header := body[:len(prefix)+end+1]
+	if len(body) > len(header) {
+		expected := bytes.NewBuffer(append([]byte(nil), header...))
+		fillVerifiableValue(expected, len(body))
+		if !bytes.Equal(expected.Bytes(), body) {
+			return errors.New("filler doesn't match")
+		}
+	}
+	return nil
+}
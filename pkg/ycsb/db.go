@@ -16,7 +16,9 @@ package ycsb
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/magiconair/properties"
 )
@@ -106,6 +108,190 @@ type AnalyzeDB interface {
 	Analyze(ctx context.Context, table string) error
 }
 
+// CostDB is the interface for the DB that can estimate the monetary cost of a workload,
+// e.g. from the request pricing of a hosted service.
+type CostDB interface {
+	// EstimateCost returns the estimated cost in USD of the executed operations.
+	// ops: The number of executed operations, keyed by the measured operation name.
+	EstimateCost(ops map[string]int64) (float64, error)
+}
+
+// StorageSizeDB is the interface for the DB that can report the physical size of its storage,
+// used to estimate write and space amplification.
+type StorageSizeDB interface {
+	// StorageSize returns the physical size in bytes of the stored data.
+	StorageSize(ctx context.Context) (int64, error)
+}
+
+// CASDB is the interface for the DB that can update a record conditionally on its
+// current values.
+type CASDB interface {
+	// CAS updates a record in the database if its current values are expected, and
+	// returns an error wrapping ErrConflict otherwise.
+	// table: The name of the table.
+	// key: The record key of the record to update.
+	// expected: The values the fields of the record must have, others aren't compared.
+	// values: A map of field/value pairs to update in the record.
+	CAS(ctx context.Context, table string, key string, expected map[string][]byte, values map[string][]byte) error
+}
+
+// ConcurrentDB is the interface for the DB whose thread state, returned by
+// InitThread, supports several operations running at once, such as a read and its
+// hedged attempt.
+type ConcurrentDB interface {
+	// ConcurrentThreads only marks the DB as supporting concurrent operations.
+	ConcurrentThreads()
+}
+
+// ValuelessDB is the interface for the DB that can be configured not to store the values
+// of the records, e.g. only their sizes, and then reads the records back without their
+// values, as an empty map.
+type ValuelessDB interface {
+	// Valueless returns whether the records are read back without their values.
+	Valueless() bool
+}
+
+// TTLDB is the interface for the DB that can expire records.
+type TTLDB interface {
+	// InsertWithTTL inserts a record which expires after the ttl.
+	InsertWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error
+
+	// UpdateWithTTL updates a record, which then expires after the ttl.
+	UpdateWithTTL(ctx context.Context, table string, key string, values map[string][]byte, ttl time.Duration) error
+}
+
+// IncrementDB is the interface for the DB that can atomically increment a counter
+// stored as a decimal number in a field.
+type IncrementDB interface {
+	// Increment adds delta to the field of the record, which is 0 if the record or
+	// the field doesn't exist, and returns the new value.
+	// table: The name of the table.
+	// key: The record key of the counter.
+	// field: The field of the counter.
+	// delta: The amount to add to the counter.
+	Increment(ctx context.Context, table string, key string, field string, delta int64) (int64, error)
+}
+
+// QueueDB is the interface for the DB that has native FIFO queues, e.g. lists.
+type QueueDB interface {
+	// Push appends the value to the tail of the queue.
+	// table: The name of the table.
+	// queue: The name of the queue.
+	// value: The value to append.
+	Push(ctx context.Context, table string, queue string, value []byte) error
+
+	// Pop removes the value at the head of the queue and returns it, or nil if the
+	// queue is empty.
+	// table: The name of the table.
+	// queue: The name of the queue.
+	Pop(ctx context.Context, table string, queue string) ([]byte, error)
+}
+
+// QueryDB is the interface for the DB that can index a non-key field and query the
+// records by it.
+type QueryDB interface {
+	// CreateIndex creates an index of the field, unless it already exists.
+	// table: The name of the table.
+	// field: The field to index.
+	CreateIndex(ctx context.Context, table string, field string) error
+
+	// Query returns the records whose field has the value.
+	// table: The name of the table.
+	// field: The indexed field to filter on.
+	// value: The value the field must have.
+	// count: The maximum number of records to return.
+	// fields: The list of fields to read, nil|empty for reading all.
+	Query(ctx context.Context, table string, field string, value []byte, count int, fields []string) ([]map[string][]byte, error)
+}
+
+// TransactionDB is the interface for the DB that can run several operations in a
+// transaction.
+type TransactionDB interface {
+	// RunTx runs fn once in a transaction, which is committed if fn returns nil and
+	// rolled back otherwise. The operations fn performs with the context it's given
+	// are part of the transaction. If the transaction aborted because of a concurrent
+	// one, the error is classified as ErrorClassConflict.
+	// fn: The function performing the operations of the transaction.
+	RunTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ExtendedStatsDB is the interface for the DB that collects binding specific statistics,
+// e.g. the retries performed internally by the client.
+type ExtendedStatsDB interface {
+	// ExtendedStats returns the statistics collected so far, keyed by name.
+	ExtendedStats() map[string]int64
+}
+
+// EndpointDB is the interface for the DB that spreads its requests over several
+// endpoints, and sends the requests on a context from util.WithEndpoint to the given one.
+type EndpointDB interface {
+	// Endpoints returns the addresses of the endpoints.
+	Endpoints() []string
+}
+
+// Event is a state change inside the DB, e.g. a server the client starts suspecting
+// to have failed.
+type Event struct {
+	Time    time.Time
+	Message string
+}
+
+// EventDB is the interface for the DB that reports the state changes it observes, so
+// that they can be told apart from the latencies around them.
+type EventDB interface {
+	// Events returns the events since the last call, in the order they happened.
+	Events() []Event
+}
+
+// StreamScanDB is the interface for the DB that can stream all the records of a table,
+// e.g. to back it up.
+type StreamScanDB interface {
+	// StreamScan calls fn with every record of the table in key order, and stops at
+	// the first error fn returns.
+	// table: The name of the table.
+	// fn: The function called with the key and the values of each record, which it
+	// must not keep after returning.
+	StreamScan(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error
+}
+
+// Classes of the errors of failed operations, which are counted separately.
+const (
+	ErrorClassTimeout  = "timeout"
+	ErrorClassNotFound = "not-found"
+	ErrorClassConflict = "conflict"
+	ErrorClassOther    = "other"
+)
+
+var (
+	// ErrNotFound is returned, possibly wrapped, when the record doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned, possibly wrapped, when the operation conflicted with a concurrent one.
+	ErrConflict = errors.New("conflict")
+)
+
+// ErrorClassifierDB is the interface for the DB that can classify its own errors,
+// e.g. from the error codes of its client library.
+type ErrorClassifierDB interface {
+	// ClassifyError returns the class of the error of a failed operation,
+	// or "" to fall back to the classification of ClassifyError.
+	ClassifyError(err error) string
+}
+
+// ClassifyError returns the class of the error of a failed operation from its type:
+// ErrNotFound, ErrConflict, or a deadline or timeout error.
+func ClassifyError(err error) string {
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrConflict):
+		return ErrorClassConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeout) && timeout.Timeout():
+		return ErrorClassTimeout
+	}
+	return ErrorClassOther
+}
+
 var dbCreators = map[string]DBCreator{}
 
 // RegisterDBCreator registers a creator for the database
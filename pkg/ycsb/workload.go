@@ -15,6 +15,7 @@ package ycsb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/magiconair/properties"
@@ -56,6 +57,50 @@ type Workload interface {
 	DoBatchTransaction(ctx context.Context, batchSize int, db DB) error
 }
 
+// KeyNameWorkload is the interface for the workload that can name the key of a record
+// from its number, e.g. to read back every record it loaded.
+type KeyNameWorkload interface {
+	// KeyName returns the key of the record with the given number.
+	KeyName(keyNum int64) string
+}
+
+// KeyspaceWorkload is the interface for the workload that can grow its keyspace
+// during the transaction phase.
+type KeyspaceWorkload interface {
+	// KeyspaceSize returns the number of records in the keyspace.
+	KeyspaceSize() int64
+
+	// GrowKeyspace inserts a new record at the end of the keyspace.
+	GrowKeyspace(ctx context.Context, db DB) error
+}
+
+// VerifyWorkload is the interface for the workload that can check what the DB holds
+// after the transactions, e.g. that it didn't lose any acknowledged write.
+type VerifyWorkload interface {
+	// Verify reads back the records the transactions wrote, and returns an error if
+	// they don't hold what they should.
+	Verify(ctx context.Context, db DB) error
+}
+
+// RecordsWorkload is the interface for the workload that can read back the records it
+// acknowledged writing, e.g. to check that the DB didn't lose or corrupt any of them.
+type RecordsWorkload interface {
+	// AckedRecords calls f with the number of every record which was acknowledged and
+	// not deleted since, as far as the workload knows, until f returns false.
+	AckedRecords(f func(keyNum int64) bool)
+
+	// VerifyRecord reads back the record, and returns an error wrapping ErrNotFound if
+	// it is missing, or ErrUnexpectedValue if it doesn't hold what was written.
+	VerifyRecord(ctx context.Context, db DB, keyNum int64) error
+}
+
+// ErrUnexpectedValue is returned when a record doesn't hold what was written to it.
+var ErrUnexpectedValue = errors.New("unexpected value")
+
+// ErrWorkloadDone is returned by the workload when it has no more operations, e.g.
+// at the end of a trace, to end the thread.
+var ErrWorkloadDone = errors.New("workload done")
+
 var workloadCreators = map[string]WorkloadCreator{}
 
 // RegisterWorkloadCreator registers a creator for the workload